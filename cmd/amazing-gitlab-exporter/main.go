@@ -60,6 +60,11 @@ func runCommand() *cli.Command {
 				Usage:   "GitLab personal access token",
 				Sources: cli.EnvVars("AGE_GITLAB_TOKEN"),
 			},
+			&cli.StringFlag{
+				Name:    "gitlab-token-file",
+				Usage:   "Path to a file containing the GitLab access token (re-read on rotation)",
+				Sources: cli.EnvVars("AGE_GITLAB_TOKEN_FILE"),
+			},
 			&cli.StringFlag{
 				Name:    "log-level",
 				Usage:   "Log level (trace, debug, info, warn, error, fatal, panic)",
@@ -86,37 +91,13 @@ func runCommand() *cli.Command {
 			log := logger.WithField("app", "amazing-gitlab-exporter")
 
 			// --- Load configuration ---
-			var cfg *config.Config
 			configPath := cmd.String("config")
-			if configPath != "" {
-				cfg, err = config.Load(configPath)
-				if err != nil {
-					return fmt.Errorf("loading config from %s: %w", configPath, err)
-				}
-			} else {
-				cfg = &config.Config{}
-			}
-
-			// Apply defaults for any unset values.
-			config.ApplyDefaults(cfg)
-
-			// --- CLI overrides ---
-			if v := cmd.String("gitlab-url"); v != "" {
-				cfg.GitLab.URL = v
-			}
-			if v := cmd.String("gitlab-token"); v != "" {
-				cfg.GitLab.Token = v
+			loader := func() (*config.Config, error) {
+				return loadConfig(cmd, configPath)
 			}
-			if v := cmd.String("server-listen-address"); v != "" {
-				cfg.Server.ListenAddress = v
-			}
-
-			// --- Validate required fields ---
-			if cfg.GitLab.URL == "" {
-				return fmt.Errorf("gitlab URL is required (--gitlab-url or config file)")
-			}
-			if cfg.GitLab.Token == "" {
-				return fmt.Errorf("gitlab token is required (--gitlab-token or config file)")
+			cfg, err := loader()
+			if err != nil {
+				return err
 			}
 
 			log.WithFields(logrus.Fields{
@@ -134,12 +115,68 @@ func runCommand() *cli.Command {
 			ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 			defer stop()
 
+			// --- SIGHUP triggers a live configuration reload ---
+			hup := make(chan os.Signal, 1)
+			signal.Notify(hup, syscall.SIGHUP)
+			defer signal.Stop(hup)
+			exp.WatchConfig(configPath, loader, hup)
+
 			// --- Run ---
 			return exp.Run(ctx)
 		},
 	}
 }
 
+// loadConfig reads the configuration file (if any), applies defaults and CLI
+// overrides, and checks required fields. It is re-run on every live reload.
+func loadConfig(cmd *cli.Command, configPath string) (*config.Config, error) {
+	var cfg *config.Config
+	if configPath != "" {
+		var err error
+		cfg, err = config.Load(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading config from %s: %w", configPath, err)
+		}
+	} else {
+		// Apply defaults for any unset values.
+		cfg = &config.Config{}
+		config.ApplyDefaults(cfg)
+	}
+
+	// --- CLI overrides ---
+	if v := cmd.String("gitlab-url"); v != "" {
+		cfg.GitLab.URL = v
+	}
+	if v := cmd.String("gitlab-token"); v != "" {
+		cfg.GitLab.Token = v
+	}
+	if v := cmd.String("gitlab-token-file"); v != "" {
+		token, err := config.ReadSecretFile(v)
+		if err != nil {
+			return nil, fmt.Errorf("reading gitlab token file: %w", err)
+		}
+		cfg.GitLab.Token = token
+		cfg.GitLab.TokenFile = v
+	}
+	if v := cmd.String("server-listen-address"); v != "" {
+		cfg.Server.ListenAddress = v
+	}
+
+	// --- Validate required fields ---
+	// With an instances list, each entry carries its own URL and token and
+	// has already been validated by config.Load.
+	if len(cfg.Instances) == 0 {
+		if cfg.GitLab.URL == "" {
+			return nil, fmt.Errorf("gitlab URL is required (--gitlab-url or config file)")
+		}
+		if cfg.GitLab.Token == "" && len(cfg.GitLab.Tokens) == 0 {
+			return nil, fmt.Errorf("gitlab token is required (--gitlab-token, --gitlab-token-file or config file)")
+		}
+	}
+
+	return cfg, nil
+}
+
 func versionCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "version",
@@ -134,7 +134,6 @@ func (c *CodeReviewCollector) Collect(ch chan<- prometheus.Metric) {
 // Run performs one collection cycle.
 func (c *CodeReviewCollector) Run(ctx context.Context) error {
 	start := time.Now()
-	var errCount float64
 
 	// Gate on tier feature availability.
 	if features := c.client.Features(); features == nil || !features.HasCodeReview {
@@ -155,11 +154,8 @@ func (c *CodeReviewCollector) Run(ctx context.Context) error {
 	obs := codeReviewObservations{}
 	rest := c.client.REST()
 
-	for _, project := range projects {
-		if err := ctx.Err(); err != nil {
-			return err
-		}
-
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects))
+	w.eachProject(projects, func(project string) {
 		// Fetch open MRs with reviewer information to determine pending reviews and reviewer stats.
 		opts := &gitlab.ListProjectMergeRequestsOptions{
 			State:   gitlab.Ptr("opened"),
@@ -171,11 +167,10 @@ func (c *CodeReviewCollector) Run(ctx context.Context) error {
 			},
 		}
 
-		mrs, _, err := rest.MergeRequests.ListProjectMergeRequests(project, opts)
+		mrs, _, err := rest.MergeRequests.ListProjectMergeRequests(project, opts, gitlab.WithContext(ctx))
 		if err != nil {
-			c.logger.WithError(err).WithField("project", project).Error("failed to list MRs for code review")
-			errCount++
-			continue
+			w.fail(err, "project", project, "failed to list MRs for code review")
+			return
 		}
 
 		var pendingReviews float64
@@ -219,10 +214,9 @@ func (c *CodeReviewCollector) Run(ctx context.Context) error {
 			},
 		}
 
-		mergedMRs, _, err := rest.MergeRequests.ListProjectMergeRequests(project, mergedOpts)
+		mergedMRs, _, err := rest.MergeRequests.ListProjectMergeRequests(project, mergedOpts, gitlab.WithContext(ctx))
 		if err != nil {
-			c.logger.WithError(err).WithField("project", project).Error("failed to list merged MRs for code review")
-			errCount++
+			w.fail(err, "project", project, "failed to list merged MRs for code review")
 		} else {
 			for _, mr := range mergedMRs {
 				// Count approvals from merge info.
@@ -266,10 +260,10 @@ func (c *CodeReviewCollector) Run(ctx context.Context) error {
 				value:  count,
 			})
 		}
-	}
+	})
 
 	obs.scrapeDuration = time.Since(start).Seconds()
-	obs.scrapeErrors = errCount
+	obs.scrapeErrors = w.errors
 
 	c.mu.Lock()
 	c.observations = obs
@@ -277,9 +271,9 @@ func (c *CodeReviewCollector) Run(ctx context.Context) error {
 
 	c.logger.WithFields(logrus.Fields{
 		"duration": obs.scrapeDuration,
-		"errors":   errCount,
+		"errors":   w.errors,
 		"projects": len(projects),
 	}).Debug("code_review collection completed")
 
-	return nil
+	return w.interrupted
 }
@@ -0,0 +1,363 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+)
+
+// complianceChecks are the checks the compliance collector evaluates, in
+// export order.
+var complianceChecks = []string{
+	"default_branch_protected",
+	"push_access_level",
+	"merge_access_level",
+	"force_push_disabled",
+	"required_approvals",
+	"pipelines_must_succeed",
+	"resolve_discussions",
+	"reset_approvals_on_push",
+	"delete_source_branch",
+}
+
+// ComplianceCollector checks the default branch protection and merge
+// request settings of the tracked projects against the configured policy
+// and reports one pass/fail gauge per check plus a compliance score.
+type ComplianceCollector struct {
+	client   *gitlabclient.Client
+	config   config.ComplianceCollectorConfig
+	projects []string
+	mu       sync.RWMutex
+	logger   *logrus.Entry
+
+	// Prometheus descriptors
+	check             *prometheus.Desc
+	score             *prometheus.Desc
+	requiredApprovals *prometheus.Desc
+
+	// Internal operational metrics
+	scrapeDuration *prometheus.Desc
+	scrapeErrors   *prometheus.Desc
+
+	// Collected observations (mutex-protected)
+	observations complianceObservations
+}
+
+type complianceObservations struct {
+	check             []labeledGauge
+	score             []labeledGauge
+	requiredApprovals []labeledGauge
+	scrapeDuration    float64
+	scrapeErrors      float64
+}
+
+// NewComplianceCollector creates a new compliance collector.
+func NewComplianceCollector(client *gitlabclient.Client, cfg config.ComplianceCollectorConfig, projects []string) *ComplianceCollector {
+	return &ComplianceCollector{
+		client:   client,
+		config:   cfg,
+		projects: projects,
+		logger:   logrus.WithField("collector", "compliance"),
+
+		check: prometheus.NewDesc(
+			"age_compliance_check",
+			"Whether the project passes the compliance check (1) or not (0).",
+			[]string{"project", "check"}, nil,
+		),
+		score: prometheus.NewDesc(
+			"age_compliance_score",
+			"Fraction of the scored compliance checks the project passes (0 to 1).",
+			[]string{"project"}, nil,
+		),
+		requiredApprovals: prometheus.NewDesc(
+			"age_compliance_required_approvals",
+			"Number of approvals merge requests of the project require.",
+			[]string{"project"}, nil,
+		),
+
+		scrapeDuration: prometheus.NewDesc(
+			"age_scrape_duration_seconds",
+			"Time taken by the collector scrape.",
+			[]string{"collector_type"}, nil,
+		),
+		scrapeErrors: prometheus.NewDesc(
+			"age_scrape_errors_total",
+			"Total number of scrape errors.",
+			[]string{"collector_type"}, nil,
+		),
+	}
+}
+
+func (c *ComplianceCollector) Name() string  { return "compliance" }
+func (c *ComplianceCollector) Enabled() bool { return c.config.Enabled }
+
+// SetProjects updates the list of tracked projects.
+func (c *ComplianceCollector) SetProjects(projects []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.projects = projects
+}
+
+// Describe implements prometheus.Collector.
+func (c *ComplianceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.check
+	ch <- c.score
+	ch <- c.requiredApprovals
+	ch <- c.scrapeDuration
+	ch <- c.scrapeErrors
+}
+
+// Collect implements prometheus.Collector.
+func (c *ComplianceCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	obs := c.observations
+	c.mu.RUnlock()
+
+	gauges := []struct {
+		desc *prometheus.Desc
+		obs  []labeledGauge
+	}{
+		{c.check, obs.check},
+		{c.score, obs.score},
+		{c.requiredApprovals, obs.requiredApprovals},
+	}
+	for _, g := range gauges {
+		for _, o := range g.obs {
+			ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, o.value, o.labels...)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.scrapeDuration, prometheus.GaugeValue, obs.scrapeDuration, "compliance")
+	ch <- prometheus.MustNewConstMetric(c.scrapeErrors, prometheus.CounterValue, obs.scrapeErrors, "compliance")
+}
+
+// Run performs one collection cycle.
+func (c *ComplianceCollector) Run(ctx context.Context) error {
+	start := time.Now()
+
+	c.mu.RLock()
+	projects := make([]string, len(c.projects))
+	copy(projects, c.projects)
+	c.mu.RUnlock()
+
+	scored := make(map[string]bool)
+	for _, name := range c.config.Checks {
+		scored[name] = true
+	}
+	if len(scored) == 0 {
+		for _, name := range complianceChecks {
+			scored[name] = true
+		}
+	}
+
+	obs := complianceObservations{}
+
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects))
+	w.projects(projects, "failed to collect compliance", func(project string) error {
+		return c.collectProject(ctx, project, scored, &obs)
+	})
+
+	obs.scrapeDuration = time.Since(start).Seconds()
+	obs.scrapeErrors = w.errors
+
+	c.mu.Lock()
+	c.observations = obs
+	c.mu.Unlock()
+
+	c.logger.WithFields(logrus.Fields{
+		"duration": obs.scrapeDuration,
+		"errors":   w.errors,
+		"projects": len(projects),
+	}).Debug("compliance collection completed")
+
+	return w.interrupted
+}
+
+// collectProject evaluates the compliance checks of one project. Checks
+// the instance cannot answer, such as approval settings on the Free tier,
+// are left out rather than reported as failing.
+func (c *ComplianceCollector) collectProject(ctx context.Context, project string, scored map[string]bool, obs *complianceObservations) error {
+	rest := c.client.REST()
+
+	p, _, err := rest.Projects.GetProject(project, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("get project: %w", err)
+	}
+	if p.DefaultBranch == "" {
+		// Empty repository: there is no branch to protect yet.
+		return nil
+	}
+
+	results := map[string]bool{
+		"pipelines_must_succeed": p.OnlyAllowMergeIfPipelineSucceeds,
+		"resolve_discussions":    p.OnlyAllowMergeIfAllDiscussionsAreResolved,
+		"delete_source_branch":   p.RemoveSourceBranchAfterMerge,
+	}
+
+	protections, err := c.defaultBranchProtections(ctx, project, p.DefaultBranch)
+	if err != nil {
+		return err
+	}
+	results["default_branch_protected"] = len(protections) > 0
+	results["push_access_level"] = len(protections) > 0
+	results["merge_access_level"] = len(protections) > 0
+	results["force_push_disabled"] = len(protections) > 0
+	for _, b := range protections {
+		if !accessLevelsAtLeast(b.PushAccessLevels, c.config.MinPushAccessLevel) {
+			results["push_access_level"] = false
+		}
+		if !accessLevelsAtLeast(b.MergeAccessLevels, c.config.MinMergeAccessLevel) {
+			results["merge_access_level"] = false
+		}
+		if b.AllowForcePush {
+			results["force_push_disabled"] = false
+		}
+	}
+
+	required, resetOnPush, ok, err := c.approvalSettings(ctx, project, p.DefaultBranch)
+	if err != nil {
+		return err
+	}
+	if ok {
+		results["required_approvals"] = required >= c.config.MinApprovals
+		results["reset_approvals_on_push"] = resetOnPush
+		obs.requiredApprovals = append(obs.requiredApprovals, labeledGauge{
+			labels: []string{project},
+			value:  float64(required),
+		})
+	}
+
+	var passed, total int
+	for _, name := range complianceChecks {
+		pass, evaluated := results[name]
+		if !evaluated {
+			continue
+		}
+		obs.check = append(obs.check, labeledGauge{labels: []string{project, name}, value: boolGauge(pass)})
+		if scored[name] {
+			total++
+			if pass {
+				passed++
+			}
+		}
+	}
+	if total > 0 {
+		obs.score = append(obs.score, labeledGauge{
+			labels: []string{project},
+			value:  float64(passed) / float64(total),
+		})
+	}
+	return nil
+}
+
+// defaultBranchProtections returns the protected branch rules, including
+// wildcard rules, that apply to the default branch.
+func (c *ComplianceCollector) defaultBranchProtections(ctx context.Context, project, branch string) ([]*gitlab.ProtectedBranch, error) {
+	var matching []*gitlab.ProtectedBranch
+	opts := &gitlab.ListProtectedBranchesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1},
+	}
+	for opts.Page > 0 {
+		branches, resp, err := c.client.REST().ProtectedBranches.ListProtectedBranches(project, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("list protected branches: %w", err)
+		}
+		for _, b := range branches {
+			if wildcardPattern(b.Name).MatchString(branch) {
+				matching = append(matching, b)
+			}
+		}
+		opts.Page = resp.NextPage
+	}
+	return matching, nil
+}
+
+// approvalSettings returns the number of approvals merge requests into
+// branch require and whether pushes reset approvals. The number comes from
+// the approval rules (see requiredApprovals), or the legacy project
+// setting when there are none. ok is false when the instance has no
+// approval settings API.
+func (c *ComplianceCollector) approvalSettings(ctx context.Context, project, branch string) (required int, resetOnPush, ok bool, err error) {
+	rest := c.client.REST()
+
+	cfg, resp, err := rest.Projects.GetApprovalConfiguration(project, gitlab.WithContext(ctx))
+	if err != nil {
+		if isForbidden(resp) || (resp != nil && resp.StatusCode == http.StatusNotFound) {
+			return 0, false, false, nil
+		}
+		return 0, false, false, fmt.Errorf("get approval configuration: %w", err)
+	}
+
+	var rules []*gitlab.ProjectApprovalRule
+	opts := &gitlab.GetProjectApprovalRulesListsOptions{PerPage: 100, Page: 1}
+	for opts.Page > 0 {
+		page, resp, err := rest.Projects.GetProjectApprovalRules(project, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return 0, false, false, fmt.Errorf("list approval rules: %w", err)
+		}
+		rules = append(rules, page...)
+		opts.Page = resp.NextPage
+	}
+	if len(rules) == 0 {
+		return cfg.ApprovalsBeforeMerge, cfg.ResetApprovalsOnPush, true, nil
+	}
+	return requiredApprovals(rules, branch), cfg.ResetApprovalsOnPush, true, nil
+}
+
+// requiredApprovals returns the approvals every merge request into branch
+// needs under rules. GitLab checks each rule on its own and one approval
+// can satisfy several rules, so this is the largest requirement rather
+// than the sum. Only regular and any_approver rules count: code owner and
+// security report rules apply to some merge requests only. Rules scoped to
+// other protected branches are ignored.
+func requiredApprovals(rules []*gitlab.ProjectApprovalRule, branch string) int {
+	required := 0
+	for _, r := range rules {
+		if r.RuleType != "regular" && r.RuleType != "any_approver" {
+			continue
+		}
+		if !approvalRuleApplies(r, branch) {
+			continue
+		}
+		required = max(required, r.ApprovalsRequired)
+	}
+	return required
+}
+
+// approvalRuleApplies reports whether rule applies to merge requests into
+// branch.
+func approvalRuleApplies(rule *gitlab.ProjectApprovalRule, branch string) bool {
+	if rule.AppliesToAllProtectedBranches || len(rule.ProtectedBranches) == 0 {
+		return true
+	}
+	for _, b := range rule.ProtectedBranches {
+		if wildcardPattern(b.Name).MatchString(branch) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessLevelsAtLeast reports whether every role-based access level is
+// "No one" or at least minLevel. Grants to individual users, groups and deploy
+// keys are not role-based and are ignored.
+func accessLevelsAtLeast(levels []*gitlab.BranchAccessDescription, minLevel int) bool {
+	for _, l := range levels {
+		if l.UserID != 0 || l.GroupID != 0 || l.DeployKeyID != 0 {
+			continue
+		}
+		if l.AccessLevel != gitlab.NoPermissions && int(l.AccessLevel) < minLevel {
+			return false
+		}
+	}
+	return true
+}
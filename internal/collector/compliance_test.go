@@ -0,0 +1,79 @@
+package collector
+
+import (
+	"testing"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestRequiredApprovals(t *testing.T) {
+	rule := func(ruleType string, required int, branches ...string) *gitlab.ProjectApprovalRule {
+		r := &gitlab.ProjectApprovalRule{RuleType: ruleType, ApprovalsRequired: required}
+		for _, b := range branches {
+			r.ProtectedBranches = append(r.ProtectedBranches, &gitlab.ProtectedBranch{Name: b})
+		}
+		return r
+	}
+
+	tests := []struct {
+		name  string
+		rules []*gitlab.ProjectApprovalRule
+		want  int
+	}{
+		{"no rules", nil, 0},
+		{"single regular rule", []*gitlab.ProjectApprovalRule{rule("regular", 2)}, 2},
+		{"independent rules take the max", []*gitlab.ProjectApprovalRule{
+			rule("any_approver", 1), rule("regular", 2), rule("regular", 1),
+		}, 2},
+		{"code owner and report rules ignored", []*gitlab.ProjectApprovalRule{
+			rule("regular", 1), rule("code_owner", 3), rule("report_approver", 5),
+		}, 1},
+		{"rule scoped to another branch ignored", []*gitlab.ProjectApprovalRule{
+			rule("regular", 1), rule("regular", 4, "release/*"),
+		}, 1},
+		{"rule scoped by wildcard to the branch", []*gitlab.ProjectApprovalRule{
+			rule("regular", 3, "ma*"),
+		}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requiredApprovals(tt.rules, "main"); got != tt.want {
+				t.Errorf("requiredApprovals = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessLevelsAtLeast(t *testing.T) {
+	role := func(level gitlab.AccessLevelValue) *gitlab.BranchAccessDescription {
+		return &gitlab.BranchAccessDescription{AccessLevel: level}
+	}
+
+	tests := []struct {
+		name   string
+		levels []*gitlab.BranchAccessDescription
+		min    int
+		want   bool
+	}{
+		{"no levels", nil, 40, true},
+		{"maintainers", []*gitlab.BranchAccessDescription{role(gitlab.MaintainerPermissions)}, 40, true},
+		{"developers below maintainer", []*gitlab.BranchAccessDescription{role(gitlab.DeveloperPermissions)}, 40, false},
+		{"no one", []*gitlab.BranchAccessDescription{role(gitlab.NoPermissions)}, 40, true},
+		{"one level too low", []*gitlab.BranchAccessDescription{
+			role(gitlab.MaintainerPermissions), role(gitlab.DeveloperPermissions),
+		}, 40, false},
+		{"user grant ignored", []*gitlab.BranchAccessDescription{
+			{AccessLevel: gitlab.DeveloperPermissions, UserID: 7},
+		}, 40, true},
+		{"deploy key ignored", []*gitlab.BranchAccessDescription{
+			{AccessLevel: gitlab.DeveloperPermissions, DeployKeyID: 3},
+		}, 40, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := accessLevelsAtLeast(tt.levels, tt.min); got != tt.want {
+				t.Errorf("accessLevelsAtLeast = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"golang.org/x/time/rate"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+)
+
+// RegistryCollector reports the container registry repositories of the
+// tracked projects: tag counts, the size and age of their tags and whether
+// the project's cleanup policy is enabled.
+type RegistryCollector struct {
+	client   *gitlabclient.Client
+	config   config.RegistryCollectorConfig
+	projects []string
+	mu       sync.RWMutex
+	logger   *logrus.Entry
+
+	// tagLimiter paces tag detail requests, which dominate the cost of a
+	// cycle, so a large registry does not eat the instance's budget.
+	tagLimiter *rate.Limiter
+
+	// Prometheus descriptors
+	cleanupPolicy *prometheus.Desc
+	repositories  *prometheus.Desc
+	tagsCount     *prometheus.Desc
+	walkedTags    *prometheus.Desc
+	truncated     *prometheus.Desc
+	size          *prometheus.Desc
+	oldestTagAge  *prometheus.Desc
+	newestTagAge  *prometheus.Desc
+
+	// Internal operational metrics
+	scrapeDuration *prometheus.Desc
+	scrapeErrors   *prometheus.Desc
+
+	// Collected observations (mutex-protected)
+	observations registryObservations
+}
+
+type registryObservations struct {
+	cleanupPolicy  []labeledGauge
+	repositories   []labeledGauge
+	tagsCount      []labeledGauge
+	walkedTags     []labeledGauge
+	truncated      []labeledGauge
+	size           []labeledGauge
+	oldestTagAge   []labeledGauge
+	newestTagAge   []labeledGauge
+	scrapeDuration float64
+	scrapeErrors   float64
+}
+
+// NewRegistryCollector creates a new container registry collector.
+func NewRegistryCollector(client *gitlabclient.Client, cfg config.RegistryCollectorConfig, projects []string) *RegistryCollector {
+	repoLabels := []string{"project", "repository"}
+
+	limit := rate.Inf
+	if cfg.TagDetailsPerSecond > 0 {
+		limit = rate.Limit(cfg.TagDetailsPerSecond)
+	}
+
+	return &RegistryCollector{
+		client:     client,
+		config:     cfg,
+		projects:   projects,
+		logger:     logrus.WithField("collector", "container_registry"),
+		tagLimiter: rate.NewLimiter(limit, 1),
+
+		cleanupPolicy: prometheus.NewDesc(
+			"age_registry_cleanup_policy_enabled",
+			"Whether the project's container registry cleanup policy is enabled (1) or not (0).",
+			[]string{"project"}, nil,
+		),
+		repositories: prometheus.NewDesc(
+			"age_registry_repositories_count",
+			"Number of container registry repositories of the project.",
+			[]string{"project"}, nil,
+		),
+		tagsCount: prometheus.NewDesc(
+			"age_registry_repository_tags_count",
+			"Number of tags in the container registry repository.",
+			repoLabels, nil,
+		),
+		walkedTags: prometheus.NewDesc(
+			"age_registry_repository_walked_tags_count",
+			"Number of tags of the repository whose details were fetched.",
+			repoLabels, nil,
+		),
+		truncated: prometheus.NewDesc(
+			"age_registry_repository_tags_truncated",
+			"Whether the tag walk stopped at max_tags_per_repository (1) or covered every tag (0).",
+			repoLabels, nil,
+		),
+		size: prometheus.NewDesc(
+			"age_registry_repository_size_bytes",
+			"Sum of the total sizes of the walked tags in bytes. Layers shared between tags are counted once per tag, so this is not deduplicated storage; partial when the walk was truncated.",
+			repoLabels, nil,
+		),
+		oldestTagAge: prometheus.NewDesc(
+			"age_registry_repository_oldest_tag_age_seconds",
+			"Age of the oldest tag in seconds; absent when the tag walk was truncated.",
+			repoLabels, nil,
+		),
+		newestTagAge: prometheus.NewDesc(
+			"age_registry_repository_newest_tag_age_seconds",
+			"Age of the newest tag in seconds; absent when the tag walk was truncated.",
+			repoLabels, nil,
+		),
+
+		scrapeDuration: prometheus.NewDesc(
+			"age_scrape_duration_seconds",
+			"Time taken by the collector scrape.",
+			[]string{"collector_type"}, nil,
+		),
+		scrapeErrors: prometheus.NewDesc(
+			"age_scrape_errors_total",
+			"Total number of scrape errors.",
+			[]string{"collector_type"}, nil,
+		),
+	}
+}
+
+func (c *RegistryCollector) Name() string  { return "container_registry" }
+func (c *RegistryCollector) Enabled() bool { return c.config.Enabled }
+
+// SetProjects updates the list of tracked projects.
+func (c *RegistryCollector) SetProjects(projects []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.projects = projects
+}
+
+// Describe implements prometheus.Collector.
+func (c *RegistryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.cleanupPolicy
+	ch <- c.repositories
+	ch <- c.tagsCount
+	ch <- c.walkedTags
+	ch <- c.truncated
+	ch <- c.size
+	ch <- c.oldestTagAge
+	ch <- c.newestTagAge
+	ch <- c.scrapeDuration
+	ch <- c.scrapeErrors
+}
+
+// Collect implements prometheus.Collector.
+func (c *RegistryCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	obs := c.observations
+	c.mu.RUnlock()
+
+	gauges := []struct {
+		desc *prometheus.Desc
+		obs  []labeledGauge
+	}{
+		{c.cleanupPolicy, obs.cleanupPolicy},
+		{c.repositories, obs.repositories},
+		{c.tagsCount, obs.tagsCount},
+		{c.walkedTags, obs.walkedTags},
+		{c.truncated, obs.truncated},
+		{c.size, obs.size},
+		{c.oldestTagAge, obs.oldestTagAge},
+		{c.newestTagAge, obs.newestTagAge},
+	}
+	for _, g := range gauges {
+		for _, o := range g.obs {
+			ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, o.value, o.labels...)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.scrapeDuration, prometheus.GaugeValue, obs.scrapeDuration, "container_registry")
+	ch <- prometheus.MustNewConstMetric(c.scrapeErrors, prometheus.CounterValue, obs.scrapeErrors, "container_registry")
+}
+
+// Run performs one collection cycle.
+func (c *RegistryCollector) Run(ctx context.Context) error {
+	start := time.Now()
+
+	c.mu.RLock()
+	projects := make([]string, len(c.projects))
+	copy(projects, c.projects)
+	c.mu.RUnlock()
+
+	obs := registryObservations{}
+
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects))
+	w.projects(projects, "failed to collect container registry", func(project string) error {
+		return c.collectProject(ctx, project, &obs)
+	})
+
+	obs.scrapeDuration = time.Since(start).Seconds()
+	obs.scrapeErrors = w.errors
+
+	c.mu.Lock()
+	c.observations = obs
+	c.mu.Unlock()
+
+	c.logger.WithFields(logrus.Fields{
+		"duration": obs.scrapeDuration,
+		"errors":   w.errors,
+		"projects": len(projects),
+	}).Debug("container_registry collection completed")
+
+	return w.interrupted
+}
+
+// collectProject records the cleanup policy and registry repositories of
+// one project.
+func (c *RegistryCollector) collectProject(ctx context.Context, project string, obs *registryObservations) error {
+	rest := c.client.REST()
+
+	p, _, err := rest.Projects.GetProject(project, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("get project: %w", err)
+	}
+	cleanup := p.ContainerExpirationPolicy != nil && p.ContainerExpirationPolicy.Enabled
+	obs.cleanupPolicy = append(obs.cleanupPolicy, labeledGauge{labels: []string{project}, value: boolGauge(cleanup)})
+
+	var repos []*gitlab.RegistryRepository
+	opts := &gitlab.ListRegistryRepositoriesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1},
+		TagsCount:   gitlab.Ptr(true),
+	}
+	for opts.Page > 0 {
+		page, resp, err := rest.ContainerRegistry.ListProjectRegistryRepositories(project, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("list registry repositories: %w", err)
+		}
+		repos = append(repos, page...)
+		opts.Page = resp.NextPage
+	}
+	obs.repositories = append(obs.repositories, labeledGauge{labels: []string{project}, value: float64(len(repos))})
+
+	for _, repo := range repos {
+		name := repo.Path
+		if name == "" {
+			name = strconv.Itoa(repo.ID)
+		}
+		labels := []string{project, name}
+		obs.tagsCount = append(obs.tagsCount, labeledGauge{labels: labels, value: float64(repo.TagsCount)})
+
+		if err := c.walkTags(ctx, project, repo, labels, obs); err != nil {
+			c.logger.WithError(err).WithFields(logrus.Fields{
+				"project":    project,
+				"repository": name,
+			}).Warn("failed to walk registry tags")
+		}
+	}
+	return nil
+}
+
+// walkTags fetches the details of up to MaxTagsPerRepository tags of a
+// repository and records their summed size and the oldest and newest tag
+// age. Tags are listed alphabetically, so a truncated walk covers an
+// arbitrary subset: its ages are left out and its size is partial.
+func (c *RegistryCollector) walkTags(ctx context.Context, project string, repo *gitlab.RegistryRepository, labels []string, obs *registryObservations) error {
+	rest := c.client.REST()
+	maxTags := c.config.MaxTagsPerRepository
+
+	var (
+		walked         int
+		truncated      bool
+		size           float64
+		oldest, newest time.Time
+	)
+	opts := &gitlab.ListRegistryRepositoryTagsOptions{PerPage: 100, Page: 1}
+	for opts.Page > 0 && !truncated {
+		tags, resp, err := rest.ContainerRegistry.ListRegistryRepositoryTags(project, repo.ID, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("list tags: %w", err)
+		}
+		for _, t := range tags {
+			if maxTags > 0 && walked >= maxTags {
+				truncated = true
+				break
+			}
+			if err := c.tagLimiter.Wait(ctx); err != nil {
+				return err
+			}
+			detail, _, err := rest.ContainerRegistry.GetRegistryRepositoryTagDetail(project, repo.ID, t.Name, gitlab.WithContext(ctx))
+			if err != nil {
+				return fmt.Errorf("get tag %s: %w", t.Name, err)
+			}
+			walked++
+			size += float64(detail.TotalSize)
+			if detail.CreatedAt != nil {
+				if oldest.IsZero() || detail.CreatedAt.Before(oldest) {
+					oldest = *detail.CreatedAt
+				}
+				if newest.IsZero() || detail.CreatedAt.After(newest) {
+					newest = *detail.CreatedAt
+				}
+			}
+		}
+		opts.Page = resp.NextPage
+		// The limit was reached exactly at the end of a page.
+		if maxTags > 0 && walked >= maxTags && opts.Page > 0 {
+			truncated = true
+		}
+	}
+
+	if truncated {
+		c.logger.WithFields(logrus.Fields{
+			"project":    project,
+			"repository": labels[1],
+			"walked":     walked,
+			"tags":       repo.TagsCount,
+		}).Debug("registry tag walk stopped at max_tags_per_repository")
+	}
+
+	obs.walkedTags = append(obs.walkedTags, labeledGauge{labels: labels, value: float64(walked)})
+	obs.truncated = append(obs.truncated, labeledGauge{labels: labels, value: boolGauge(truncated)})
+	obs.size = append(obs.size, labeledGauge{labels: labels, value: size})
+	if !truncated && !oldest.IsZero() {
+		obs.oldestTagAge = append(obs.oldestTagAge, labeledGauge{labels: labels, value: time.Since(oldest).Seconds()})
+		obs.newestTagAge = append(obs.newestTagAge, labeledGauge{labels: labels, value: time.Since(newest).Seconds()})
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
 
 	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
 	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
@@ -133,7 +134,6 @@ func (c *ContributorsCollector) Collect(ch chan<- prometheus.Metric) {
 // Run performs one collection cycle.
 func (c *ContributorsCollector) Run(ctx context.Context) error {
 	start := time.Now()
-	var errCount float64
 
 	c.mu.RLock()
 	projects := make([]string, len(c.projects))
@@ -143,18 +143,14 @@ func (c *ContributorsCollector) Run(ctx context.Context) error {
 	obs := contributorsObservations{}
 	rest := c.client.REST()
 
-	for _, project := range projects {
-		if err := ctx.Err(); err != nil {
-			return err
-		}
-
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects))
+	w.eachProject(projects, func(project string) {
 		// GET /api/v4/projects/:id/repository/contributors
 		path := fmt.Sprintf("projects/%s/repository/contributors", project)
-		req, err := rest.NewRequest(http.MethodGet, path, nil, nil)
+		req, err := rest.NewRequest(http.MethodGet, path, nil, []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
 		if err != nil {
-			c.logger.WithError(err).WithField("project", project).Error("failed to create contributors request")
-			errCount++
-			continue
+			w.fail(err, "project", project, "failed to create contributors request")
+			return
 		}
 
 		var contributors []contributorResponse
@@ -162,11 +158,10 @@ func (c *ContributorsCollector) Run(ctx context.Context) error {
 		if err != nil {
 			if resp != nil && resp.StatusCode == http.StatusNotFound {
 				c.logger.WithField("project", project).Debug("contributors endpoint not found (empty repo?)")
-				continue
+				return
 			}
-			c.logger.WithError(err).WithField("project", project).Error("failed to fetch contributors")
-			errCount++
-			continue
+			w.fail(err, "project", project, "failed to fetch contributors")
+			return
 		}
 
 		for _, contrib := range contributors {
@@ -189,10 +184,10 @@ func (c *ContributorsCollector) Run(ctx context.Context) error {
 				value:  float64(contrib.Deletions),
 			})
 		}
-	}
+	})
 
 	obs.scrapeDuration = time.Since(start).Seconds()
-	obs.scrapeErrors = errCount
+	obs.scrapeErrors = w.errors
 
 	c.mu.Lock()
 	c.observations = obs
@@ -200,9 +195,9 @@ func (c *ContributorsCollector) Run(ctx context.Context) error {
 
 	c.logger.WithFields(logrus.Fields{
 		"duration": obs.scrapeDuration,
-		"errors":   errCount,
+		"errors":   w.errors,
 		"projects": len(projects),
 	}).Debug("contributors collection completed")
 
-	return nil
+	return w.interrupted
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
 
 	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
 	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
@@ -141,7 +142,6 @@ func (c *DORACollector) Collect(ch chan<- prometheus.Metric) {
 // Run performs one collection cycle.
 func (c *DORACollector) Run(ctx context.Context) error {
 	start := time.Now()
-	var errCount float64
 
 	// Gate on tier feature availability.
 	if features := c.client.Features(); features == nil || !features.HasDORA {
@@ -174,11 +174,8 @@ func (c *DORACollector) Run(ctx context.Context) error {
 		"change_failure_rate",
 	}
 
-	for _, project := range projects {
-		if err := ctx.Err(); err != nil {
-			return err
-		}
-
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects))
+	w.eachProject(projects, func(project string) {
 		for _, envTier := range envTiers {
 			for _, metricType := range doraMetricTypes {
 				labels := []string{project, envTier}
@@ -197,14 +194,14 @@ func (c *DORACollector) Run(ctx context.Context) error {
 					Interval:        "daily",
 				}
 
-				req, err := rest.NewRequest(http.MethodGet, path, reqOpt, nil)
+				req, err := rest.NewRequest(http.MethodGet, path, reqOpt, []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
 				if err != nil {
 					c.logger.WithError(err).WithFields(logrus.Fields{
 						"project": project,
 						"metric":  metricType,
 						"tier":    envTier,
 					}).Error("failed to create DORA request")
-					errCount++
+					w.errors++
 					continue
 				}
 
@@ -223,7 +220,7 @@ func (c *DORACollector) Run(ctx context.Context) error {
 						"metric":  metricType,
 						"tier":    envTier,
 					}).Error("failed to fetch DORA metric")
-					errCount++
+					w.errors++
 					continue
 				}
 
@@ -260,10 +257,10 @@ func (c *DORACollector) Run(ctx context.Context) error {
 				}
 			}
 		}
-	}
+	})
 
 	obs.scrapeDuration = time.Since(start).Seconds()
-	obs.scrapeErrors = errCount
+	obs.scrapeErrors = w.errors
 
 	c.mu.Lock()
 	c.observations = obs
@@ -271,9 +268,9 @@ func (c *DORACollector) Run(ctx context.Context) error {
 
 	c.logger.WithFields(logrus.Fields{
 		"duration": obs.scrapeDuration,
-		"errors":   errCount,
+		"errors":   w.errors,
 		"projects": len(projects),
 	}).Debug("dora collection completed")
 
-	return nil
+	return w.interrupted
 }
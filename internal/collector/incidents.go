@@ -0,0 +1,448 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+)
+
+// maxAlerts bounds the open and the resolved alerts read per project.
+const maxAlerts = 100
+
+var defaultIncidentBuckets = []float64{60, 300, 900, 1800, 3600, 7200, 14400, 43200, 86400, 259200, 604800}
+
+// IncidentsCollector derives incident response metrics from issues of type
+// incident: time to acknowledge (first comment or assignment), time to
+// resolve and open incidents by severity label, per project and per parent
+// group. It works on every tier, unlike the DORA time to restore service.
+type IncidentsCollector struct {
+	client   *gitlabclient.Client
+	config   config.IncidentsCollectorConfig
+	projects []string
+	mu       sync.RWMutex
+	logger   *logrus.Entry
+	tracker  *issueTracker
+
+	// acked maps incident IDs to the time they were acknowledged; the zero
+	// time marks a resolved incident that never was. Guarded by mu.
+	acked      map[int]time.Time
+	alertsOnce sync.Once
+
+	// Prometheus descriptors
+	open           *prometheus.Desc
+	unacknowledged *prometheus.Desc
+	timeToAck      *prometheus.Desc
+	timeToResolve  *prometheus.Desc
+
+	groupOpen          *prometheus.Desc
+	groupTimeToAck     *prometheus.Desc
+	groupTimeToResolve *prometheus.Desc
+
+	alertsOpen         *prometheus.Desc
+	alertTimeToResolve *prometheus.Desc
+
+	// Internal operational metrics
+	scrapeDuration *prometheus.Desc
+	scrapeErrors   *prometheus.Desc
+
+	// Collected observations (mutex-protected)
+	observations incidentObservations
+}
+
+type incidentObservations struct {
+	open           []labeledGauge
+	unacknowledged []labeledGauge
+	timeToAck      []labeledValue
+	timeToResolve  []labeledValue
+
+	groupOpen          []labeledGauge
+	groupTimeToAck     []labeledValue
+	groupTimeToResolve []labeledValue
+
+	alertsOpen         []labeledGauge
+	alertTimeToResolve []labeledValue
+
+	scrapeDuration float64
+	scrapeErrors   float64
+}
+
+// NewIncidentsCollector creates a new incidents collector.
+func NewIncidentsCollector(client *gitlabclient.Client, cfg config.IncidentsCollectorConfig, projects []string) *IncidentsCollector {
+	projectLabels := []string{"project"}
+	groupLabels := []string{"group"}
+
+	return &IncidentsCollector{
+		client:   client,
+		config:   cfg,
+		projects: projects,
+		logger:   logrus.WithField("collector", "incidents"),
+		tracker:  newIssueTracker("incident", cfg.ResolvedWindow(), cfg.FullSyncInterval()),
+		acked:    make(map[int]time.Time),
+
+		open: prometheus.NewDesc(
+			"age_incident_open_count",
+			"Number of open incidents by severity label (\"unknown\" without one).",
+			[]string{"project", "severity"}, nil,
+		),
+		unacknowledged: prometheus.NewDesc(
+			"age_incident_unacknowledged_count",
+			"Number of open incidents nobody commented on or was assigned to yet.",
+			projectLabels, nil,
+		),
+		timeToAck: prometheus.NewDesc(
+			"age_incident_time_to_acknowledge_seconds",
+			"Time from incident creation to the first comment or assignment, in seconds.",
+			projectLabels, nil,
+		),
+		timeToResolve: prometheus.NewDesc(
+			"age_incident_time_to_resolve_seconds",
+			"Time from incident creation to close of incidents resolved within the window, in seconds.",
+			projectLabels, nil,
+		),
+
+		groupOpen: prometheus.NewDesc(
+			"age_incident_group_open_count",
+			"Number of open incidents of the group's projects by severity label.",
+			[]string{"group", "severity"}, nil,
+		),
+		groupTimeToAck: prometheus.NewDesc(
+			"age_incident_group_time_to_acknowledge_seconds",
+			"Time from incident creation to the first comment or assignment across the group's projects, in seconds.",
+			groupLabels, nil,
+		),
+		groupTimeToResolve: prometheus.NewDesc(
+			"age_incident_group_time_to_resolve_seconds",
+			"Time to resolve incidents of the group's projects resolved within the window, in seconds.",
+			groupLabels, nil,
+		),
+
+		alertsOpen: prometheus.NewDesc(
+			"age_alert_open_count",
+			"Number of triggered or acknowledged alert management alerts.",
+			[]string{"project", "severity", "status"}, nil,
+		),
+		alertTimeToResolve: prometheus.NewDesc(
+			"age_alert_time_to_resolve_seconds",
+			"Time from alert start to end of recently resolved alerts, in seconds.",
+			projectLabels, nil,
+		),
+
+		scrapeDuration: prometheus.NewDesc(
+			"age_scrape_duration_seconds",
+			"Time taken by the collector scrape.",
+			[]string{"collector_type"}, nil,
+		),
+		scrapeErrors: prometheus.NewDesc(
+			"age_scrape_errors_total",
+			"Total number of scrape errors.",
+			[]string{"collector_type"}, nil,
+		),
+	}
+}
+
+func (c *IncidentsCollector) Name() string  { return "incidents" }
+func (c *IncidentsCollector) Enabled() bool { return c.config.Enabled }
+
+// SetProjects updates the list of tracked projects.
+func (c *IncidentsCollector) SetProjects(projects []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.projects = projects
+}
+
+// Describe implements prometheus.Collector.
+func (c *IncidentsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.open
+	ch <- c.unacknowledged
+	ch <- c.timeToAck
+	ch <- c.timeToResolve
+	ch <- c.groupOpen
+	ch <- c.groupTimeToAck
+	ch <- c.groupTimeToResolve
+	ch <- c.alertsOpen
+	ch <- c.alertTimeToResolve
+	ch <- c.scrapeDuration
+	ch <- c.scrapeErrors
+}
+
+// Collect implements prometheus.Collector.
+func (c *IncidentsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	obs := c.observations
+	c.mu.RUnlock()
+
+	buckets := c.config.HistogramBuckets
+	if len(buckets) == 0 {
+		buckets = defaultIncidentBuckets
+	}
+
+	gauges := []struct {
+		desc *prometheus.Desc
+		obs  []labeledGauge
+	}{
+		{c.open, obs.open},
+		{c.unacknowledged, obs.unacknowledged},
+		{c.groupOpen, obs.groupOpen},
+		{c.alertsOpen, obs.alertsOpen},
+	}
+	for _, g := range gauges {
+		for _, o := range g.obs {
+			ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, o.value, o.labels...)
+		}
+	}
+	emitHistograms(ch, c.timeToAck, obs.timeToAck, buckets)
+	emitHistograms(ch, c.timeToResolve, obs.timeToResolve, buckets)
+	emitHistograms(ch, c.groupTimeToAck, obs.groupTimeToAck, buckets)
+	emitHistograms(ch, c.groupTimeToResolve, obs.groupTimeToResolve, buckets)
+	emitHistograms(ch, c.alertTimeToResolve, obs.alertTimeToResolve, buckets)
+
+	ch <- prometheus.MustNewConstMetric(c.scrapeDuration, prometheus.GaugeValue, obs.scrapeDuration, "incidents")
+	ch <- prometheus.MustNewConstMetric(c.scrapeErrors, prometheus.CounterValue, obs.scrapeErrors, "incidents")
+}
+
+// Run performs one collection cycle.
+func (c *IncidentsCollector) Run(ctx context.Context) error {
+	start := time.Now()
+
+	c.mu.RLock()
+	projects := make([]string, len(c.projects))
+	copy(projects, c.projects)
+	c.mu.RUnlock()
+
+	c.tracker.retain(projects)
+	obs := incidentObservations{}
+	groupOpen := make(map[[2]string]int) // group, severity -> count
+	seen := make(map[int]bool)
+
+	alerts := c.config.IncludeAlerts
+	if alerts && !c.client.GraphQLEnabled() {
+		c.alertsOnce.Do(func() {
+			c.logger.Warn("include_alerts requires gitlab.use_graphql, skipping alerts")
+		})
+		alerts = false
+	}
+
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects))
+	w.projects(projects, "failed to sync incidents", func(project string) error {
+		open, resolved, err := c.tracker.sync(ctx, c.client.REST(), project)
+		if err != nil {
+			return err
+		}
+		groups := parentGroups(project)
+
+		bySeverity := make(map[string]int)
+		var unacknowledged int
+		for _, incident := range append(open, resolved...) {
+			seen[incident.ID] = true
+			if incident.CreatedAt == nil {
+				continue
+			}
+
+			ackedAt, err := c.acknowledgedAt(ctx, project, incident)
+			if err != nil {
+				c.logger.WithError(err).WithFields(logrus.Fields{
+					"project":  project,
+					"incident": incident.IID,
+				}).Warn("failed to read incident notes")
+				w.errors++
+			}
+			if !ackedAt.IsZero() {
+				tta := ackedAt.Sub(*incident.CreatedAt).Seconds()
+				obs.timeToAck = append(obs.timeToAck, labeledValue{labels: []string{project}, value: tta})
+				for _, g := range groups {
+					obs.groupTimeToAck = append(obs.groupTimeToAck, labeledValue{labels: []string{g}, value: tta})
+				}
+			}
+
+			if incident.State == "opened" {
+				severity := c.severity(incident)
+				bySeverity[severity]++
+				for _, g := range groups {
+					groupOpen[[2]string{g, severity}]++
+				}
+				if ackedAt.IsZero() {
+					unacknowledged++
+				}
+				continue
+			}
+
+			if incident.ClosedAt != nil {
+				ttr := incident.ClosedAt.Sub(*incident.CreatedAt).Seconds()
+				obs.timeToResolve = append(obs.timeToResolve, labeledValue{labels: []string{project}, value: ttr})
+				for _, g := range groups {
+					obs.groupTimeToResolve = append(obs.groupTimeToResolve, labeledValue{labels: []string{g}, value: ttr})
+				}
+			}
+		}
+
+		for severity, n := range bySeverity {
+			obs.open = append(obs.open, labeledGauge{labels: []string{project, severity}, value: float64(n)})
+		}
+		obs.unacknowledged = append(obs.unacknowledged, labeledGauge{labels: []string{project}, value: float64(unacknowledged)})
+
+		if alerts {
+			if err := c.collectAlerts(ctx, project, &obs); err != nil {
+				w.fail(err, "project", project, "failed to collect alerts")
+			}
+		}
+		return nil
+	})
+
+	keys := make([][2]string, 0, len(groupOpen))
+	for k := range groupOpen {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	for _, k := range keys {
+		obs.groupOpen = append(obs.groupOpen, labeledGauge{labels: []string{k[0], k[1]}, value: float64(groupOpen[k])})
+	}
+
+	obs.scrapeDuration = time.Since(start).Seconds()
+	obs.scrapeErrors = w.errors
+
+	c.mu.Lock()
+	c.observations = obs
+	// Forget acknowledgements of incidents that left the window, unless the
+	// run was cut short and did not visit every project.
+	if w.interrupted == nil {
+		for id := range c.acked {
+			if !seen[id] {
+				delete(c.acked, id)
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	c.logger.WithFields(logrus.Fields{
+		"duration": obs.scrapeDuration,
+		"errors":   w.errors,
+		"projects": len(projects),
+	}).Debug("incidents collection completed")
+
+	return w.interrupted
+}
+
+// severity returns the incident's severity label value, or "unknown".
+func (c *IncidentsCollector) severity(incident *gitlab.Issue) string {
+	prefix := c.config.SeverityLabelPrefix
+	if prefix == "" {
+		return "unknown"
+	}
+	for _, l := range incident.Labels {
+		if strings.HasPrefix(l, prefix) && len(l) > len(prefix) {
+			return strings.TrimPrefix(l, prefix)
+		}
+	}
+	return "unknown"
+}
+
+// acknowledgedAt returns when an incident was first commented on or
+// assigned, or the zero time if it has not been yet. Acknowledgements are
+// cached, so each incident's notes are read until one is found; those of
+// resolved incidents are read once.
+func (c *IncidentsCollector) acknowledgedAt(ctx context.Context, project string, incident *gitlab.Issue) (time.Time, error) {
+	c.mu.RLock()
+	ackedAt, ok := c.acked[incident.ID]
+	c.mu.RUnlock()
+	if ok {
+		return ackedAt, nil
+	}
+
+	opts := &gitlab.ListIssueNotesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1},
+		OrderBy:     gitlab.Ptr("created_at"),
+		Sort:        gitlab.Ptr("asc"),
+	}
+	for opts.Page > 0 && ackedAt.IsZero() {
+		notes, resp, err := c.client.REST().Notes.ListIssueNotes(project, incident.IID, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("list notes of incident %d: %w", incident.IID, err)
+		}
+		for _, n := range notes {
+			if n.CreatedAt == nil {
+				continue
+			}
+			if !n.System || strings.HasPrefix(n.Body, "assigned to") {
+				ackedAt = *n.CreatedAt
+				break
+			}
+		}
+		opts.Page = resp.NextPage
+	}
+
+	// An incident created with an assignee has no assignment note.
+	if ackedAt.IsZero() && (incident.Assignee != nil || len(incident.Assignees) > 0) {
+		ackedAt = *incident.CreatedAt
+	}
+
+	if !ackedAt.IsZero() || incident.State != "opened" {
+		c.mu.Lock()
+		c.acked[incident.ID] = ackedAt
+		c.mu.Unlock()
+	}
+	return ackedAt, nil
+}
+
+// collectAlerts records the open alerts of a project and the time to
+// resolve of the ones resolved within the window.
+func (c *IncidentsCollector) collectAlerts(ctx context.Context, project string, obs *incidentObservations) error {
+	open, resolved, err := c.client.FetchProjectAlerts(ctx, project, maxAlerts)
+	if err != nil {
+		return err
+	}
+
+	type alertKey struct{ severity, status string }
+	counts := make(map[alertKey]int)
+	for _, a := range open {
+		counts[alertKey{strings.ToLower(a.Severity), strings.ToLower(a.Status)}]++
+	}
+	for k, n := range counts {
+		obs.alertsOpen = append(obs.alertsOpen, labeledGauge{labels: []string{project, k.severity, k.status}, value: float64(n)})
+	}
+
+	cutoff := time.Now().Add(-c.config.ResolvedWindow())
+	for _, a := range resolved {
+		if a.EndedAt == nil {
+			continue
+		}
+		startedAt, err := time.Parse(time.RFC3339, a.StartedAt)
+		if err != nil {
+			continue
+		}
+		endedAt, err := time.Parse(time.RFC3339, *a.EndedAt)
+		if err != nil || endedAt.Before(cutoff) {
+			continue
+		}
+		obs.alertTimeToResolve = append(obs.alertTimeToResolve, labeledValue{
+			labels: []string{project},
+			value:  endedAt.Sub(startedAt).Seconds(),
+		})
+	}
+	return nil
+}
+
+// parentGroups returns every group a project path is nested in, from the
+// top-level group down: "a/b/c" yields "a" and "a/b".
+func parentGroups(project string) []string {
+	parts := strings.Split(project, "/")
+	groups := make([]string, 0, len(parts)-1)
+	for i := 1; i < len(parts); i++ {
+		groups = append(groups, strings.Join(parts[:i], "/"))
+	}
+	return groups
+}
@@ -0,0 +1,430 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+)
+
+// issueSyncOverlap is subtracted from the last sync time when fetching
+// updated issues, to tolerate clock skew between the exporter and GitLab.
+const issueSyncOverlap = 5 * time.Minute
+
+var defaultIssueBuckets = []float64{3600, 86400, 259200, 604800, 1209600, 2592000, 7776000, 15552000, 31536000}
+
+// IssuesCollector reports open issue counts, ages and estimates and the
+// time-to-close of recently closed issues of the tracked projects.
+type IssuesCollector struct {
+	client   *gitlabclient.Client
+	config   config.IssuesCollectorConfig
+	projects []string
+	mu       sync.RWMutex
+	logger   *logrus.Entry
+	tracker  *issueTracker
+
+	// Prometheus descriptors
+	openCount       *prometheus.Desc
+	openByLabel     *prometheus.Desc
+	openByMilestone *prometheus.Desc
+	openAge         *prometheus.Desc
+	timeToClose     *prometheus.Desc
+	openWeight      *prometheus.Desc
+	openEstimate    *prometheus.Desc
+
+	// Internal operational metrics
+	scrapeDuration *prometheus.Desc
+	scrapeErrors   *prometheus.Desc
+
+	// Collected observations (mutex-protected)
+	observations issueObservations
+}
+
+type issueObservations struct {
+	openCount       []labeledGauge
+	openByLabel     []labeledGauge
+	openByMilestone []labeledGauge
+	openAge         []labeledValue
+	timeToClose     []labeledValue
+	openWeight      []labeledGauge
+	openEstimate    []labeledGauge
+	scrapeDuration  float64
+	scrapeErrors    float64
+}
+
+// NewIssuesCollector creates a new issues collector.
+func NewIssuesCollector(client *gitlabclient.Client, cfg config.IssuesCollectorConfig, projects []string) *IssuesCollector {
+	projectLabels := []string{"project"}
+
+	return &IssuesCollector{
+		client:   client,
+		config:   cfg,
+		projects: projects,
+		logger:   logrus.WithField("collector", "issues"),
+		tracker:  newIssueTracker("", cfg.ClosedWindow(), cfg.FullSyncInterval()),
+
+		openCount: prometheus.NewDesc(
+			"age_issue_open_count",
+			"Number of open issues by issue type and whether they are assigned.",
+			[]string{"project", "issue_type", "assigned"}, nil,
+		),
+		openByLabel: prometheus.NewDesc(
+			"age_issue_open_by_label_count",
+			"Number of open issues with the label, for the labels in collectors.issues.labels.",
+			[]string{"project", "label"}, nil,
+		),
+		openByMilestone: prometheus.NewDesc(
+			"age_issue_open_by_milestone_count",
+			"Number of open issues by milestone (\"none\" for issues without one).",
+			[]string{"project", "milestone"}, nil,
+		),
+		openAge: prometheus.NewDesc(
+			"age_issue_open_age_seconds",
+			"Age of open issues in seconds.",
+			projectLabels, nil,
+		),
+		timeToClose: prometheus.NewDesc(
+			"age_issue_time_to_close_seconds",
+			"Time from creation to close of issues closed within the window, in seconds.",
+			projectLabels, nil,
+		),
+		openWeight: prometheus.NewDesc(
+			"age_issue_open_weight",
+			"Sum of the weights of open issues.",
+			projectLabels, nil,
+		),
+		openEstimate: prometheus.NewDesc(
+			"age_issue_open_time_estimate_seconds",
+			"Sum of the time estimates of open issues in seconds.",
+			projectLabels, nil,
+		),
+
+		scrapeDuration: prometheus.NewDesc(
+			"age_scrape_duration_seconds",
+			"Time taken by the collector scrape.",
+			[]string{"collector_type"}, nil,
+		),
+		scrapeErrors: prometheus.NewDesc(
+			"age_scrape_errors_total",
+			"Total number of scrape errors.",
+			[]string{"collector_type"}, nil,
+		),
+	}
+}
+
+func (c *IssuesCollector) Name() string  { return "issues" }
+func (c *IssuesCollector) Enabled() bool { return c.config.Enabled }
+
+// SetProjects updates the list of tracked projects.
+func (c *IssuesCollector) SetProjects(projects []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.projects = projects
+}
+
+// Describe implements prometheus.Collector.
+func (c *IssuesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.openCount
+	ch <- c.openByLabel
+	ch <- c.openByMilestone
+	ch <- c.openAge
+	ch <- c.timeToClose
+	ch <- c.openWeight
+	ch <- c.openEstimate
+	ch <- c.scrapeDuration
+	ch <- c.scrapeErrors
+}
+
+// Collect implements prometheus.Collector.
+func (c *IssuesCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	obs := c.observations
+	c.mu.RUnlock()
+
+	buckets := c.config.HistogramBuckets
+	if len(buckets) == 0 {
+		buckets = defaultIssueBuckets
+	}
+
+	gauges := []struct {
+		desc *prometheus.Desc
+		obs  []labeledGauge
+	}{
+		{c.openCount, obs.openCount},
+		{c.openByLabel, obs.openByLabel},
+		{c.openByMilestone, obs.openByMilestone},
+		{c.openWeight, obs.openWeight},
+		{c.openEstimate, obs.openEstimate},
+	}
+	for _, g := range gauges {
+		for _, o := range g.obs {
+			ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, o.value, o.labels...)
+		}
+	}
+	emitHistograms(ch, c.openAge, obs.openAge, buckets)
+	emitHistograms(ch, c.timeToClose, obs.timeToClose, buckets)
+
+	ch <- prometheus.MustNewConstMetric(c.scrapeDuration, prometheus.GaugeValue, obs.scrapeDuration, "issues")
+	ch <- prometheus.MustNewConstMetric(c.scrapeErrors, prometheus.CounterValue, obs.scrapeErrors, "issues")
+}
+
+// Run performs one collection cycle.
+func (c *IssuesCollector) Run(ctx context.Context) error {
+	start := time.Now()
+
+	c.mu.RLock()
+	projects := make([]string, len(c.projects))
+	copy(projects, c.projects)
+	c.mu.RUnlock()
+
+	c.tracker.retain(projects)
+	obs := issueObservations{}
+
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects))
+	w.projects(projects, "failed to sync issues", func(project string) error {
+		open, closed, err := c.tracker.sync(ctx, c.client.REST(), project)
+		if err != nil {
+			return err
+		}
+		c.observe(project, open, closed, &obs)
+		return nil
+	})
+
+	obs.scrapeDuration = time.Since(start).Seconds()
+	obs.scrapeErrors = w.errors
+
+	c.mu.Lock()
+	c.observations = obs
+	c.mu.Unlock()
+
+	c.logger.WithFields(logrus.Fields{
+		"duration": obs.scrapeDuration,
+		"errors":   w.errors,
+		"projects": len(projects),
+	}).Debug("issues collection completed")
+
+	return w.interrupted
+}
+
+// observe records the observations of one project's issues.
+func (c *IssuesCollector) observe(project string, open, closed []*gitlab.Issue, obs *issueObservations) {
+	now := time.Now()
+
+	type typeKey struct{ issueType, assigned string }
+	byType := make(map[typeKey]int)
+	byMilestone := make(map[string]int)
+	byLabel := make(map[string]int, len(c.config.Labels))
+	for _, l := range c.config.Labels {
+		byLabel[l] = 0
+	}
+	var weight, estimate float64
+
+	for _, issue := range open {
+		issueType := "issue"
+		if issue.IssueType != nil && *issue.IssueType != "" {
+			issueType = *issue.IssueType
+		}
+		assigned := "false"
+		if len(issue.Assignees) > 0 || issue.Assignee != nil {
+			assigned = "true"
+		}
+		byType[typeKey{issueType, assigned}]++
+
+		milestone := "none"
+		if issue.Milestone != nil {
+			milestone = issue.Milestone.Title
+		}
+		byMilestone[milestone]++
+
+		for _, l := range issue.Labels {
+			if _, ok := byLabel[l]; ok {
+				byLabel[l]++
+			}
+		}
+
+		weight += float64(issue.Weight)
+		if issue.TimeStats != nil {
+			estimate += float64(issue.TimeStats.TimeEstimate)
+		}
+		if issue.CreatedAt != nil {
+			obs.openAge = append(obs.openAge, labeledValue{
+				labels: []string{project},
+				value:  now.Sub(*issue.CreatedAt).Seconds(),
+			})
+		}
+	}
+
+	for _, issue := range closed {
+		if issue.CreatedAt != nil && issue.ClosedAt != nil {
+			obs.timeToClose = append(obs.timeToClose, labeledValue{
+				labels: []string{project},
+				value:  issue.ClosedAt.Sub(*issue.CreatedAt).Seconds(),
+			})
+		}
+	}
+
+	for k, n := range byType {
+		obs.openCount = append(obs.openCount, labeledGauge{labels: []string{project, k.issueType, k.assigned}, value: float64(n)})
+	}
+	for l, n := range byLabel {
+		obs.openByLabel = append(obs.openByLabel, labeledGauge{labels: []string{project, l}, value: float64(n)})
+	}
+	for m, n := range byMilestone {
+		obs.openByMilestone = append(obs.openByMilestone, labeledGauge{labels: []string{project, m}, value: float64(n)})
+	}
+	obs.openWeight = append(obs.openWeight, labeledGauge{labels: []string{project}, value: weight})
+	obs.openEstimate = append(obs.openEstimate, labeledGauge{labels: []string{project}, value: estimate})
+}
+
+// issueTracker keeps the open and recently closed issues of each project
+// up to date by fetching only the issues updated since the previous sync.
+// Every fullSync it lists the project's issues afresh, which also drops
+// issues that were deleted (deletions do not show up as updates).
+type issueTracker struct {
+	mu        sync.Mutex
+	issueType string
+	window    time.Duration
+	fullSync  time.Duration
+	projects  map[string]*projectIssues
+}
+
+// projectIssues is the tracked state of one project.
+type projectIssues struct {
+	open       map[int]*gitlab.Issue
+	closed     map[int]*gitlab.Issue
+	synced     time.Time
+	fullSynced time.Time
+}
+
+// newIssueTracker creates a tracker for issues of issueType (all types when
+// empty) that keeps closed issues for window.
+func newIssueTracker(issueType string, window, fullSync time.Duration) *issueTracker {
+	return &issueTracker{
+		issueType: issueType,
+		window:    window,
+		fullSync:  fullSync,
+		projects:  make(map[string]*projectIssues),
+	}
+}
+
+// retain drops the state of projects that are no longer tracked.
+func (t *issueTracker) retain(projects []string) {
+	keep := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		keep[p] = true
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for p := range t.projects {
+		if !keep[p] {
+			delete(t.projects, p)
+		}
+	}
+}
+
+// sync brings a project's issues up to date and returns its open issues
+// and the issues closed within the window, ordered by ID.
+func (t *issueTracker) sync(ctx context.Context, rest *gitlab.Client, project string) (open, closed []*gitlab.Issue, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-t.window)
+	p := t.projects[project]
+
+	if p == nil || t.fullSync <= 0 || now.Sub(p.fullSynced) >= t.fullSync {
+		fresh := &projectIssues{
+			open:       make(map[int]*gitlab.Issue),
+			closed:     make(map[int]*gitlab.Issue),
+			synced:     now,
+			fullSynced: now,
+		}
+		opened, err := t.list(ctx, rest, project, "opened", nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, issue := range opened {
+			fresh.open[issue.ID] = issue
+		}
+		recent, err := t.list(ctx, rest, project, "closed", &cutoff)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, issue := range recent {
+			fresh.closed[issue.ID] = issue
+		}
+		p = fresh
+		t.projects[project] = p
+	} else {
+		since := p.synced.Add(-issueSyncOverlap)
+		updated, err := t.list(ctx, rest, project, "", &since)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, issue := range updated {
+			if issue.State == "opened" {
+				p.open[issue.ID] = issue
+				delete(p.closed, issue.ID)
+				continue
+			}
+			delete(p.open, issue.ID)
+			p.closed[issue.ID] = issue
+		}
+		p.synced = now
+	}
+
+	for id, issue := range p.closed {
+		if issue.ClosedAt == nil || issue.ClosedAt.Before(cutoff) {
+			delete(p.closed, id)
+		}
+	}
+	return sortedIssues(p.open), sortedIssues(p.closed), nil
+}
+
+// list fetches every page of a project's issues in state (any state when
+// empty), optionally only those updated after since.
+func (t *issueTracker) list(ctx context.Context, rest *gitlab.Client, project, state string, since *time.Time) ([]*gitlab.Issue, error) {
+	opts := &gitlab.ListProjectIssuesOptions{
+		ListOptions:  gitlab.ListOptions{PerPage: 100, Page: 1},
+		UpdatedAfter: since,
+	}
+	if state != "" {
+		opts.State = gitlab.Ptr(state)
+	}
+	if t.issueType != "" {
+		opts.IssueType = gitlab.Ptr(t.issueType)
+	}
+
+	var all []*gitlab.Issue
+	for opts.Page > 0 {
+		issues, resp, err := rest.Issues.ListProjectIssues(project, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("list issues in %s: %w", project, err)
+		}
+		for _, issue := range issues {
+			// Descriptions are not used and can be large.
+			issue.Description = ""
+		}
+		all = append(all, issues...)
+		opts.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+// sortedIssues returns the issues of a map ordered by ID.
+func sortedIssues(m map[int]*gitlab.Issue) []*gitlab.Issue {
+	out := make([]*gitlab.Issue, 0, len(m))
+	for _, issue := range m {
+		out = append(out, issue)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
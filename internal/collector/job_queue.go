@@ -0,0 +1,343 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+)
+
+// maxQueueWaitPages bounds the pages of recent jobs read per project for
+// the queue wait histogram, so a busy project cannot exhaust the budget.
+const maxQueueWaitPages = 10
+
+var defaultQueueBuckets = []float64{5, 10, 30, 60, 120, 300, 600, 1800, 3600}
+
+// JobQueueCollector measures the job queue of the tracked projects: jobs
+// that are created, waiting for a resource group or pending, grouped by
+// status, the runner tags the jobs require and whether they run on a
+// protected ref (and therefore only on protected runners).
+type JobQueueCollector struct {
+	client   *gitlabclient.Client
+	config   config.JobQueueCollectorConfig
+	projects []string
+	mu       sync.RWMutex
+	logger   *logrus.Entry
+
+	// Prometheus descriptors
+	pending       *prometheus.Desc
+	oldestPending *prometheus.Desc
+	wait          *prometheus.Desc
+
+	// Internal operational metrics
+	scrapeDuration *prometheus.Desc
+	scrapeErrors   *prometheus.Desc
+
+	// Collected observations (mutex-protected)
+	observations jobQueueObservations
+}
+
+type jobQueueObservations struct {
+	pending        []labeledGauge
+	oldestPending  []labeledGauge
+	wait           []labeledValue
+	scrapeDuration float64
+	scrapeErrors   float64
+}
+
+// NewJobQueueCollector creates a new job queue collector.
+func NewJobQueueCollector(client *gitlabclient.Client, cfg config.JobQueueCollectorConfig, projects []string) *JobQueueCollector {
+	queueLabels := []string{"tags", "protected", "status"}
+	waitLabels := []string{"tags", "protected"}
+
+	return &JobQueueCollector{
+		client:   client,
+		config:   cfg,
+		projects: projects,
+		logger:   logrus.WithField("collector", "job_queue"),
+
+		pending: prometheus.NewDesc(
+			"age_job_queue_pending_jobs",
+			"Number of jobs with the tag set that have not started, by status (created, waiting_for_resource or pending).",
+			queueLabels, nil,
+		),
+		oldestPending: prometheus.NewDesc(
+			"age_job_queue_oldest_pending_age_seconds",
+			"Seconds the longest-waiting job with the tag set and status has been queued.",
+			queueLabels, nil,
+		),
+		wait: prometheus.NewDesc(
+			"age_job_queue_wait_seconds",
+			"Time recently started jobs with the tag set waited for a runner.",
+			waitLabels, nil,
+		),
+
+		scrapeDuration: prometheus.NewDesc(
+			"age_scrape_duration_seconds",
+			"Time taken by the collector scrape.",
+			[]string{"collector_type"}, nil,
+		),
+		scrapeErrors: prometheus.NewDesc(
+			"age_scrape_errors_total",
+			"Total number of scrape errors.",
+			[]string{"collector_type"}, nil,
+		),
+	}
+}
+
+func (c *JobQueueCollector) Name() string  { return "job_queue" }
+func (c *JobQueueCollector) Enabled() bool { return c.config.Enabled }
+
+// SetProjects updates the list of tracked projects.
+func (c *JobQueueCollector) SetProjects(projects []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.projects = projects
+}
+
+// Describe implements prometheus.Collector.
+func (c *JobQueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.pending
+	ch <- c.oldestPending
+	ch <- c.wait
+	ch <- c.scrapeDuration
+	ch <- c.scrapeErrors
+}
+
+// Collect implements prometheus.Collector.
+func (c *JobQueueCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	obs := c.observations
+	c.mu.RUnlock()
+
+	buckets := c.config.HistogramBuckets
+	if len(buckets) == 0 {
+		buckets = defaultQueueBuckets
+	}
+
+	for _, o := range obs.pending {
+		ch <- prometheus.MustNewConstMetric(c.pending, prometheus.GaugeValue, o.value, o.labels...)
+	}
+	for _, o := range obs.oldestPending {
+		ch <- prometheus.MustNewConstMetric(c.oldestPending, prometheus.GaugeValue, o.value, o.labels...)
+	}
+	emitHistograms(ch, c.wait, obs.wait, buckets)
+
+	ch <- prometheus.MustNewConstMetric(c.scrapeDuration, prometheus.GaugeValue, obs.scrapeDuration, "job_queue")
+	ch <- prometheus.MustNewConstMetric(c.scrapeErrors, prometheus.CounterValue, obs.scrapeErrors, "job_queue")
+}
+
+// jobQueueKey identifies one queue: the tag set jobs require, whether
+// they run on a protected ref ("true", "false" or "unknown") and the job
+// status.
+type jobQueueKey struct {
+	tags      string
+	protected string
+	status    string
+}
+
+// jobQueue aggregates the pending jobs of one queue.
+type jobQueue struct {
+	pending int
+	oldest  float64
+}
+
+// Run performs one collection cycle.
+func (c *JobQueueCollector) Run(ctx context.Context) error {
+	start := time.Now()
+
+	c.mu.RLock()
+	projects := make([]string, len(c.projects))
+	copy(projects, c.projects)
+	c.mu.RUnlock()
+
+	obs := jobQueueObservations{}
+	queues := make(map[jobQueueKey]*jobQueue)
+
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects))
+	w.projects(projects, "failed to collect job queue", func(project string) error {
+		return c.collectProject(ctx, project, queues, &obs)
+	})
+
+	keys := make([]jobQueueKey, 0, len(queues))
+	for k := range queues {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].tags != keys[j].tags {
+			return keys[i].tags < keys[j].tags
+		}
+		if keys[i].protected != keys[j].protected {
+			return keys[i].protected < keys[j].protected
+		}
+		return keys[i].status < keys[j].status
+	})
+	for _, k := range keys {
+		q := queues[k]
+		labels := []string{k.tags, k.protected, k.status}
+		obs.pending = append(obs.pending, labeledGauge{labels: labels, value: float64(q.pending)})
+		obs.oldestPending = append(obs.oldestPending, labeledGauge{labels: labels, value: q.oldest})
+	}
+
+	obs.scrapeDuration = time.Since(start).Seconds()
+	obs.scrapeErrors = w.errors
+
+	c.mu.Lock()
+	c.observations = obs
+	c.mu.Unlock()
+
+	c.logger.WithFields(logrus.Fields{
+		"duration": obs.scrapeDuration,
+		"errors":   w.errors,
+		"projects": len(projects),
+		"queues":   len(queues),
+	}).Debug("job_queue collection completed")
+
+	return w.interrupted
+}
+
+// collectProject adds the jobs of a project that have not started to
+// queues and the queue wait of its recently started jobs to obs.
+func (c *JobQueueCollector) collectProject(ctx context.Context, project string, queues map[jobQueueKey]*jobQueue, obs *jobQueueObservations) error {
+	rest := c.client.REST()
+	refs := &protectedRefs{project: project}
+
+	for page := 1; page > 0; {
+		jobs, resp, err := rest.Jobs.ListProjectJobs(project, &gitlab.ListJobsOptions{
+			ListOptions: gitlab.ListOptions{PerPage: 100, Page: page},
+			Scope: &[]gitlab.BuildStateValue{
+				gitlab.Created, gitlab.WaitingForResource, gitlab.Pending,
+			},
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("list queued jobs in %s: %w", project, err)
+		}
+		for _, j := range jobs {
+			key := jobQueueKey{tags: tagGroupName(j.TagList), protected: c.protected(ctx, refs, j), status: j.Status}
+			q := queues[key]
+			if q == nil {
+				q = &jobQueue{}
+				queues[key] = q
+			}
+			q.pending++
+			waited := j.QueuedDuration
+			if waited == 0 && j.CreatedAt != nil {
+				waited = time.Since(*j.CreatedAt).Seconds()
+			}
+			q.oldest = max(q.oldest, waited)
+		}
+		page = resp.NextPage
+	}
+
+	// Jobs are listed newest first, so paging stops at the first job created
+	// before the wait window.
+	cutoff := time.Now().Add(-c.config.WaitWindow())
+	for page := 1; page > 0 && page <= maxQueueWaitPages; {
+		jobs, resp, err := rest.Jobs.ListProjectJobs(project, &gitlab.ListJobsOptions{
+			ListOptions: gitlab.ListOptions{PerPage: 100, Page: page},
+			Scope: &[]gitlab.BuildStateValue{
+				gitlab.Running, gitlab.Success, gitlab.Failed, gitlab.Canceled,
+			},
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("list recent jobs in %s: %w", project, err)
+		}
+		page = resp.NextPage
+		for _, j := range jobs {
+			if j.CreatedAt != nil && j.CreatedAt.Before(cutoff) {
+				page = 0
+				break
+			}
+			// Jobs cancelled while pending never left the queue.
+			if j.StartedAt == nil {
+				continue
+			}
+			obs.wait = append(obs.wait, labeledValue{
+				labels: []string{tagGroupName(j.TagList), c.protected(ctx, refs, j)},
+				value:  j.QueuedDuration,
+			})
+		}
+	}
+	return nil
+}
+
+// protectedRefs holds the protected branch and tag patterns of a project,
+// fetched on first use.
+type protectedRefs struct {
+	project  string
+	loaded   bool
+	failed   bool
+	branches []*regexp.Regexp
+	tags     []*regexp.Regexp
+}
+
+// protected reports whether a job runs on a protected branch or tag as
+// "true" or "false", or "unknown" when the patterns could not be listed.
+// Merge request pipelines run on refs/merge-requests/* and are never
+// protected.
+func (c *JobQueueCollector) protected(ctx context.Context, refs *protectedRefs, j *gitlab.Job) string {
+	if !refs.loaded {
+		refs.loaded = true
+		if err := c.loadProtectedRefs(ctx, refs); err != nil {
+			c.logger.WithError(err).WithField("project", refs.project).Debug("failed to list protected refs")
+			refs.failed = true
+		}
+	}
+	if refs.failed {
+		return "unknown"
+	}
+
+	patterns := refs.branches
+	if j.Tag {
+		patterns = refs.tags
+	}
+	for _, p := range patterns {
+		if p.MatchString(j.Ref) {
+			return "true"
+		}
+	}
+	return "false"
+}
+
+// loadProtectedRefs lists the protected branch and tag patterns of a
+// project.
+func (c *JobQueueCollector) loadProtectedRefs(ctx context.Context, refs *protectedRefs) error {
+	rest := c.client.REST()
+
+	branches, _, err := rest.ProtectedBranches.ListProtectedBranches(refs.project, &gitlab.ListProtectedBranchesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("list protected branches: %w", err)
+	}
+	for _, b := range branches {
+		refs.branches = append(refs.branches, wildcardPattern(b.Name))
+	}
+
+	tags, _, err := rest.ProtectedTags.ListProtectedTags(refs.project, &gitlab.ListProtectedTagsOptions{
+		PerPage: 100,
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("list protected tags: %w", err)
+	}
+	for _, t := range tags {
+		refs.tags = append(refs.tags, wildcardPattern(t.Name))
+	}
+	return nil
+}
+
+// wildcardPattern compiles a GitLab protected ref name, in which "*"
+// matches any string, into an anchored regexp.
+func wildcardPattern(name string) *regexp.Regexp {
+	return regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(name), `\*`, ".*") + "$")
+}
@@ -118,15 +118,11 @@ func (c *JobsCollector) Run(ctx context.Context) error {
 	copy(projects, c.projects)
 	c.mu.RUnlock()
 
-	for _, project := range projects {
-		if err := c.collectProject(ctx, project); err != nil {
-			c.logger.WithFields(logrus.Fields{
-				"project": project,
-				"error":   err,
-			}).Error("failed to collect jobs")
-		}
-	}
-	return nil
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects))
+	w.projects(projects, "failed to collect jobs", func(project string) error {
+		return c.collectProject(ctx, project)
+	})
+	return w.interrupted
 }
 
 // collectProject fetches pipelines for a project then iterates their jobs.
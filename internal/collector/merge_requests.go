@@ -192,7 +192,6 @@ func (c *MergeRequestsCollector) Collect(ch chan<- prometheus.Metric) {
 // Run performs one collection cycle.
 func (c *MergeRequestsCollector) Run(ctx context.Context) error {
 	start := time.Now()
-	var errCount float64
 
 	c.mu.RLock()
 	projects := make([]string, len(c.projects))
@@ -202,11 +201,8 @@ func (c *MergeRequestsCollector) Run(ctx context.Context) error {
 	obs := mergeRequestObservations{}
 	rest := c.client.REST()
 
-	for _, project := range projects {
-		if err := ctx.Err(); err != nil {
-			return err
-		}
-
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects))
+	w.eachProject(projects, func(project string) {
 		// Fetch recently updated MRs (all states).
 		opts := &gitlab.ListProjectMergeRequestsOptions{
 			State:   gitlab.Ptr("all"),
@@ -218,11 +214,10 @@ func (c *MergeRequestsCollector) Run(ctx context.Context) error {
 			},
 		}
 
-		mrs, _, err := rest.MergeRequests.ListProjectMergeRequests(project, opts)
+		mrs, _, err := rest.MergeRequests.ListProjectMergeRequests(project, opts, gitlab.WithContext(ctx))
 		if err != nil {
-			c.logger.WithError(err).WithField("project", project).Error("failed to list merge requests")
-			errCount++
-			continue
+			w.fail(err, "project", project, "failed to list merge requests")
+			return
 		}
 
 		throughputByBranch := make(map[string]float64)
@@ -342,10 +337,10 @@ func (c *MergeRequestsCollector) Run(ctx context.Context) error {
 				value:  count,
 			})
 		}
-	}
+	})
 
 	obs.scrapeDuration = time.Since(start).Seconds()
-	obs.scrapeErrors = errCount
+	obs.scrapeErrors = w.errors
 
 	c.mu.Lock()
 	c.observations = obs
@@ -353,11 +348,11 @@ func (c *MergeRequestsCollector) Run(ctx context.Context) error {
 
 	c.logger.WithFields(logrus.Fields{
 		"duration": obs.scrapeDuration,
-		"errors":   errCount,
+		"errors":   w.errors,
 		"projects": len(projects),
 	}).Debug("merge_requests collection completed")
 
-	return nil
+	return w.interrupted
 }
 
 // emitHistograms emits constant histogram metrics for a set of observations.
@@ -0,0 +1,442 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+)
+
+// mergeTrainSlack bounds how long before the window a merged car may have
+// joined the train and still be found. Completed cars are listed newest
+// first by creation time, so the listing stops at cars created this long
+// before the window starts.
+const mergeTrainSlack = 24 * time.Hour
+
+var defaultMergeTrainBuckets = []float64{300, 600, 900, 1800, 3600, 7200, 14400, 28800}
+
+// MergeTrainsCollector reports the merge trains of the tracked projects
+// (Premium tier) per target branch: the number of cars, the time merged
+// cars spent in the train, the cars dropped from it and the hourly
+// throughput.
+type MergeTrainsCollector struct {
+	client   *gitlabclient.Client
+	config   config.MergeTrainsCollectorConfig
+	projects []string
+	mu       sync.RWMutex
+	logger   *logrus.Entry
+
+	// cars holds the active cars of the previous run by car ID. GitLab
+	// deletes cars dropped from a train, so a car that left the train
+	// without merging is only noticed by its absence. Guarded by mu.
+	cars map[int]trainCar
+	// dropped counts the dropped cars by project, target branch and
+	// reason since the exporter started. Guarded by mu.
+	dropped map[[3]string]float64
+
+	// Prometheus descriptors
+	length      *prometheus.Desc
+	timeInTrain *prometheus.Desc
+	droppedCars *prometheus.Desc
+	throughput  *prometheus.Desc
+
+	// Internal operational metrics
+	scrapeDuration *prometheus.Desc
+	scrapeErrors   *prometheus.Desc
+
+	// Collected observations (mutex-protected)
+	observations mergeTrainObservations
+}
+
+type mergeTrainObservations struct {
+	length         []labeledGauge
+	timeInTrain    []labeledValue
+	dropped        []labeledGauge
+	throughput     []labeledGauge
+	scrapeDuration float64
+	scrapeErrors   float64
+}
+
+// trainCar is an active merge train car as last seen.
+type trainCar struct {
+	id           int
+	project      string
+	targetBranch string
+	mrIID        int
+	pipelineID   int
+}
+
+// NewMergeTrainsCollector creates a new merge trains collector.
+func NewMergeTrainsCollector(client *gitlabclient.Client, cfg config.MergeTrainsCollectorConfig, projects []string) *MergeTrainsCollector {
+	branchLabels := []string{"project", "target_branch"}
+
+	return &MergeTrainsCollector{
+		client:   client,
+		config:   cfg,
+		projects: projects,
+		logger:   logrus.WithField("collector", "merge_trains"),
+		cars:     make(map[int]trainCar),
+		dropped:  make(map[[3]string]float64),
+
+		length: prometheus.NewDesc(
+			"age_merge_train_length",
+			"Number of merge requests currently in the merge train.",
+			branchLabels, nil,
+		),
+		timeInTrain: prometheus.NewDesc(
+			"age_merge_train_time_in_train_seconds",
+			"Time merge requests merged within the window spent in the merge train, in seconds.",
+			branchLabels, nil,
+		),
+		droppedCars: prometheus.NewDesc(
+			"age_merge_train_dropped_cars_total",
+			"Merge requests that left the merge train without merging, by reason (pipeline_failed or removed).",
+			[]string{"project", "target_branch", "reason"}, nil,
+		),
+		throughput: prometheus.NewDesc(
+			"age_merge_train_throughput_per_hour",
+			"Merge requests merged by the merge train per hour, averaged over the window.",
+			branchLabels, nil,
+		),
+
+		scrapeDuration: prometheus.NewDesc(
+			"age_scrape_duration_seconds",
+			"Time taken by the collector scrape.",
+			[]string{"collector_type"}, nil,
+		),
+		scrapeErrors: prometheus.NewDesc(
+			"age_scrape_errors_total",
+			"Total number of scrape errors.",
+			[]string{"collector_type"}, nil,
+		),
+	}
+}
+
+func (c *MergeTrainsCollector) Name() string  { return "merge_trains" }
+func (c *MergeTrainsCollector) Enabled() bool { return c.config.Enabled }
+
+// SetProjects updates the list of tracked projects.
+func (c *MergeTrainsCollector) SetProjects(projects []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.projects = projects
+}
+
+// Describe implements prometheus.Collector.
+func (c *MergeTrainsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.length
+	ch <- c.timeInTrain
+	ch <- c.droppedCars
+	ch <- c.throughput
+	ch <- c.scrapeDuration
+	ch <- c.scrapeErrors
+}
+
+// Collect implements prometheus.Collector.
+func (c *MergeTrainsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	obs := c.observations
+	c.mu.RUnlock()
+
+	buckets := c.config.HistogramBuckets
+	if len(buckets) == 0 {
+		buckets = defaultMergeTrainBuckets
+	}
+
+	for _, o := range obs.length {
+		ch <- prometheus.MustNewConstMetric(c.length, prometheus.GaugeValue, o.value, o.labels...)
+	}
+	emitHistograms(ch, c.timeInTrain, obs.timeInTrain, buckets)
+	for _, o := range obs.dropped {
+		ch <- prometheus.MustNewConstMetric(c.droppedCars, prometheus.CounterValue, o.value, o.labels...)
+	}
+	for _, o := range obs.throughput {
+		ch <- prometheus.MustNewConstMetric(c.throughput, prometheus.GaugeValue, o.value, o.labels...)
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.scrapeDuration, prometheus.GaugeValue, obs.scrapeDuration, "merge_trains")
+	ch <- prometheus.MustNewConstMetric(c.scrapeErrors, prometheus.CounterValue, obs.scrapeErrors, "merge_trains")
+}
+
+// Run performs one collection cycle.
+func (c *MergeTrainsCollector) Run(ctx context.Context) error {
+	start := time.Now()
+
+	// Gate on tier feature availability.
+	if features := c.client.Features(); features == nil || features.Tier < gitlabclient.TierPremium {
+		c.logger.Debug("merge trains not available on this GitLab instance, skipping")
+		c.mu.Lock()
+		clear(c.cars)
+		c.observations = mergeTrainObservations{
+			scrapeDuration: time.Since(start).Seconds(),
+		}
+		c.mu.Unlock()
+		return nil
+	}
+
+	c.mu.RLock()
+	projects := make([]string, len(c.projects))
+	copy(projects, c.projects)
+	c.mu.RUnlock()
+
+	obs := mergeTrainObservations{}
+
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects))
+	w.projects(projects, "failed to collect merge trains", func(project string) error {
+		return c.collectProject(ctx, project, &obs)
+	})
+
+	// Forget the cars of projects that are no longer tracked or readable,
+	// so they are neither kept forever nor counted as dropped later.
+	tracked := make(map[string]bool, len(projects))
+	for _, project := range projects {
+		if c.client.ProjectAllowed(c.Name(), project) {
+			tracked[project] = true
+		}
+	}
+
+	c.mu.Lock()
+	for id, car := range c.cars {
+		if !tracked[car.project] {
+			delete(c.cars, id)
+		}
+	}
+	keys := make([][3]string, 0, len(c.dropped))
+	for k := range c.dropped {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		for n := range keys[i] {
+			if keys[i][n] != keys[j][n] {
+				return keys[i][n] < keys[j][n]
+			}
+		}
+		return false
+	})
+	for _, k := range keys {
+		obs.dropped = append(obs.dropped, labeledGauge{labels: []string{k[0], k[1], k[2]}, value: c.dropped[k]})
+	}
+	obs.scrapeDuration = time.Since(start).Seconds()
+	obs.scrapeErrors = w.errors
+	c.observations = obs
+	c.mu.Unlock()
+
+	c.logger.WithFields(logrus.Fields{
+		"duration": obs.scrapeDuration,
+		"errors":   w.errors,
+		"projects": len(projects),
+	}).Debug("merge_trains collection completed")
+
+	return w.interrupted
+}
+
+// collectProject records the merge trains of one project and counts the
+// cars that left them without merging since the previous run.
+func (c *MergeTrainsCollector) collectProject(ctx context.Context, project string, obs *mergeTrainObservations) error {
+	now := time.Now()
+	windowStart := now.Add(-c.config.Window())
+
+	active, resp, err := c.listCars(ctx, project, "active", time.Time{})
+	if err != nil {
+		if isForbidden(resp) {
+			// Merge trains are disabled for the project.
+			c.logger.WithField("project", project).Debug("merge trains not accessible, skipping")
+			return nil
+		}
+		return fmt.Errorf("list active merge train cars: %w", err)
+	}
+	complete, _, err := c.listCars(ctx, project, "complete", windowStart.Add(-mergeTrainSlack))
+	if err != nil {
+		return fmt.Errorf("list completed merge train cars: %w", err)
+	}
+
+	length := make(map[string]int)
+	merged := make(map[string]int)
+	for _, car := range active {
+		length[car.TargetBranch]++
+	}
+	for _, car := range complete {
+		if car.MergedAt == nil || car.MergedAt.Before(windowStart) {
+			continue
+		}
+		merged[car.TargetBranch]++
+		obs.timeInTrain = append(obs.timeInTrain, labeledValue{
+			labels: []string{project, car.TargetBranch},
+			value:  float64(car.Duration),
+		})
+	}
+
+	// Cars of the previous run that are neither active nor completed
+	// either merged after the completed listing stopped looking or were
+	// dropped. A car whose lookup fails is kept and classified on the next
+	// run, so a transient API error is never counted as a drop.
+	c.mu.RLock()
+	gone := vanishedCars(c.cars, project, active, complete)
+	c.mu.RUnlock()
+
+	drops := make(map[[3]string]float64)
+	var unclassified []trainCar
+	var lookupErr error
+	for _, car := range gone {
+		reason, dropped, err := c.classifyVanished(ctx, project, car)
+		if err != nil {
+			unclassified = append(unclassified, car)
+			if lookupErr == nil {
+				lookupErr = err
+			}
+			continue
+		}
+		if dropped {
+			drops[[3]string{project, car.targetBranch, reason}]++
+		}
+	}
+
+	c.mu.Lock()
+	for id, car := range c.cars {
+		if car.project == project {
+			delete(c.cars, id)
+		}
+	}
+	for _, car := range active {
+		tc := trainCar{id: car.ID, project: project, targetBranch: car.TargetBranch}
+		if car.MergeRequest != nil {
+			tc.mrIID = car.MergeRequest.IID
+		}
+		if car.Pipeline != nil {
+			tc.pipelineID = car.Pipeline.ID
+		}
+		c.cars[car.ID] = tc
+	}
+	for _, car := range unclassified {
+		c.cars[car.id] = car
+	}
+	for k, n := range drops {
+		c.dropped[k] += n
+	}
+	c.mu.Unlock()
+
+	branches := make(map[string]bool)
+	for b := range length {
+		branches[b] = true
+	}
+	for b := range merged {
+		branches[b] = true
+	}
+	names := make([]string, 0, len(branches))
+	for b := range branches {
+		names = append(names, b)
+	}
+	sort.Strings(names)
+	for _, b := range names {
+		labels := []string{project, b}
+		obs.length = append(obs.length, labeledGauge{labels: labels, value: float64(length[b])})
+		obs.throughput = append(obs.throughput, labeledGauge{
+			labels: labels,
+			value:  float64(merged[b]) / c.config.Window().Hours(),
+		})
+	}
+	if lookupErr != nil {
+		return fmt.Errorf("classify vanished merge train car: %w", lookupErr)
+	}
+	return nil
+}
+
+// classifyVanished tells whether a car that left the train was dropped, and
+// why. The merge request tells a late merge from a drop, and the car's last
+// pipeline tells a failure from a removal. A merge request or pipeline that
+// no longer exists counts as removed; any other lookup error leaves the car
+// unclassified.
+func (c *MergeTrainsCollector) classifyVanished(ctx context.Context, project string, car trainCar) (string, bool, error) {
+	rest := c.client.REST()
+	var mrState, pipelineStatus string
+	if car.mrIID != 0 {
+		mr, resp, err := rest.MergeRequests.GetMergeRequest(project, car.mrIID, nil, gitlab.WithContext(ctx))
+		switch {
+		case err == nil:
+			mrState = mr.State
+		case !isNotFound(resp):
+			return "", false, fmt.Errorf("get merge request !%d: %w", car.mrIID, err)
+		}
+	}
+	if car.pipelineID != 0 && mrState != "merged" {
+		p, resp, err := rest.Pipelines.GetPipeline(project, car.pipelineID, gitlab.WithContext(ctx))
+		switch {
+		case err == nil:
+			pipelineStatus = p.Status
+		case !isNotFound(resp):
+			return "", false, fmt.Errorf("get pipeline %d: %w", car.pipelineID, err)
+		}
+	}
+	reason, dropped := dropReason(mrState, pipelineStatus)
+	return reason, dropped, nil
+}
+
+// listCars lists the merge train cars of a project in the given scope,
+// newest first. When since is set, listing stops at the first car created
+// before it.
+func (c *MergeTrainsCollector) listCars(ctx context.Context, project, scope string, since time.Time) ([]*gitlab.MergeTrain, *gitlab.Response, error) {
+	var cars []*gitlab.MergeTrain
+	opts := &gitlab.ListMergeTrainsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1},
+		Scope:       gitlab.Ptr(scope),
+		Sort:        gitlab.Ptr("desc"),
+	}
+	for opts.Page > 0 {
+		page, resp, err := c.client.REST().MergeTrains.ListProjectMergeTrains(project, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, resp, err
+		}
+		for _, car := range page {
+			if !since.IsZero() && car.CreatedAt != nil && car.CreatedAt.Before(since) {
+				return cars, resp, nil
+			}
+			cars = append(cars, car)
+		}
+		opts.Page = resp.NextPage
+	}
+	return cars, nil, nil
+}
+
+// vanishedCars returns the cars of project seen in the previous run that
+// are neither active nor among the completed cars now, sorted by ID.
+func vanishedCars(prev map[int]trainCar, project string, active, complete []*gitlab.MergeTrain) []trainCar {
+	present := make(map[int]bool, len(active)+len(complete))
+	for _, car := range active {
+		present[car.ID] = true
+	}
+	for _, car := range complete {
+		present[car.ID] = true
+	}
+
+	var gone []trainCar
+	for id, car := range prev {
+		if car.project == project && !present[id] {
+			gone = append(gone, car)
+		}
+	}
+	sort.Slice(gone, func(i, j int) bool { return gone[i].id < gone[j].id })
+	return gone
+}
+
+// dropReason classifies a vanished car from the state of its merge request
+// and the status of its last pipeline (empty when unknown). dropped is
+// false when the merge request was merged: the car left the train by
+// merging.
+func dropReason(mrState, pipelineStatus string) (reason string, dropped bool) {
+	switch {
+	case mrState == "merged":
+		return "", false
+	case pipelineStatus == "failed":
+		return "pipeline_failed", true
+	default:
+		return "removed", true
+	}
+}
@@ -0,0 +1,110 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+)
+
+func TestVanishedCars(t *testing.T) {
+	prev := map[int]trainCar{
+		1: {id: 1, project: "g/a", targetBranch: "main"},
+		2: {id: 2, project: "g/a", targetBranch: "main"},
+		3: {id: 3, project: "g/a", targetBranch: "main"},
+		4: {id: 4, project: "g/b", targetBranch: "main"},
+		5: {id: 5, project: "g/a", targetBranch: "release"},
+	}
+	active := []*gitlab.MergeTrain{{ID: 2}, {ID: 6}}
+	complete := []*gitlab.MergeTrain{{ID: 3}}
+
+	got := vanishedCars(prev, "g/a", active, complete)
+	want := []trainCar{prev[1], prev[5]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("vanishedCars = %+v, want %+v", got, want)
+	}
+
+	if got := vanishedCars(prev, "g/c", active, complete); len(got) != 0 {
+		t.Errorf("vanishedCars for an unseen project = %+v, want none", got)
+	}
+}
+
+func TestDropReason(t *testing.T) {
+	tests := []struct {
+		name           string
+		mrState        string
+		pipelineStatus string
+		wantReason     string
+		wantDropped    bool
+	}{
+		{"merged late", "merged", "success", "", false},
+		{"merged with failed pipeline", "merged", "failed", "", false},
+		{"failed pipeline", "opened", "failed", "pipeline_failed", true},
+		{"removed by user", "opened", "canceled", "removed", true},
+		{"merge request closed", "closed", "running", "removed", true},
+		{"nothing known", "", "", "removed", true},
+		{"unknown merge request with failed pipeline", "", "failed", "pipeline_failed", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, dropped := dropReason(tt.mrState, tt.pipelineStatus)
+			if reason != tt.wantReason || dropped != tt.wantDropped {
+				t.Errorf("dropReason = %q, %v; want %q, %v", reason, dropped, tt.wantReason, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestVanishedCarLookupFailureIsNotADrop(t *testing.T) {
+	mrStatus := http.StatusForbidden
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v4/projects/42/merge_trains":
+			fmt.Fprint(w, "[]")
+		case "/api/v4/projects/42/merge_requests/5":
+			w.WriteHeader(mrStatus)
+			if mrStatus == http.StatusOK {
+				fmt.Fprint(w, `{"iid":5,"state":"merged"}`)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := gitlabclient.New(srv.URL, "token", 100, 100, false, logrus.NewEntry(logrus.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewMergeTrainsCollector(client, config.MergeTrainsCollectorConfig{WindowHours: 24}, []string{"42"})
+	car := trainCar{id: 1, project: "42", targetBranch: "main", mrIID: 5}
+	c.cars[car.id] = car
+
+	err = c.collectProject(context.Background(), "42", &mergeTrainObservations{})
+	if err == nil {
+		t.Error("collectProject hid the failed merge request lookup")
+	}
+	if len(c.dropped) != 0 {
+		t.Errorf("dropped = %v after a failed lookup, want none", c.dropped)
+	}
+	if got, ok := c.cars[car.id]; !ok || got != car {
+		t.Fatalf("cars = %v, want the unclassified car kept", c.cars)
+	}
+
+	mrStatus = http.StatusOK
+	if err := c.collectProject(context.Background(), "42", &mergeTrainObservations{}); err != nil {
+		t.Fatalf("collectProject: %v", err)
+	}
+	if len(c.dropped) != 0 || len(c.cars) != 0 {
+		t.Errorf("dropped = %v, cars = %v; want a merged car forgotten without a drop", c.dropped, c.cars)
+	}
+}
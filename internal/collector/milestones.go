@@ -0,0 +1,428 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+)
+
+// iterationStateCurrent is the numeric state GitLab reports for the
+// iteration in progress.
+const iterationStateCurrent = 2
+
+// MilestonesCollector reports the delivery progress of the active
+// milestones of the tracked projects and configured groups, and of the
+// groups' current iterations on Premium instances: issue and merge request
+// counts by state, completion ratio, days remaining and overdue flags.
+type MilestonesCollector struct {
+	client   *gitlabclient.Client
+	config   config.MilestonesCollectorConfig
+	projects []string
+	mu       sync.RWMutex
+	logger   *logrus.Entry
+
+	// Prometheus descriptors
+	issues              *prometheus.Desc
+	mergeRequests       *prometheus.Desc
+	completion          *prometheus.Desc
+	daysRemaining       *prometheus.Desc
+	overdue             *prometheus.Desc
+	iterationIssues     *prometheus.Desc
+	iterationCompletion *prometheus.Desc
+	iterationRemaining  *prometheus.Desc
+	iterationOverdue    *prometheus.Desc
+
+	// Internal operational metrics
+	scrapeDuration *prometheus.Desc
+	scrapeErrors   *prometheus.Desc
+
+	// Collected observations (mutex-protected)
+	observations milestoneObservations
+}
+
+type milestoneObservations struct {
+	issues              []labeledGauge
+	mergeRequests       []labeledGauge
+	completion          []labeledGauge
+	daysRemaining       []labeledGauge
+	overdue             []labeledGauge
+	iterationIssues     []labeledGauge
+	iterationCompletion []labeledGauge
+	iterationRemaining  []labeledGauge
+	iterationOverdue    []labeledGauge
+	scrapeDuration      float64
+	scrapeErrors        float64
+}
+
+// NewMilestonesCollector creates a new milestones collector.
+func NewMilestonesCollector(client *gitlabclient.Client, cfg config.MilestonesCollectorConfig, projects []string) *MilestonesCollector {
+	milestoneLabels := []string{"namespace", "milestone"}
+	iterationLabels := []string{"group", "iteration"}
+
+	return &MilestonesCollector{
+		client:   client,
+		config:   cfg,
+		projects: projects,
+		logger:   logrus.WithField("collector", "milestones"),
+
+		issues: prometheus.NewDesc(
+			"age_milestone_issues_count",
+			"Number of issues of the active milestone by state; namespace is the project or group path.",
+			[]string{"namespace", "milestone", "state"}, nil,
+		),
+		mergeRequests: prometheus.NewDesc(
+			"age_milestone_merge_requests_count",
+			"Number of merge requests of the active milestone by state.",
+			[]string{"namespace", "milestone", "state"}, nil,
+		),
+		completion: prometheus.NewDesc(
+			"age_milestone_completion_ratio",
+			"Fraction of the milestone's issues that are closed (0 to 1).",
+			milestoneLabels, nil,
+		),
+		daysRemaining: prometheus.NewDesc(
+			"age_milestone_days_remaining",
+			"Days until the end of the milestone's due date, negative once it has passed.",
+			milestoneLabels, nil,
+		),
+		overdue: prometheus.NewDesc(
+			"age_milestone_overdue",
+			"Whether the active milestone is past its due date (1) or not (0).",
+			milestoneLabels, nil,
+		),
+		iterationIssues: prometheus.NewDesc(
+			"age_iteration_issues_count",
+			"Number of issues of the group's current iteration by state.",
+			[]string{"group", "iteration", "state"}, nil,
+		),
+		iterationCompletion: prometheus.NewDesc(
+			"age_iteration_completion_ratio",
+			"Fraction of the current iteration's issues that are closed (0 to 1).",
+			iterationLabels, nil,
+		),
+		iterationRemaining: prometheus.NewDesc(
+			"age_iteration_days_remaining",
+			"Days until the end of the current iteration's due date, negative once it has passed.",
+			iterationLabels, nil,
+		),
+		iterationOverdue: prometheus.NewDesc(
+			"age_iteration_overdue",
+			"Whether the current iteration is past its due date (1) or not (0).",
+			iterationLabels, nil,
+		),
+
+		scrapeDuration: prometheus.NewDesc(
+			"age_scrape_duration_seconds",
+			"Time taken by the collector scrape.",
+			[]string{"collector_type"}, nil,
+		),
+		scrapeErrors: prometheus.NewDesc(
+			"age_scrape_errors_total",
+			"Total number of scrape errors.",
+			[]string{"collector_type"}, nil,
+		),
+	}
+}
+
+func (c *MilestonesCollector) Name() string  { return "milestones" }
+func (c *MilestonesCollector) Enabled() bool { return c.config.Enabled }
+
+// SetProjects updates the list of tracked projects.
+func (c *MilestonesCollector) SetProjects(projects []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.projects = projects
+}
+
+// Describe implements prometheus.Collector.
+func (c *MilestonesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.issues
+	ch <- c.mergeRequests
+	ch <- c.completion
+	ch <- c.daysRemaining
+	ch <- c.overdue
+	ch <- c.iterationIssues
+	ch <- c.iterationCompletion
+	ch <- c.iterationRemaining
+	ch <- c.iterationOverdue
+	ch <- c.scrapeDuration
+	ch <- c.scrapeErrors
+}
+
+// Collect implements prometheus.Collector.
+func (c *MilestonesCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	obs := c.observations
+	c.mu.RUnlock()
+
+	gauges := []struct {
+		desc *prometheus.Desc
+		obs  []labeledGauge
+	}{
+		{c.issues, obs.issues},
+		{c.mergeRequests, obs.mergeRequests},
+		{c.completion, obs.completion},
+		{c.daysRemaining, obs.daysRemaining},
+		{c.overdue, obs.overdue},
+		{c.iterationIssues, obs.iterationIssues},
+		{c.iterationCompletion, obs.iterationCompletion},
+		{c.iterationRemaining, obs.iterationRemaining},
+		{c.iterationOverdue, obs.iterationOverdue},
+	}
+	for _, g := range gauges {
+		for _, o := range g.obs {
+			ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, o.value, o.labels...)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.scrapeDuration, prometheus.GaugeValue, obs.scrapeDuration, "milestones")
+	ch <- prometheus.MustNewConstMetric(c.scrapeErrors, prometheus.CounterValue, obs.scrapeErrors, "milestones")
+}
+
+// Run performs one collection cycle.
+func (c *MilestonesCollector) Run(ctx context.Context) error {
+	start := time.Now()
+
+	c.mu.RLock()
+	projects := make([]string, len(c.projects))
+	copy(projects, c.projects)
+	c.mu.RUnlock()
+
+	// Iterations are a Premium feature.
+	iterations := c.config.IncludeIterations
+	if features := c.client.Features(); features == nil || features.Tier < gitlabclient.TierPremium {
+		iterations = false
+	}
+
+	obs := milestoneObservations{}
+
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects)+len(c.config.Groups))
+	w.projects(projects, "failed to collect milestones", func(project string) error {
+		return c.collectProject(ctx, project, &obs)
+	})
+	w.groups(c.config.Groups, "failed to collect group milestones", func(group string) error {
+		err := c.collectGroup(ctx, group, &obs)
+		if iterations {
+			if err := c.collectIterations(ctx, group, &obs); err != nil {
+				w.fail(err, "group", group, "failed to collect iterations")
+			}
+		}
+		return err
+	})
+
+	obs.scrapeDuration = time.Since(start).Seconds()
+	obs.scrapeErrors = w.errors
+
+	c.mu.Lock()
+	c.observations = obs
+	c.mu.Unlock()
+
+	c.logger.WithFields(logrus.Fields{
+		"duration": obs.scrapeDuration,
+		"errors":   w.errors,
+		"projects": len(projects),
+		"groups":   len(c.config.Groups),
+	}).Debug("milestones collection completed")
+
+	return w.interrupted
+}
+
+// collectProject records the active milestones of one project.
+func (c *MilestonesCollector) collectProject(ctx context.Context, project string, obs *milestoneObservations) error {
+	rest := c.client.REST()
+
+	opts := &gitlab.ListMilestonesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1},
+		State:       gitlab.Ptr("active"),
+	}
+	for opts.Page > 0 {
+		milestones, resp, err := rest.Milestones.ListMilestones(project, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("list milestones: %w", err)
+		}
+		for _, m := range milestones {
+			issues := func(state string) (int, error) {
+				_, resp, err := rest.Issues.ListProjectIssues(project, &gitlab.ListProjectIssuesOptions{
+					ListOptions: gitlab.ListOptions{PerPage: 1},
+					Milestone:   gitlab.Ptr(m.Title),
+					State:       gitlab.Ptr(state),
+				}, gitlab.WithContext(ctx))
+				return totalItems(resp), err
+			}
+			mergeRequests := func(state string) (int, error) {
+				_, resp, err := rest.MergeRequests.ListProjectMergeRequests(project, &gitlab.ListProjectMergeRequestsOptions{
+					ListOptions: gitlab.ListOptions{PerPage: 1},
+					Milestone:   gitlab.Ptr(m.Title),
+					State:       gitlab.Ptr(state),
+				}, gitlab.WithContext(ctx))
+				return totalItems(resp), err
+			}
+			if err := c.recordMilestone(project, m.Title, m.DueDate, issues, mergeRequests, obs); err != nil {
+				return fmt.Errorf("milestone %q: %w", m.Title, err)
+			}
+		}
+		opts.Page = resp.NextPage
+	}
+	return nil
+}
+
+// collectGroup records the active milestones of one group, counting the
+// issues and merge requests of the group and its subgroups.
+func (c *MilestonesCollector) collectGroup(ctx context.Context, group string, obs *milestoneObservations) error {
+	rest := c.client.REST()
+
+	opts := &gitlab.ListGroupMilestonesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1},
+		State:       gitlab.Ptr("active"),
+	}
+	for opts.Page > 0 {
+		milestones, resp, err := rest.GroupMilestones.ListGroupMilestones(group, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("list group milestones: %w", err)
+		}
+		for _, m := range milestones {
+			issues := func(state string) (int, error) {
+				_, resp, err := rest.Issues.ListGroupIssues(group, &gitlab.ListGroupIssuesOptions{
+					ListOptions: gitlab.ListOptions{PerPage: 1},
+					Milestone:   gitlab.Ptr(m.Title),
+					State:       gitlab.Ptr(state),
+				}, gitlab.WithContext(ctx))
+				return totalItems(resp), err
+			}
+			mergeRequests := func(state string) (int, error) {
+				_, resp, err := rest.MergeRequests.ListGroupMergeRequests(group, &gitlab.ListGroupMergeRequestsOptions{
+					ListOptions: gitlab.ListOptions{PerPage: 1},
+					Milestone:   gitlab.Ptr(m.Title),
+					State:       gitlab.Ptr(state),
+				}, gitlab.WithContext(ctx))
+				return totalItems(resp), err
+			}
+			if err := c.recordMilestone(group, m.Title, m.DueDate, issues, mergeRequests, obs); err != nil {
+				return fmt.Errorf("milestone %q: %w", m.Title, err)
+			}
+		}
+		opts.Page = resp.NextPage
+	}
+	return nil
+}
+
+// recordMilestone counts the issues and merge requests of a milestone by
+// state with the given counting functions and records its progress.
+func (c *MilestonesCollector) recordMilestone(namespace, title string, due *gitlab.ISOTime, issues, mergeRequests func(state string) (int, error), obs *milestoneObservations) error {
+	labels := []string{namespace, title}
+
+	counts := make(map[string]int)
+	for _, state := range []string{"opened", "closed"} {
+		n, err := issues(state)
+		if err != nil {
+			return fmt.Errorf("count %s issues: %w", state, err)
+		}
+		counts[state] = n
+		obs.issues = append(obs.issues, labeledGauge{labels: []string{namespace, title, state}, value: float64(n)})
+	}
+	for _, state := range []string{"opened", "merged", "closed"} {
+		n, err := mergeRequests(state)
+		if err != nil {
+			return fmt.Errorf("count %s merge requests: %w", state, err)
+		}
+		obs.mergeRequests = append(obs.mergeRequests, labeledGauge{labels: []string{namespace, title, state}, value: float64(n)})
+	}
+
+	if total := counts["opened"] + counts["closed"]; total > 0 {
+		obs.completion = append(obs.completion, labeledGauge{
+			labels: labels,
+			value:  float64(counts["closed"]) / float64(total),
+		})
+	}
+	if due != nil {
+		remaining := daysUntilEndOf(time.Time(*due), time.Now())
+		obs.daysRemaining = append(obs.daysRemaining, labeledGauge{labels: labels, value: remaining})
+		obs.overdue = append(obs.overdue, labeledGauge{labels: labels, value: boolGauge(remaining < 0)})
+	}
+	return nil
+}
+
+// collectIterations records the current iterations of one group.
+func (c *MilestonesCollector) collectIterations(ctx context.Context, group string, obs *milestoneObservations) error {
+	rest := c.client.REST()
+
+	iterations, _, err := rest.GroupIterations.ListGroupIterations(group, &gitlab.ListGroupIterationsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+		State:       gitlab.Ptr("current"),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("list iterations: %w", err)
+	}
+
+	for _, it := range iterations {
+		if it.State != iterationStateCurrent {
+			continue
+		}
+		labels := []string{group, iterationName(it)}
+
+		counts := make(map[string]int)
+		for _, state := range []string{"opened", "closed"} {
+			_, resp, err := rest.Issues.ListGroupIssues(group, &gitlab.ListGroupIssuesOptions{
+				ListOptions: gitlab.ListOptions{PerPage: 1},
+				IterationID: gitlab.Ptr(it.ID),
+				State:       gitlab.Ptr(state),
+			}, gitlab.WithContext(ctx))
+			if err != nil {
+				return fmt.Errorf("count %s issues of iteration %d: %w", state, it.ID, err)
+			}
+			counts[state] = totalItems(resp)
+			obs.iterationIssues = append(obs.iterationIssues, labeledGauge{
+				labels: []string{labels[0], labels[1], state},
+				value:  float64(counts[state]),
+			})
+		}
+
+		if total := counts["opened"] + counts["closed"]; total > 0 {
+			obs.iterationCompletion = append(obs.iterationCompletion, labeledGauge{
+				labels: labels,
+				value:  float64(counts["closed"]) / float64(total),
+			})
+		}
+		if it.DueDate != nil {
+			remaining := daysUntilEndOf(time.Time(*it.DueDate), time.Now())
+			obs.iterationRemaining = append(obs.iterationRemaining, labeledGauge{labels: labels, value: remaining})
+			obs.iterationOverdue = append(obs.iterationOverdue, labeledGauge{labels: labels, value: boolGauge(remaining < 0)})
+		}
+	}
+	return nil
+}
+
+// iterationName returns the iteration's title, or its date range for
+// untitled iterations created by a cadence.
+func iterationName(it *gitlab.GroupIteration) string {
+	if it.Title != "" {
+		return it.Title
+	}
+	if it.StartDate != nil && it.DueDate != nil {
+		return time.Time(*it.StartDate).Format("2006-01-02") + ".." + time.Time(*it.DueDate).Format("2006-01-02")
+	}
+	return fmt.Sprintf("#%d", it.IID)
+}
+
+// daysUntilEndOf returns the days from now until the end (UTC) of the given
+// date, negative once it has passed.
+func daysUntilEndOf(date, now time.Time) float64 {
+	end := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	return end.Sub(now).Hours() / 24
+}
+
+// totalItems returns the X-Total count of a listing response.
+func totalItems(resp *gitlab.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.TotalItems
+}
@@ -0,0 +1,56 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestDaysUntilEndOf(t *testing.T) {
+	due := time.Date(2026, 7, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want float64
+	}{
+		{"days ahead", time.Date(2026, 7, 8, 0, 0, 0, 0, time.UTC), 3},
+		{"on the due date", time.Date(2026, 7, 10, 12, 0, 0, 0, time.UTC), 0.5},
+		{"end of the due date", time.Date(2026, 7, 11, 0, 0, 0, 0, time.UTC), 0},
+		{"passed", time.Date(2026, 7, 12, 0, 0, 0, 0, time.UTC), -1},
+		{"now in another zone", time.Date(2026, 7, 11, 1, 0, 0, 0, time.FixedZone("CEST", 2*3600)), 1.0 / 24},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := daysUntilEndOf(due, tt.now); got != tt.want {
+				t.Errorf("daysUntilEndOf = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIterationName(t *testing.T) {
+	date := func(s string) *gitlab.ISOTime {
+		v, _ := time.Parse("2006-01-02", s)
+		d := gitlab.ISOTime(v)
+		return &d
+	}
+
+	tests := []struct {
+		name string
+		it   *gitlab.GroupIteration
+		want string
+	}{
+		{"titled", &gitlab.GroupIteration{IID: 4, Title: "Sprint 12", StartDate: date("2026-07-01"), DueDate: date("2026-07-14")}, "Sprint 12"},
+		{"cadence iteration", &gitlab.GroupIteration{IID: 4, StartDate: date("2026-07-01"), DueDate: date("2026-07-14")}, "2026-07-01..2026-07-14"},
+		{"no dates", &gitlab.GroupIteration{IID: 4, DueDate: date("2026-07-14")}, "#4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := iterationName(tt.it); got != tt.want {
+				t.Errorf("iterationName = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,390 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+)
+
+// PackagesCollector reports the package registry of the tracked projects:
+// package and version counts, file sizes, publish times and the pipelines
+// that published them, by package type.
+type PackagesCollector struct {
+	client   *gitlabclient.Client
+	config   config.PackagesCollectorConfig
+	projects []string
+	mu       sync.RWMutex
+	logger   *logrus.Entry
+
+	// files maps package version IDs to the size and publishing pipelines
+	// of their files. Published files rarely change, so each version is
+	// read once. Guarded by mu.
+	files map[int]packageFiles
+
+	// Prometheus descriptors
+	packages      *prometheus.Desc
+	versions      *prometheus.Desc
+	size          *prometheus.Desc
+	latestPublish *prometheus.Desc
+	pipelines     *prometheus.Desc
+
+	// Internal operational metrics
+	scrapeDuration *prometheus.Desc
+	scrapeErrors   *prometheus.Desc
+
+	// Collected observations (mutex-protected)
+	observations packageObservations
+}
+
+type packageObservations struct {
+	packages       []labeledGauge
+	versions       []labeledGauge
+	size           []labeledGauge
+	latestPublish  []labeledGauge
+	pipelines      []labeledGauge
+	scrapeDuration float64
+	scrapeErrors   float64
+}
+
+// packageFiles summarises the files of one package version.
+type packageFiles struct {
+	size      float64
+	pipelines []int
+}
+
+// packageStats accumulates the versions of one project and package type.
+type packageStats struct {
+	latest    map[string]time.Time // package name -> newest version publish time
+	versions  int
+	size      float64
+	pipelines map[int]bool
+}
+
+// NewPackagesCollector creates a new packages collector.
+func NewPackagesCollector(client *gitlabclient.Client, cfg config.PackagesCollectorConfig, projects []string) *PackagesCollector {
+	typeLabels := []string{"project", "package_type"}
+
+	return &PackagesCollector{
+		client:   client,
+		config:   cfg,
+		projects: projects,
+		logger:   logrus.WithField("collector", "packages"),
+		files:    make(map[int]packageFiles),
+
+		packages: prometheus.NewDesc(
+			"age_package_count",
+			"Number of distinct packages by package type.",
+			typeLabels, nil,
+		),
+		versions: prometheus.NewDesc(
+			"age_package_versions_count",
+			"Number of package versions by package type.",
+			typeLabels, nil,
+		),
+		size: prometheus.NewDesc(
+			"age_package_size_bytes",
+			"Total size of the package files by package type in bytes.",
+			typeLabels, nil,
+		),
+		latestPublish: prometheus.NewDesc(
+			"age_package_latest_publish_timestamp_seconds",
+			"Unix timestamp at which the newest version of the package was published.",
+			[]string{"project", "package_type", "package"}, nil,
+		),
+		pipelines: prometheus.NewDesc(
+			"age_package_publishing_pipelines_count",
+			"Number of distinct pipelines that published package files by package type.",
+			typeLabels, nil,
+		),
+
+		scrapeDuration: prometheus.NewDesc(
+			"age_scrape_duration_seconds",
+			"Time taken by the collector scrape.",
+			[]string{"collector_type"}, nil,
+		),
+		scrapeErrors: prometheus.NewDesc(
+			"age_scrape_errors_total",
+			"Total number of scrape errors.",
+			[]string{"collector_type"}, nil,
+		),
+	}
+}
+
+func (c *PackagesCollector) Name() string  { return "packages" }
+func (c *PackagesCollector) Enabled() bool { return c.config.Enabled }
+
+// SetProjects updates the list of tracked projects.
+func (c *PackagesCollector) SetProjects(projects []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.projects = projects
+}
+
+// Describe implements prometheus.Collector.
+func (c *PackagesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.packages
+	ch <- c.versions
+	ch <- c.size
+	ch <- c.latestPublish
+	ch <- c.pipelines
+	ch <- c.scrapeDuration
+	ch <- c.scrapeErrors
+}
+
+// Collect implements prometheus.Collector.
+func (c *PackagesCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	obs := c.observations
+	c.mu.RUnlock()
+
+	gauges := []struct {
+		desc *prometheus.Desc
+		obs  []labeledGauge
+	}{
+		{c.packages, obs.packages},
+		{c.versions, obs.versions},
+		{c.size, obs.size},
+		{c.latestPublish, obs.latestPublish},
+		{c.pipelines, obs.pipelines},
+	}
+	for _, g := range gauges {
+		for _, o := range g.obs {
+			ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, o.value, o.labels...)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.scrapeDuration, prometheus.GaugeValue, obs.scrapeDuration, "packages")
+	ch <- prometheus.MustNewConstMetric(c.scrapeErrors, prometheus.CounterValue, obs.scrapeErrors, "packages")
+}
+
+// Run performs one collection cycle. Packages of the configured groups are
+// listed once per group; the remaining tracked projects are listed one by
+// one.
+func (c *PackagesCollector) Run(ctx context.Context) error {
+	start := time.Now()
+
+	c.mu.RLock()
+	projects := make([]string, len(c.projects))
+	copy(projects, c.projects)
+	c.mu.RUnlock()
+
+	tracked := make(map[string]bool, len(projects))
+	var ungrouped []string
+	for _, project := range projects {
+		if !c.client.ProjectAllowed(c.Name(), project) {
+			continue
+		}
+		tracked[project] = true
+		if c.groupOf(project) == "" {
+			ungrouped = append(ungrouped, project)
+		}
+	}
+
+	stats := make(map[[2]string]*packageStats) // project, package type
+	seen := make(map[int]bool)
+
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(c.config.Groups)+len(ungrouped))
+	w.groups(c.config.Groups, "failed to collect group packages", func(group string) error {
+		return c.collectGroup(ctx, group, tracked, stats, seen)
+	})
+	w.projects(ungrouped, "failed to collect packages", func(project string) error {
+		return c.collectProject(ctx, project, stats, seen)
+	})
+
+	obs := packageObservations{}
+	keys := make([][2]string, 0, len(stats))
+	for k := range stats {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	for _, k := range keys {
+		s := stats[k]
+		labels := []string{k[0], k[1]}
+		obs.packages = append(obs.packages, labeledGauge{labels: labels, value: float64(len(s.latest))})
+		obs.versions = append(obs.versions, labeledGauge{labels: labels, value: float64(s.versions)})
+		if c.config.IncludeFiles {
+			obs.size = append(obs.size, labeledGauge{labels: labels, value: s.size})
+			obs.pipelines = append(obs.pipelines, labeledGauge{labels: labels, value: float64(len(s.pipelines))})
+		}
+		names := make([]string, 0, len(s.latest))
+		for name := range s.latest {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if t := s.latest[name]; !t.IsZero() {
+				obs.latestPublish = append(obs.latestPublish, labeledGauge{
+					labels: []string{k[0], k[1], name},
+					value:  float64(t.Unix()),
+				})
+			}
+		}
+	}
+
+	obs.scrapeDuration = time.Since(start).Seconds()
+	obs.scrapeErrors = w.errors
+
+	c.mu.Lock()
+	c.observations = obs
+	// Forget the files of deleted versions, unless the run was cut short
+	// and did not visit every project.
+	if w.interrupted == nil && w.errors == 0 {
+		for id := range c.files {
+			if !seen[id] {
+				delete(c.files, id)
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	c.logger.WithFields(logrus.Fields{
+		"duration": obs.scrapeDuration,
+		"errors":   w.errors,
+		"projects": len(tracked),
+		"groups":   len(c.config.Groups),
+	}).Debug("packages collection completed")
+
+	return w.interrupted
+}
+
+// groupOf returns the configured group containing the project, or "" when
+// the project is listed on its own.
+func (c *PackagesCollector) groupOf(project string) string {
+	for _, group := range c.config.Groups {
+		if strings.HasPrefix(project, strings.TrimSuffix(group, "/")+"/") {
+			return group
+		}
+	}
+	return ""
+}
+
+// collectGroup records the package versions of the group's tracked
+// projects with a single group-level listing.
+func (c *PackagesCollector) collectGroup(ctx context.Context, group string, tracked map[string]bool, stats map[[2]string]*packageStats, seen map[int]bool) error {
+	opts := &gitlab.ListGroupPackagesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1},
+	}
+	for opts.Page > 0 {
+		pkgs, resp, err := c.client.REST().Packages.ListGroupPackages(group, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("list group packages: %w", err)
+		}
+		for _, p := range pkgs {
+			// Projects of the group that are not tracked, or that another
+			// configured group already covers, are skipped.
+			if !tracked[p.ProjectPath] || c.groupOf(p.ProjectPath) != group {
+				continue
+			}
+			if err := c.record(ctx, p.ProjectPath, p.ProjectID, &p.Package, stats, seen); err != nil {
+				return err
+			}
+		}
+		opts.Page = resp.NextPage
+	}
+	return nil
+}
+
+// collectProject records the package versions of one project.
+func (c *PackagesCollector) collectProject(ctx context.Context, project string, stats map[[2]string]*packageStats, seen map[int]bool) error {
+	opts := &gitlab.ListProjectPackagesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1},
+	}
+	for opts.Page > 0 {
+		pkgs, resp, err := c.client.REST().Packages.ListProjectPackages(project, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("list packages: %w", err)
+		}
+		for _, p := range pkgs {
+			if err := c.record(ctx, project, project, p, stats, seen); err != nil {
+				return err
+			}
+		}
+		opts.Page = resp.NextPage
+	}
+	return nil
+}
+
+// record adds one package version to the statistics of its project and
+// package type. pid identifies the project in API calls.
+func (c *PackagesCollector) record(ctx context.Context, project string, pid interface{}, p *gitlab.Package, stats map[[2]string]*packageStats, seen map[int]bool) error {
+	key := [2]string{project, p.PackageType}
+	s, ok := stats[key]
+	if !ok {
+		s = &packageStats{latest: make(map[string]time.Time), pipelines: make(map[int]bool)}
+		stats[key] = s
+	}
+	s.versions++
+	seen[p.ID] = true
+
+	latest := s.latest[p.Name]
+	if p.CreatedAt != nil && p.CreatedAt.After(latest) {
+		latest = *p.CreatedAt
+	}
+	s.latest[p.Name] = latest
+
+	if !c.config.IncludeFiles {
+		return nil
+	}
+	files, err := c.versionFiles(ctx, pid, p.ID)
+	if err != nil {
+		return fmt.Errorf("list files of %s %s: %w", p.Name, p.Version, err)
+	}
+	s.size += files.size
+	for _, id := range files.pipelines {
+		s.pipelines[id] = true
+	}
+	return nil
+}
+
+// versionFiles returns the size and publishing pipelines of the files of a
+// package version, reading them from the API the first time.
+func (c *PackagesCollector) versionFiles(ctx context.Context, pid interface{}, id int) (packageFiles, error) {
+	c.mu.RLock()
+	files, ok := c.files[id]
+	c.mu.RUnlock()
+	if ok {
+		return files, nil
+	}
+
+	pipelines := make(map[int]bool)
+	opts := &gitlab.ListPackageFilesOptions{PerPage: 100, Page: 1}
+	for opts.Page > 0 {
+		page, resp, err := c.client.REST().Packages.ListPackageFiles(pid, id, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return packageFiles{}, err
+		}
+		for _, f := range page {
+			files.size += float64(f.Size)
+			if f.Pipeline != nil {
+				for _, pl := range *f.Pipeline {
+					pipelines[pl.ID] = true
+				}
+			}
+		}
+		opts.Page = resp.NextPage
+	}
+	for pl := range pipelines {
+		files.pipelines = append(files.pipelines, pl)
+	}
+	sort.Ints(files.pipelines)
+
+	c.mu.Lock()
+	c.files[id] = files
+	c.mu.Unlock()
+	return files, nil
+}
@@ -0,0 +1,363 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/scheduler"
+)
+
+// PipelineSchedulesCollector reports the pipeline schedules of the tracked
+// projects: whether they are active and owned by an active user, when they
+// run next, how their last pipeline went and whether they are overdue.
+type PipelineSchedulesCollector struct {
+	client   *gitlabclient.Client
+	config   config.PipelineSchedulesCollectorConfig
+	projects []string
+	mu       sync.RWMutex
+	logger   *logrus.Entry
+
+	// lastRuns maps schedule IDs to their last pipeline, so the pipeline's
+	// creation time is only read when the schedule runs again. Guarded by
+	// mu.
+	lastRuns map[int]scheduleRun
+
+	// Prometheus descriptors
+	info        *prometheus.Desc
+	active      *prometheus.Desc
+	ownerActive *prometheus.Desc
+	nextRun     *prometheus.Desc
+	lastStatus  *prometheus.Desc
+	lastAge     *prometheus.Desc
+	overdue     *prometheus.Desc
+
+	// Internal operational metrics
+	scrapeDuration *prometheus.Desc
+	scrapeErrors   *prometheus.Desc
+
+	// Collected observations (mutex-protected)
+	observations scheduleObservations
+}
+
+type scheduleObservations struct {
+	info           []labeledGauge
+	active         []labeledGauge
+	ownerActive    []labeledGauge
+	nextRun        []labeledGauge
+	lastStatus     []labeledGauge
+	lastAge        []labeledGauge
+	overdue        []labeledGauge
+	scrapeDuration float64
+	scrapeErrors   float64
+}
+
+// scheduleRun is the last pipeline a schedule created.
+type scheduleRun struct {
+	pipelineID int
+	createdAt  time.Time
+}
+
+// NewPipelineSchedulesCollector creates a new pipeline schedules collector.
+func NewPipelineSchedulesCollector(client *gitlabclient.Client, cfg config.PipelineSchedulesCollectorConfig, projects []string) *PipelineSchedulesCollector {
+	scheduleLabels := []string{"project", "schedule"}
+
+	return &PipelineSchedulesCollector{
+		client:   client,
+		config:   cfg,
+		projects: projects,
+		logger:   logrus.WithField("collector", "pipeline_schedules"),
+		lastRuns: make(map[int]scheduleRun),
+
+		info: prometheus.NewDesc(
+			"age_pipeline_schedule_info",
+			"Pipeline schedule description, ref and cron expression (always 1).",
+			[]string{"project", "schedule", "description", "ref", "cron", "cron_timezone"}, nil,
+		),
+		active: prometheus.NewDesc(
+			"age_pipeline_schedule_active",
+			"Whether the pipeline schedule is active (1) or not (0).",
+			scheduleLabels, nil,
+		),
+		ownerActive: prometheus.NewDesc(
+			"age_pipeline_schedule_owner_active",
+			"Whether the pipeline schedule's owner exists and is active (1) or is blocked or removed (0).",
+			scheduleLabels, nil,
+		),
+		nextRun: prometheus.NewDesc(
+			"age_pipeline_schedule_next_run_timestamp_seconds",
+			"Unix timestamp of the pipeline schedule's next run.",
+			scheduleLabels, nil,
+		),
+		lastStatus: prometheus.NewDesc(
+			"age_pipeline_schedule_last_pipeline_status",
+			"Status of the last pipeline created by the schedule (1 for the current status).",
+			[]string{"project", "schedule", "status"}, nil,
+		),
+		lastAge: prometheus.NewDesc(
+			"age_pipeline_schedule_last_pipeline_age_seconds",
+			"Time since the last pipeline created by the schedule, in seconds.",
+			scheduleLabels, nil,
+		),
+		overdue: prometheus.NewDesc(
+			"age_pipeline_schedule_overdue",
+			"Whether an active schedule missed two consecutive cron runs since its last pipeline (1) or not (0).",
+			scheduleLabels, nil,
+		),
+
+		scrapeDuration: prometheus.NewDesc(
+			"age_scrape_duration_seconds",
+			"Time taken by the collector scrape.",
+			[]string{"collector_type"}, nil,
+		),
+		scrapeErrors: prometheus.NewDesc(
+			"age_scrape_errors_total",
+			"Total number of scrape errors.",
+			[]string{"collector_type"}, nil,
+		),
+	}
+}
+
+func (c *PipelineSchedulesCollector) Name() string  { return "pipeline_schedules" }
+func (c *PipelineSchedulesCollector) Enabled() bool { return c.config.Enabled }
+
+// SetProjects updates the list of tracked projects.
+func (c *PipelineSchedulesCollector) SetProjects(projects []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.projects = projects
+}
+
+// Describe implements prometheus.Collector.
+func (c *PipelineSchedulesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.info
+	ch <- c.active
+	ch <- c.ownerActive
+	ch <- c.nextRun
+	ch <- c.lastStatus
+	ch <- c.lastAge
+	ch <- c.overdue
+	ch <- c.scrapeDuration
+	ch <- c.scrapeErrors
+}
+
+// Collect implements prometheus.Collector.
+func (c *PipelineSchedulesCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	obs := c.observations
+	c.mu.RUnlock()
+
+	gauges := []struct {
+		desc *prometheus.Desc
+		obs  []labeledGauge
+	}{
+		{c.info, obs.info},
+		{c.active, obs.active},
+		{c.ownerActive, obs.ownerActive},
+		{c.nextRun, obs.nextRun},
+		{c.lastStatus, obs.lastStatus},
+		{c.lastAge, obs.lastAge},
+		{c.overdue, obs.overdue},
+	}
+	for _, g := range gauges {
+		for _, o := range g.obs {
+			ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, o.value, o.labels...)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.scrapeDuration, prometheus.GaugeValue, obs.scrapeDuration, "pipeline_schedules")
+	ch <- prometheus.MustNewConstMetric(c.scrapeErrors, prometheus.CounterValue, obs.scrapeErrors, "pipeline_schedules")
+}
+
+// Run performs one collection cycle.
+func (c *PipelineSchedulesCollector) Run(ctx context.Context) error {
+	start := time.Now()
+
+	c.mu.RLock()
+	projects := make([]string, len(c.projects))
+	copy(projects, c.projects)
+	c.mu.RUnlock()
+
+	obs := scheduleObservations{}
+	seen := make(map[int]bool)
+
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects))
+	w.projects(projects, "failed to collect pipeline schedules", func(project string) error {
+		return c.collectProject(ctx, project, &obs, seen)
+	})
+
+	obs.scrapeDuration = time.Since(start).Seconds()
+	obs.scrapeErrors = w.errors
+
+	c.mu.Lock()
+	c.observations = obs
+	// Forget deleted schedules, unless the run was cut short and did not
+	// visit every project.
+	if w.interrupted == nil {
+		for id := range c.lastRuns {
+			if !seen[id] {
+				delete(c.lastRuns, id)
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	c.logger.WithFields(logrus.Fields{
+		"duration": obs.scrapeDuration,
+		"errors":   w.errors,
+		"projects": len(projects),
+	}).Debug("pipeline_schedules collection completed")
+
+	return w.interrupted
+}
+
+// collectProject records the pipeline schedules of one project. A schedule
+// whose last pipeline cannot be read is still recorded, judged overdue by
+// what is known without it, and the first such failure is returned.
+func (c *PipelineSchedulesCollector) collectProject(ctx context.Context, project string, obs *scheduleObservations, seen map[int]bool) error {
+	rest := c.client.REST()
+	now := time.Now()
+
+	var schedules []*gitlab.PipelineSchedule
+	opts := &gitlab.ListPipelineSchedulesOptions{PerPage: 100, Page: 1}
+	for opts.Page > 0 {
+		page, resp, err := rest.PipelineSchedules.ListPipelineSchedules(project, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("list pipeline schedules: %w", err)
+		}
+		schedules = append(schedules, page...)
+		opts.Page = resp.NextPage
+	}
+
+	var lastRunErr error
+	for _, s := range schedules {
+		seen[s.ID] = true
+		id := strconv.Itoa(s.ID)
+		labels := []string{project, id}
+
+		obs.info = append(obs.info, labeledGauge{
+			labels: []string{project, id, s.Description, s.Ref, s.Cron, s.CronTimezone},
+			value:  1,
+		})
+		obs.active = append(obs.active, labeledGauge{labels: labels, value: boolGauge(s.Active)})
+		ownerActive := s.Owner != nil && s.Owner.State == "active"
+		obs.ownerActive = append(obs.ownerActive, labeledGauge{labels: labels, value: boolGauge(ownerActive)})
+		if s.NextRunAt != nil {
+			obs.nextRun = append(obs.nextRun, labeledGauge{labels: labels, value: float64(s.NextRunAt.Unix())})
+		}
+
+		// The listing does not include the last pipeline.
+		run, status, err := c.lastRun(ctx, project, s.ID)
+		since := run.createdAt
+		if err != nil {
+			if lastRunErr == nil {
+				lastRunErr = fmt.Errorf("read last pipeline of schedule %d: %w", s.ID, err)
+			}
+			since = c.knownLastRun(s)
+		} else {
+			if status != "" {
+				obs.lastStatus = append(obs.lastStatus, labeledGauge{labels: []string{project, id, status}, value: 1})
+			}
+			if !run.createdAt.IsZero() {
+				obs.lastAge = append(obs.lastAge, labeledGauge{labels: labels, value: now.Sub(run.createdAt).Seconds()})
+			}
+		}
+
+		overdue := false
+		if s.Active {
+			if since.IsZero() && s.CreatedAt != nil {
+				since = *s.CreatedAt
+			}
+			overdue = scheduleOverdue(s.Cron, s.CronTimezone, since, now)
+		}
+		obs.overdue = append(obs.overdue, labeledGauge{labels: labels, value: boolGauge(overdue)})
+	}
+	return lastRunErr
+}
+
+// knownLastRun estimates when a schedule last ran without reading its last
+// pipeline: the cached run, or else just before its next run, which GitLab
+// moves on to the following cron tick whenever the schedule fires. It is
+// zero when neither is known.
+func (c *PipelineSchedulesCollector) knownLastRun(s *gitlab.PipelineSchedule) time.Time {
+	c.mu.RLock()
+	run, ok := c.lastRuns[s.ID]
+	c.mu.RUnlock()
+	if ok && !run.createdAt.IsZero() {
+		return run.createdAt
+	}
+	if s.NextRunAt != nil {
+		return s.NextRunAt.Add(-time.Second)
+	}
+	return time.Time{}
+}
+
+// lastRun returns the last pipeline of a schedule and its current status.
+// The pipeline's creation time is cached per schedule and only read again
+// when the schedule has created a new pipeline.
+func (c *PipelineSchedulesCollector) lastRun(ctx context.Context, project string, scheduleID int) (scheduleRun, string, error) {
+	rest := c.client.REST()
+
+	s, _, err := rest.PipelineSchedules.GetPipelineSchedule(project, scheduleID, gitlab.WithContext(ctx))
+	if err != nil {
+		return scheduleRun{}, "", err
+	}
+	if s.LastPipeline == nil {
+		return scheduleRun{}, "", nil
+	}
+
+	c.mu.RLock()
+	run, ok := c.lastRuns[scheduleID]
+	c.mu.RUnlock()
+	if ok && run.pipelineID == s.LastPipeline.ID {
+		return run, s.LastPipeline.Status, nil
+	}
+
+	p, _, err := rest.Pipelines.GetPipeline(project, s.LastPipeline.ID, gitlab.WithContext(ctx))
+	if err != nil {
+		return scheduleRun{}, s.LastPipeline.Status, err
+	}
+	run = scheduleRun{pipelineID: p.ID}
+	if p.CreatedAt != nil {
+		run.createdAt = *p.CreatedAt
+	}
+
+	c.mu.Lock()
+	c.lastRuns[scheduleID] = run
+	c.mu.Unlock()
+	return run, s.LastPipeline.Status, nil
+}
+
+// scheduleOverdue reports whether two runs of the cron expression fell
+// between since and now. The expression is evaluated on the wall clock of
+// the schedule's time zone (UTC when it is unknown). An unparsable
+// expression is never overdue.
+func scheduleOverdue(expr, timezone string, since, now time.Time) bool {
+	if since.IsZero() {
+		return false
+	}
+	cron, err := scheduler.ParseCron(expr)
+	if err != nil {
+		return false
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	// Cron evaluates in UTC, so shift both instants to the schedule's wall
+	// clock expressed as UTC.
+	wall := func(t time.Time) time.Time {
+		t = t.In(loc)
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	}
+	second := cron.Next(cron.Next(wall(since)))
+	return !second.IsZero() && second.Before(wall(now))
+}
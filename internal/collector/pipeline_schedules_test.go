@@ -0,0 +1,104 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata" // Europe/Berlin without relying on the host's zoneinfo
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+)
+
+func TestScheduleOverdue(t *testing.T) {
+	at := func(t *testing.T, s string) time.Time {
+		t.Helper()
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name     string
+		expr     string
+		timezone string
+		since    string
+		now      string
+		want     bool
+	}{
+		{"one run missed", "0 * * * *", "UTC", "2026-07-01T10:00:00Z", "2026-07-01T11:30:00Z", false},
+		{"two runs missed", "0 * * * *", "UTC", "2026-07-01T10:00:00Z", "2026-07-01T12:30:00Z", true},
+		{"second run just due", "0 * * * *", "UTC", "2026-07-01T10:00:00Z", "2026-07-01T12:00:00Z", false},
+		{"never ran", "0 * * * *", "UTC", "", "2026-07-01T12:30:00Z", false},
+		{"unparsable expression", "not a cron", "UTC", "2026-07-01T10:00:00Z", "2026-07-09T10:00:00Z", false},
+		{"schedule time zone", "0 9 * * *", "Europe/Berlin", "2026-07-01T07:00:00Z", "2026-07-02T09:30:00Z", false},
+		{"schedule time zone overdue", "0 9 * * *", "Europe/Berlin", "2026-07-01T07:00:00Z", "2026-07-03T07:30:00Z", true},
+		{"unknown time zone falls back to UTC", "0 9 * * *", "Nowhere/Land", "2026-07-01T07:00:00Z", "2026-07-02T09:30:00Z", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var since time.Time
+			if tt.since != "" {
+				since = at(t, tt.since)
+			}
+			if got := scheduleOverdue(tt.expr, tt.timezone, since, at(t, tt.now)); got != tt.want {
+				t.Errorf("scheduleOverdue = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleLastRunFailure(t *testing.T) {
+	nextRun := time.Now().Add(-3 * time.Hour).UTC().Truncate(time.Hour)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v4/projects/42/pipeline_schedules":
+			fmt.Fprintf(w, `[
+				{"id":1,"cron":"0 * * * *","cron_timezone":"UTC","active":true,"next_run_at":%q},
+				{"id":2,"cron":"0 * * * *","cron_timezone":"UTC","active":true,"next_run_at":%q}
+			]`, nextRun.Format(time.RFC3339), time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+		case "/api/v4/projects/42/pipeline_schedules/1":
+			w.WriteHeader(http.StatusForbidden)
+		case "/api/v4/projects/42/pipeline_schedules/2":
+			fmt.Fprint(w, `{"id":2,"last_pipeline":{"id":7,"status":"success"}}`)
+		case "/api/v4/projects/42/pipelines/7":
+			fmt.Fprintf(w, `{"id":7,"created_at":%q}`, time.Now().Add(-30*time.Minute).UTC().Format(time.RFC3339))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := gitlabclient.New(srv.URL, "token", 100, 100, false, logrus.NewEntry(logrus.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewPipelineSchedulesCollector(client, config.PipelineSchedulesCollectorConfig{}, []string{"42"})
+
+	obs := scheduleObservations{}
+	if err := c.collectProject(context.Background(), "42", &obs, make(map[int]bool)); err == nil {
+		t.Error("collectProject hid the failed last pipeline lookup")
+	}
+
+	// Schedule 1 missed the runs at and after its next_run_at.
+	wantOverdue := []labeledGauge{
+		{labels: []string{"42", "1"}, value: 1},
+		{labels: []string{"42", "2"}, value: 0},
+	}
+	if !reflect.DeepEqual(obs.overdue, wantOverdue) {
+		t.Errorf("overdue = %+v, want %+v", obs.overdue, wantOverdue)
+	}
+	wantStatus := []labeledGauge{{labels: []string{"42", "2", "success"}, value: 1}}
+	if !reflect.DeepEqual(obs.lastStatus, wantStatus) || len(obs.lastAge) != 1 {
+		t.Errorf("lastStatus = %+v, lastAge = %+v; want only schedule 2", obs.lastStatus, obs.lastAge)
+	}
+}
@@ -175,16 +175,11 @@ func (c *PipelinesCollector) Run(ctx context.Context) error {
 	copy(projects, c.projects)
 	c.mu.RUnlock()
 
-	for _, project := range projects {
-		if err := c.collectProject(ctx, project); err != nil {
-			c.logger.WithFields(logrus.Fields{
-				"project": project,
-				"error":   err,
-			}).Error("failed to collect pipelines")
-			// Continue with remaining projects rather than aborting.
-		}
-	}
-	return nil
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects))
+	w.projects(projects, "failed to collect pipelines", func(project string) error {
+		return c.collectProject(ctx, project)
+	})
+	return w.interrupted
 }
 
 // collectProject fetches pipeline data for a single project.
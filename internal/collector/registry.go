@@ -56,6 +56,21 @@ func (r *Registry) Register(c Collector) {
 	}).Info("registered collector")
 }
 
+// Unregister removes the collector with the given name from the registry.
+// It reports whether a collector was removed.
+func (r *Registry) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, c := range r.collectors {
+		if c.Name() == name {
+			r.collectors = append(r.collectors[:i], r.collectors[i+1:]...)
+			r.logger.WithField("collector", name).Info("unregistered collector")
+			return true
+		}
+	}
+	return false
+}
+
 // Collectors returns a snapshot of all registered collectors.
 func (r *Registry) Collectors() []Collector {
 	r.mu.RLock()
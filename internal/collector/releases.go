@@ -0,0 +1,415 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+)
+
+// maxReleasePages bounds the pages of releases read per project when the
+// tag filter sets no most_recent or max_age_days limit.
+const maxReleasePages = 10
+
+var defaultReleaseBuckets = []float64{86400, 259200, 604800, 1209600, 2592000, 5184000, 7776000, 15552000}
+
+// mergeRequestRef matches the reference GitLab adds to merge commit messages.
+var mergeRequestRef = regexp.MustCompile(`See merge request (\S*![0-9]+)`)
+
+// ReleasesCollector reports the release cadence of the tracked projects:
+// release counts, time since the last release, the interval between
+// releases, upcoming releases and, optionally, the commits and merge
+// requests each release shipped compared with the previous one and their
+// lead time from commit to release.
+type ReleasesCollector struct {
+	client   *gitlabclient.Client
+	config   config.ReleasesCollectorConfig
+	projects []string
+	mu       sync.RWMutex
+	logger   *logrus.Entry
+
+	// tags is the tag filter of defaults and tagOverrides those of
+	// projects that set their own.
+	tags         config.TagsConfig
+	tagOverrides map[string]config.TagsConfig
+
+	// changes caches the compare results between two release tags, which
+	// do not change once both releases exist. Guarded by mu.
+	changes map[releasePair]releaseChanges
+
+	// Prometheus descriptors
+	count           *prometheus.Desc
+	sinceLast       *prometheus.Desc
+	interval        *prometheus.Desc
+	commits         *prometheus.Desc
+	mergeRequests   *prometheus.Desc
+	upcomingRelease *prometheus.Desc
+	leadTimeMean    *prometheus.Desc
+	leadTimeMax     *prometheus.Desc
+
+	// Internal operational metrics
+	scrapeDuration *prometheus.Desc
+	scrapeErrors   *prometheus.Desc
+
+	// Collected observations (mutex-protected)
+	observations releaseObservations
+}
+
+type releaseObservations struct {
+	count           []labeledGauge
+	sinceLast       []labeledGauge
+	interval        []labeledValue
+	commits         []labeledGauge
+	mergeRequests   []labeledGauge
+	upcomingRelease []labeledGauge
+	leadTimeMean    []labeledGauge
+	leadTimeMax     []labeledGauge
+	scrapeDuration  float64
+	scrapeErrors    float64
+}
+
+// releasePair identifies the comparison of a release with its predecessor.
+type releasePair struct {
+	project, from, to string
+}
+
+// releaseChanges is what a release shipped since the previous one.
+// commitTimeSum is the sum of the commit times (Unix seconds) of the dated
+// commits and earliest the oldest of them.
+type releaseChanges struct {
+	commits       int
+	mergeRequests int
+	dated         int
+	commitTimeSum float64
+	earliest      time.Time
+}
+
+// NewReleasesCollector creates a new releases collector. tags is the tag
+// filter of defaults and tagOverrides the per-project ones.
+func NewReleasesCollector(client *gitlabclient.Client, cfg config.ReleasesCollectorConfig, tags config.TagsConfig, tagOverrides map[string]config.TagsConfig, projects []string) *ReleasesCollector {
+	projectLabels := []string{"project"}
+	releaseLabels := []string{"project", "tag"}
+
+	return &ReleasesCollector{
+		client:       client,
+		config:       cfg,
+		projects:     projects,
+		logger:       logrus.WithField("collector", "releases"),
+		tags:         tags,
+		tagOverrides: tagOverrides,
+		changes:      make(map[releasePair]releaseChanges),
+
+		count: prometheus.NewDesc(
+			"age_release_count",
+			"Number of releases within the refs.tags filter (most_recent, max_age_days).",
+			projectLabels, nil,
+		),
+		sinceLast: prometheus.NewDesc(
+			"age_release_time_since_last_seconds",
+			"Seconds since the most recent release.",
+			projectLabels, nil,
+		),
+		interval: prometheus.NewDesc(
+			"age_release_interval_seconds",
+			"Time between consecutive releases in seconds.",
+			projectLabels, nil,
+		),
+		commits: prometheus.NewDesc(
+			"age_release_commits_count",
+			"Number of commits in the release that were not in the previous release.",
+			releaseLabels, nil,
+		),
+		mergeRequests: prometheus.NewDesc(
+			"age_release_merge_requests_count",
+			"Number of merge requests whose merge commit is in the release but not in the previous release.",
+			releaseLabels, nil,
+		),
+		upcomingRelease: prometheus.NewDesc(
+			"age_release_upcoming_timestamp_seconds",
+			"Planned release time (Unix seconds) of releases whose released_at is in the future.",
+			releaseLabels, nil,
+		),
+		leadTimeMean: prometheus.NewDesc(
+			"age_release_lead_time_mean_seconds",
+			"Mean time from commit to release of the commits in the release that were not in the previous release.",
+			releaseLabels, nil,
+		),
+		leadTimeMax: prometheus.NewDesc(
+			"age_release_lead_time_max_seconds",
+			"Time from commit to release of the oldest commit in the release that was not in the previous release.",
+			releaseLabels, nil,
+		),
+
+		scrapeDuration: prometheus.NewDesc(
+			"age_scrape_duration_seconds",
+			"Time taken by the collector scrape.",
+			[]string{"collector_type"}, nil,
+		),
+		scrapeErrors: prometheus.NewDesc(
+			"age_scrape_errors_total",
+			"Total number of scrape errors.",
+			[]string{"collector_type"}, nil,
+		),
+	}
+}
+
+func (c *ReleasesCollector) Name() string  { return "releases" }
+func (c *ReleasesCollector) Enabled() bool { return c.config.Enabled }
+
+// SetProjects updates the list of tracked projects.
+func (c *ReleasesCollector) SetProjects(projects []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.projects = projects
+}
+
+// Describe implements prometheus.Collector.
+func (c *ReleasesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.count
+	ch <- c.sinceLast
+	ch <- c.interval
+	ch <- c.commits
+	ch <- c.mergeRequests
+	ch <- c.upcomingRelease
+	ch <- c.leadTimeMean
+	ch <- c.leadTimeMax
+	ch <- c.scrapeDuration
+	ch <- c.scrapeErrors
+}
+
+// Collect implements prometheus.Collector.
+func (c *ReleasesCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	obs := c.observations
+	c.mu.RUnlock()
+
+	buckets := c.config.HistogramBuckets
+	if len(buckets) == 0 {
+		buckets = defaultReleaseBuckets
+	}
+
+	gauges := []struct {
+		desc *prometheus.Desc
+		obs  []labeledGauge
+	}{
+		{c.count, obs.count},
+		{c.sinceLast, obs.sinceLast},
+		{c.commits, obs.commits},
+		{c.mergeRequests, obs.mergeRequests},
+		{c.upcomingRelease, obs.upcomingRelease},
+		{c.leadTimeMean, obs.leadTimeMean},
+		{c.leadTimeMax, obs.leadTimeMax},
+	}
+	for _, g := range gauges {
+		for _, o := range g.obs {
+			ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, o.value, o.labels...)
+		}
+	}
+	emitHistograms(ch, c.interval, obs.interval, buckets)
+
+	ch <- prometheus.MustNewConstMetric(c.scrapeDuration, prometheus.GaugeValue, obs.scrapeDuration, "releases")
+	ch <- prometheus.MustNewConstMetric(c.scrapeErrors, prometheus.CounterValue, obs.scrapeErrors, "releases")
+}
+
+// Run performs one collection cycle.
+func (c *ReleasesCollector) Run(ctx context.Context) error {
+	start := time.Now()
+
+	c.mu.RLock()
+	projects := make([]string, len(c.projects))
+	copy(projects, c.projects)
+	c.mu.RUnlock()
+
+	obs := releaseObservations{}
+	used := make(map[releasePair]bool)
+
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects))
+	w.projects(projects, "failed to collect releases", func(project string) error {
+		return c.collectProject(ctx, project, &obs, used)
+	})
+
+	obs.scrapeDuration = time.Since(start).Seconds()
+	obs.scrapeErrors = w.errors
+
+	c.mu.Lock()
+	c.observations = obs
+	if w.interrupted == nil {
+		for pair := range c.changes {
+			if !used[pair] {
+				delete(c.changes, pair)
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	c.logger.WithFields(logrus.Fields{
+		"duration": obs.scrapeDuration,
+		"errors":   w.errors,
+		"projects": len(projects),
+	}).Debug("releases collection completed")
+
+	return w.interrupted
+}
+
+// collectProject records the releases of one project.
+func (c *ReleasesCollector) collectProject(ctx context.Context, project string, obs *releaseObservations, used map[releasePair]bool) error {
+	tags := c.tags
+	if t, ok := c.tagOverrides[project]; ok {
+		tags = t
+	}
+	var pattern *regexp.Regexp
+	if tags.Enabled && tags.Regexp != "" {
+		var err error
+		if pattern, err = regexp.Compile(tags.Regexp); err != nil {
+			return fmt.Errorf("refs.tags.regexp: %w", err)
+		}
+	}
+	var cutoff time.Time
+	if tags.Enabled && tags.MaxAgeDays > 0 {
+		cutoff = time.Now().AddDate(0, 0, -tags.MaxAgeDays)
+	}
+	mostRecent := 0
+	if tags.Enabled {
+		mostRecent = tags.MostRecent
+	}
+
+	// Releases are listed newest first. released holds the releases in the
+	// window followed by the one before it, which the oldest is compared
+	// with.
+	now := time.Now()
+	var released []*gitlab.Release
+	opts := &gitlab.ListReleasesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1},
+		OrderBy:     gitlab.Ptr("released_at"),
+		Sort:        gitlab.Ptr("desc"),
+	}
+	complete := false
+	for pages := 0; opts.Page > 0 && !complete; pages++ {
+		if mostRecent == 0 && cutoff.IsZero() && pages >= maxReleasePages {
+			break
+		}
+		releases, resp, err := c.client.REST().Releases.ListReleases(project, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("list releases: %w", err)
+		}
+		for _, r := range releases {
+			if r.ReleasedAt == nil || (pattern != nil && !pattern.MatchString(r.TagName)) {
+				continue
+			}
+			if r.UpcomingRelease || r.ReleasedAt.After(now) {
+				obs.upcomingRelease = append(obs.upcomingRelease, labeledGauge{
+					labels: []string{project, r.TagName},
+					value:  float64(r.ReleasedAt.Unix()),
+				})
+				continue
+			}
+			released = append(released, r)
+			if (mostRecent > 0 && len(released) > mostRecent) ||
+				(!cutoff.IsZero() && r.ReleasedAt.Before(cutoff)) {
+				complete = true
+				break
+			}
+		}
+		opts.Page = resp.NextPage
+	}
+
+	inWindow := len(released)
+	if complete {
+		inWindow--
+	}
+	obs.count = append(obs.count, labeledGauge{labels: []string{project}, value: float64(inWindow)})
+	if len(released) > 0 {
+		obs.sinceLast = append(obs.sinceLast, labeledGauge{
+			labels: []string{project},
+			value:  now.Sub(*released[0].ReleasedAt).Seconds(),
+		})
+	}
+
+	for i := 0; i < inWindow && i+1 < len(released); i++ {
+		r, prev := released[i], released[i+1]
+		obs.interval = append(obs.interval, labeledValue{
+			labels: []string{project},
+			value:  r.ReleasedAt.Sub(*prev.ReleasedAt).Seconds(),
+		})
+
+		if !c.config.IncludeChanges {
+			continue
+		}
+		pair := releasePair{project: project, from: prev.TagName, to: r.TagName}
+		used[pair] = true
+		changes, err := c.releaseChanges(ctx, pair)
+		if err != nil {
+			c.logger.WithError(err).WithFields(logrus.Fields{
+				"project": project,
+				"tag":     r.TagName,
+			}).Warn("failed to compare release with the previous one")
+			continue
+		}
+		labels := []string{project, r.TagName}
+		obs.commits = append(obs.commits, labeledGauge{labels: labels, value: float64(changes.commits)})
+		obs.mergeRequests = append(obs.mergeRequests, labeledGauge{labels: labels, value: float64(changes.mergeRequests)})
+		if changes.dated > 0 {
+			releasedAt := float64(r.ReleasedAt.Unix())
+			obs.leadTimeMean = append(obs.leadTimeMean, labeledGauge{
+				labels: labels,
+				value:  max(releasedAt-changes.commitTimeSum/float64(changes.dated), 0),
+			})
+			obs.leadTimeMax = append(obs.leadTimeMax, labeledGauge{
+				labels: labels,
+				value:  max(r.ReleasedAt.Sub(changes.earliest).Seconds(), 0),
+			})
+		}
+	}
+	return nil
+}
+
+// releaseChanges compares two release tags, counting the commits between
+// them and the merge requests whose merge commits are among those, and
+// summing the commit times for the lead time. Merge requests merged by
+// fast-forward leave no merge commit and are not counted.
+func (c *ReleasesCollector) releaseChanges(ctx context.Context, pair releasePair) (releaseChanges, error) {
+	c.mu.RLock()
+	changes, ok := c.changes[pair]
+	c.mu.RUnlock()
+	if ok {
+		return changes, nil
+	}
+
+	cmp, _, err := c.client.REST().Repositories.Compare(pair.project, &gitlab.CompareOptions{
+		From: gitlab.Ptr(pair.from),
+		To:   gitlab.Ptr(pair.to),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return releaseChanges{}, fmt.Errorf("compare %s...%s: %w", pair.from, pair.to, err)
+	}
+
+	mrs := make(map[string]bool)
+	changes = releaseChanges{commits: len(cmp.Commits)}
+	for _, commit := range cmp.Commits {
+		for _, m := range mergeRequestRef.FindAllStringSubmatch(commit.Message, -1) {
+			mrs[m[1]] = true
+		}
+		if commit.CommittedDate == nil {
+			continue
+		}
+		changes.dated++
+		changes.commitTimeSum += float64(commit.CommittedDate.Unix())
+		if changes.earliest.IsZero() || commit.CommittedDate.Before(changes.earliest) {
+			changes.earliest = *commit.CommittedDate
+		}
+	}
+	changes.mergeRequests = len(mrs)
+
+	c.mu.Lock()
+	c.changes[pair] = changes
+	c.mu.Unlock()
+	return changes, nil
+}
@@ -0,0 +1,70 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+)
+
+func TestReleaseLeadTime(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v4/projects/42/releases":
+			fmt.Fprint(w, `[
+				{"tag_name":"v2","released_at":"2024-01-10T00:00:00Z"},
+				{"tag_name":"v1","released_at":"2024-01-01T00:00:00Z"}
+			]`)
+		case "/api/v4/projects/42/repository/compare":
+			if r.URL.Query().Get("from") != "v1" || r.URL.Query().Get("to") != "v2" {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, `{"commits":[
+				{"id":"b","message":"Merge branch 'x'\n\nSee merge request g/p!7","committed_date":"2024-01-09T00:00:00Z"},
+				{"id":"a","message":"fix","committed_date":"2024-01-07T00:00:00Z"},
+				{"id":"c","message":"undated"}
+			]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := gitlabclient.New(srv.URL, "token", 100, 100, false, logrus.NewEntry(logrus.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewReleasesCollector(client, config.ReleasesCollectorConfig{IncludeChanges: true}, config.TagsConfig{}, nil, []string{"42"})
+
+	obs := releaseObservations{}
+	if err := c.collectProject(context.Background(), "42", &obs, make(map[releasePair]bool)); err != nil {
+		t.Fatalf("collectProject: %v", err)
+	}
+
+	const day = 86400
+	labels := []string{"42", "v2"}
+	checks := []struct {
+		name string
+		got  []labeledGauge
+		want []labeledGauge
+	}{
+		{"commits", obs.commits, []labeledGauge{{labels: labels, value: 3}}},
+		{"merge requests", obs.mergeRequests, []labeledGauge{{labels: labels, value: 1}}},
+		{"mean lead time", obs.leadTimeMean, []labeledGauge{{labels: labels, value: 2 * day}}},
+		{"max lead time", obs.leadTimeMax, []labeledGauge{{labels: labels, value: 3 * day}}},
+	}
+	for _, ch := range checks {
+		if !reflect.DeepEqual(ch.got, ch.want) {
+			t.Errorf("%s = %+v, want %+v", ch.name, ch.got, ch.want)
+		}
+	}
+}
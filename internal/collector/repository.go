@@ -134,7 +134,6 @@ func (c *RepositoryCollector) Collect(ch chan<- prometheus.Metric) {
 // Run performs one collection cycle.
 func (c *RepositoryCollector) Run(ctx context.Context) error {
 	start := time.Now()
-	var errCount float64
 
 	c.mu.RLock()
 	projects := make([]string, len(c.projects))
@@ -144,16 +143,12 @@ func (c *RepositoryCollector) Run(ctx context.Context) error {
 	obs := repositoryObservations{}
 	rest := c.client.REST()
 
-	for _, project := range projects {
-		if err := ctx.Err(); err != nil {
-			return err
-		}
-
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects))
+	w.eachProject(projects, func(project string) {
 		// --- Languages ---
-		languages, _, err := rest.Projects.GetProjectLanguages(project)
+		languages, _, err := rest.Projects.GetProjectLanguages(project, gitlab.WithContext(ctx))
 		if err != nil {
-			c.logger.WithError(err).WithField("project", project).Error("failed to get repository languages")
-			errCount++
+			w.fail(err, "project", project, "failed to get repository languages")
 		} else if languages != nil {
 			for lang, pct := range *languages {
 				obs.languagePercentage = append(obs.languagePercentage, labeledGauge{
@@ -166,10 +161,9 @@ func (c *RepositoryCollector) Run(ctx context.Context) error {
 		// --- Project statistics (size, commit count) ---
 		projDetail, _, err := rest.Projects.GetProject(project, &gitlab.GetProjectOptions{
 			Statistics: gitlab.Ptr(true),
-		})
+		}, gitlab.WithContext(ctx))
 		if err != nil {
-			c.logger.WithError(err).WithField("project", project).Error("failed to get project statistics")
-			errCount++
+			w.fail(err, "project", project, "failed to get project statistics")
 		} else if projDetail != nil {
 			if projDetail.Statistics != nil {
 				obs.sizeBytes = append(obs.sizeBytes, labeledGauge{
@@ -192,12 +186,12 @@ func (c *RepositoryCollector) Run(ctx context.Context) error {
 				PerPage: 1,
 				Page:    1,
 			},
-		})
+		}, gitlab.WithContext(ctx))
 		if err != nil {
 			c.logger.WithError(err).WithField("project", project).Warn("failed to get latest pipeline for coverage")
 		} else if len(pipelines) > 0 {
 			// PipelineInfo doesn't have Coverage — fetch the full Pipeline.
-			if fullPipeline, _, err2 := rest.Pipelines.GetPipeline(project, pipelines[0].ID); err2 == nil && fullPipeline.Coverage != "" {
+			if fullPipeline, _, err2 := rest.Pipelines.GetPipeline(project, pipelines[0].ID, gitlab.WithContext(ctx)); err2 == nil && fullPipeline.Coverage != "" {
 				if cov, parseErr := strconv.ParseFloat(fullPipeline.Coverage, 64); parseErr == nil {
 					obs.coverage = append(obs.coverage, labeledGauge{
 						labels: []string{project},
@@ -206,10 +200,10 @@ func (c *RepositoryCollector) Run(ctx context.Context) error {
 				}
 			}
 		}
-	}
+	})
 
 	obs.scrapeDuration = time.Since(start).Seconds()
-	obs.scrapeErrors = errCount
+	obs.scrapeErrors = w.errors
 
 	c.mu.Lock()
 	c.observations = obs
@@ -217,9 +211,9 @@ func (c *RepositoryCollector) Run(ctx context.Context) error {
 
 	c.logger.WithFields(logrus.Fields{
 		"duration": obs.scrapeDuration,
-		"errors":   errCount,
+		"errors":   w.errors,
 		"projects": len(projects),
 	}).Debug("repository collection completed")
 
-	return nil
+	return w.interrupted
 }
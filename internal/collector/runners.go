@@ -0,0 +1,513 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+)
+
+// untaggedGroup is the tag group of runners without tags.
+const untaggedGroup = "untagged"
+
+// RunnersCollector reports the health and load of the CI runners visible
+// to the token: instance runners, runners of the configured groups and
+// runners of the tracked projects. Runners are also aggregated by their
+// tag set, which is what jobs are matched against.
+type RunnersCollector struct {
+	client   *gitlabclient.Client
+	config   config.RunnersCollectorConfig
+	projects []string
+	mu       sync.RWMutex
+	logger   *logrus.Entry
+
+	// adminDenied is set once /runners/all answered 403, so later cycles
+	// go straight to the runners visible to the token; denied holds the
+	// groups whose runners could not be listed. Both are guarded by mu.
+	adminDenied bool
+	denied      map[string]bool
+
+	// Prometheus descriptors
+	status         *prometheus.Desc
+	online         *prometheus.Desc
+	paused         *prometheus.Desc
+	contactedAge   *prometheus.Desc
+	info           *prometheus.Desc
+	runningJobs    *prometheus.Desc
+	maxConcurrency *prometheus.Desc
+
+	groupRunners        *prometheus.Desc
+	groupRunningJobs    *prometheus.Desc
+	groupMaxConcurrency *prometheus.Desc
+
+	// Internal operational metrics
+	scrapeDuration *prometheus.Desc
+	scrapeErrors   *prometheus.Desc
+
+	// Collected observations (mutex-protected)
+	observations runnersObservations
+}
+
+type runnersObservations struct {
+	status         []labeledGauge
+	online         []labeledGauge
+	paused         []labeledGauge
+	contactedAge   []labeledGauge
+	info           []labeledGauge
+	runningJobs    []labeledGauge
+	maxConcurrency []labeledGauge
+
+	groupRunners        []labeledGauge
+	groupRunningJobs    []labeledGauge
+	groupMaxConcurrency []labeledGauge
+
+	scrapeDuration float64
+	scrapeErrors   float64
+}
+
+// NewRunnersCollector creates a new runners collector.
+func NewRunnersCollector(client *gitlabclient.Client, cfg config.RunnersCollectorConfig, projects []string) *RunnersCollector {
+	runnerLabels := []string{"runner_id", "runner", "runner_type"}
+
+	return &RunnersCollector{
+		client:   client,
+		config:   cfg,
+		projects: projects,
+		logger:   logrus.WithField("collector", "runners"),
+		denied:   make(map[string]bool),
+
+		status: prometheus.NewDesc(
+			"age_runner_status",
+			"Runner status reported by GitLab (1 for the current status: online, offline, stale or never_contacted).",
+			append(runnerLabels, "status"), nil,
+		),
+		online: prometheus.NewDesc(
+			"age_runner_online",
+			"Whether the runner contacted GitLab recently (1) or not (0).",
+			runnerLabels, nil,
+		),
+		paused: prometheus.NewDesc(
+			"age_runner_paused",
+			"Whether the runner is paused (1) or accepts jobs (0).",
+			runnerLabels, nil,
+		),
+		contactedAge: prometheus.NewDesc(
+			"age_runner_contacted_age_seconds",
+			"Seconds since the runner last contacted GitLab.",
+			runnerLabels, nil,
+		),
+		info: prometheus.NewDesc(
+			"age_runner_info",
+			"Informational metric about the runner (always 1).",
+			append(runnerLabels, "version", "executor", "platform", "architecture", "tags"), nil,
+		),
+		runningJobs: prometheus.NewDesc(
+			"age_runner_running_jobs",
+			"Number of jobs the runner is currently running.",
+			runnerLabels, nil,
+		),
+		maxConcurrency: prometheus.NewDesc(
+			"age_runner_max_concurrency",
+			"Maximum number of concurrent jobs of the runner, as configured in collectors.runners.max_concurrency.",
+			runnerLabels, nil,
+		),
+
+		groupRunners: prometheus.NewDesc(
+			"age_runner_tag_group_runners",
+			"Number of runners with the tag set, by status.",
+			[]string{"tags", "status"}, nil,
+		),
+		groupRunningJobs: prometheus.NewDesc(
+			"age_runner_tag_group_running_jobs",
+			"Number of jobs running on runners with the tag set.",
+			[]string{"tags"}, nil,
+		),
+		groupMaxConcurrency: prometheus.NewDesc(
+			"age_runner_tag_group_max_concurrency",
+			"Configured maximum concurrency summed over the runners with the tag set.",
+			[]string{"tags"}, nil,
+		),
+
+		scrapeDuration: prometheus.NewDesc(
+			"age_scrape_duration_seconds",
+			"Time taken by the collector scrape.",
+			[]string{"collector_type"}, nil,
+		),
+		scrapeErrors: prometheus.NewDesc(
+			"age_scrape_errors_total",
+			"Total number of scrape errors.",
+			[]string{"collector_type"}, nil,
+		),
+	}
+}
+
+func (c *RunnersCollector) Name() string  { return "runners" }
+func (c *RunnersCollector) Enabled() bool { return c.config.Enabled }
+
+// SetProjects updates the list of tracked projects.
+func (c *RunnersCollector) SetProjects(projects []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.projects = projects
+}
+
+// Describe implements prometheus.Collector.
+func (c *RunnersCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.status
+	ch <- c.online
+	ch <- c.paused
+	ch <- c.contactedAge
+	ch <- c.info
+	ch <- c.runningJobs
+	ch <- c.maxConcurrency
+	ch <- c.groupRunners
+	ch <- c.groupRunningJobs
+	ch <- c.groupMaxConcurrency
+	ch <- c.scrapeDuration
+	ch <- c.scrapeErrors
+}
+
+// Collect implements prometheus.Collector.
+func (c *RunnersCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	obs := c.observations
+	c.mu.RUnlock()
+
+	gauges := []struct {
+		desc *prometheus.Desc
+		obs  []labeledGauge
+	}{
+		{c.status, obs.status},
+		{c.online, obs.online},
+		{c.paused, obs.paused},
+		{c.contactedAge, obs.contactedAge},
+		{c.info, obs.info},
+		{c.runningJobs, obs.runningJobs},
+		{c.maxConcurrency, obs.maxConcurrency},
+		{c.groupRunners, obs.groupRunners},
+		{c.groupRunningJobs, obs.groupRunningJobs},
+		{c.groupMaxConcurrency, obs.groupMaxConcurrency},
+	}
+	for _, g := range gauges {
+		for _, o := range g.obs {
+			ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, o.value, o.labels...)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.scrapeDuration, prometheus.GaugeValue, obs.scrapeDuration, "runners")
+	ch <- prometheus.MustNewConstMetric(c.scrapeErrors, prometheus.CounterValue, obs.scrapeErrors, "runners")
+}
+
+// Run performs one collection cycle.
+func (c *RunnersCollector) Run(ctx context.Context) error {
+	start := time.Now()
+	var errCount float64
+
+	c.mu.RLock()
+	projects := make([]string, len(c.projects))
+	copy(projects, c.projects)
+	c.mu.RUnlock()
+
+	// Runners are listed per scope and de-duplicated, since a runner can
+	// be visible through several of them.
+	seen := make(map[int]bool)
+	var runners []*gitlab.Runner
+	add := func(list []*gitlab.Runner) {
+		for _, r := range list {
+			if !seen[r.ID] {
+				seen[r.ID] = true
+				runners = append(runners, r)
+			}
+		}
+	}
+
+	if c.config.InstanceRunners {
+		list, err := c.listInstanceRunners(ctx)
+		if err != nil {
+			c.logger.WithError(err).Error("failed to list instance runners")
+			errCount++
+		}
+		add(list)
+	}
+
+	for _, group := range c.config.Groups {
+		list, err := c.listScope(ctx, "group "+group, func(page int) ([]*gitlab.Runner, *gitlab.Response, error) {
+			return c.client.REST().Runners.ListGroupsRunners(group, &gitlab.ListGroupsRunnersOptions{
+				ListOptions: gitlab.ListOptions{PerPage: 100, Page: page},
+			}, gitlab.WithContext(ctx))
+		})
+		if err != nil {
+			c.logger.WithError(err).WithField("group", group).Error("failed to list group runners")
+			errCount++
+		}
+		add(list)
+	}
+
+	if c.config.ProjectRunners {
+		for _, project := range projects {
+			if ctx.Err() != nil {
+				break
+			}
+			if !c.client.ProjectAllowed(c.Name(), project) {
+				continue
+			}
+			list, err := c.listScope(ctx, "project "+project, func(page int) ([]*gitlab.Runner, *gitlab.Response, error) {
+				return c.client.REST().Runners.ListProjectRunners(project, &gitlab.ListProjectRunnersOptions{
+					ListOptions: gitlab.ListOptions{PerPage: 100, Page: page},
+				}, gitlab.WithContext(ctx))
+			})
+			if err != nil {
+				c.logger.WithError(err).WithField("project", project).Error("failed to list project runners")
+				errCount++
+			}
+			add(list)
+		}
+	}
+
+	obs := runnersObservations{}
+	groups := make(map[string]*runnerTagGroup)
+
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(runners))
+	for _, r := range runners {
+		if !w.wait() {
+			break
+		}
+		if err := c.collectRunner(ctx, r, &obs, groups); err != nil {
+			w.fail(err, "runner_id", r.ID, "failed to collect runner")
+		}
+	}
+	errCount += w.errors
+	// The project listing above stops early without going through the walk.
+	interrupted := w.interrupted
+	if interrupted == nil {
+		interrupted = ctx.Err()
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g := groups[name]
+		for status, n := range g.runners {
+			obs.groupRunners = append(obs.groupRunners, labeledGauge{labels: []string{name, status}, value: float64(n)})
+		}
+		obs.groupRunningJobs = append(obs.groupRunningJobs, labeledGauge{labels: []string{name}, value: float64(g.runningJobs)})
+		if g.maxConcurrency > 0 {
+			obs.groupMaxConcurrency = append(obs.groupMaxConcurrency, labeledGauge{labels: []string{name}, value: float64(g.maxConcurrency)})
+		}
+	}
+
+	obs.scrapeDuration = time.Since(start).Seconds()
+	obs.scrapeErrors = errCount
+
+	c.mu.Lock()
+	c.observations = obs
+	c.mu.Unlock()
+
+	c.logger.WithFields(logrus.Fields{
+		"duration": obs.scrapeDuration,
+		"errors":   errCount,
+		"runners":  len(runners),
+	}).Debug("runners collection completed")
+
+	return interrupted
+}
+
+// runnerTagGroup aggregates the runners sharing one tag set.
+type runnerTagGroup struct {
+	runners        map[string]int // status -> count
+	runningJobs    int
+	maxConcurrency int
+}
+
+// collectRunner fetches the details and running jobs of one runner and
+// records its observations.
+func (c *RunnersCollector) collectRunner(ctx context.Context, r *gitlab.Runner, obs *runnersObservations, groups map[string]*runnerTagGroup) error {
+	rest := c.client.REST()
+
+	details, _, err := rest.Runners.GetRunnerDetails(r.ID, gitlab.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("get runner %d: %w", r.ID, err)
+	}
+
+	name := details.Description
+	if name == "" {
+		name = details.Name
+	}
+	if name == "" {
+		name = "runner-" + strconv.Itoa(details.ID)
+	}
+	labels := []string{strconv.Itoa(details.ID), name, details.RunnerType}
+
+	status := details.Status
+	if status == "" {
+		status = "unknown"
+	}
+	obs.status = append(obs.status, labeledGauge{labels: append(labels[:3:3], status), value: 1})
+	obs.online = append(obs.online, labeledGauge{labels: labels, value: boolGauge(details.Online)})
+	obs.paused = append(obs.paused, labeledGauge{labels: labels, value: boolGauge(details.Paused)})
+	if details.ContactedAt != nil {
+		obs.contactedAge = append(obs.contactedAge, labeledGauge{
+			labels: labels,
+			value:  time.Since(*details.ContactedAt).Seconds(),
+		})
+	}
+
+	tagGroup := tagGroupName(details.TagList)
+
+	executor := "unknown"
+	if c.client.GraphQLEnabled() {
+		if e, err := c.client.FetchRunnerExecutor(ctx, details.ID); err != nil {
+			c.logger.WithError(err).WithField("runner_id", details.ID).Debug("failed to fetch runner executor")
+		} else if e != "" {
+			executor = e
+		}
+	}
+	obs.info = append(obs.info, labeledGauge{
+		labels: append(labels[:3:3], details.Version, executor, details.Platform, details.Architecture, tagGroup),
+		value:  1,
+	})
+
+	g := groups[tagGroup]
+	if g == nil {
+		g = &runnerTagGroup{runners: make(map[string]int)}
+		groups[tagGroup] = g
+	}
+	g.runners[status]++
+
+	jobs, resp, err := rest.Runners.ListRunnerJobs(details.ID, &gitlab.ListRunnerJobsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+		Status:      gitlab.Ptr("running"),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		c.logger.WithError(err).WithField("runner_id", details.ID).Debug("failed to list running jobs of runner")
+	} else {
+		running := max(resp.TotalItems, len(jobs))
+		obs.runningJobs = append(obs.runningJobs, labeledGauge{labels: labels, value: float64(running)})
+		g.runningJobs += running
+	}
+
+	if limit, ok := c.config.MaxConcurrency[name]; ok {
+		obs.maxConcurrency = append(obs.maxConcurrency, labeledGauge{labels: labels, value: float64(limit)})
+		g.maxConcurrency += limit
+	}
+	return nil
+}
+
+// listInstanceRunners lists every runner of the instance through the admin
+// endpoint. Without administrator access it falls back to the runners
+// visible to the token, which include the instance runners it may use.
+func (c *RunnersCollector) listInstanceRunners(ctx context.Context) ([]*gitlab.Runner, error) {
+	rest := c.client.REST()
+
+	c.mu.RLock()
+	useAdmin := !c.adminDenied
+	c.mu.RUnlock()
+	if admin, known := c.client.Permissions().Admin(); known && !admin {
+		useAdmin = false
+	}
+
+	if useAdmin {
+		var denied bool
+		list, err := c.listPages(func(page int) ([]*gitlab.Runner, *gitlab.Response, error) {
+			runners, resp, err := rest.Runners.ListAllRunners(&gitlab.ListRunnersOptions{
+				ListOptions: gitlab.ListOptions{PerPage: 100, Page: page},
+			}, gitlab.WithContext(ctx))
+			denied = isForbidden(resp)
+			return runners, resp, err
+		})
+		if !denied {
+			return list, err
+		}
+		c.mu.Lock()
+		c.adminDenied = true
+		c.mu.Unlock()
+		c.logger.Info("admin runner endpoint forbidden, listing the runners visible to the token instead")
+	}
+
+	return c.listPages(func(page int) ([]*gitlab.Runner, *gitlab.Response, error) {
+		return rest.Runners.ListRunners(&gitlab.ListRunnersOptions{
+			ListOptions: gitlab.ListOptions{PerPage: 100, Page: page},
+		}, gitlab.WithContext(ctx))
+	})
+}
+
+// listScope lists the runners of a group or project. A scope the token
+// may not list runners of is logged once and skipped on every cycle.
+func (c *RunnersCollector) listScope(ctx context.Context, scope string, fetch func(page int) ([]*gitlab.Runner, *gitlab.Response, error)) ([]*gitlab.Runner, error) {
+	c.mu.RLock()
+	skip := c.denied[scope]
+	c.mu.RUnlock()
+	if skip {
+		return nil, nil
+	}
+
+	var denied bool
+	list, err := c.listPages(func(page int) ([]*gitlab.Runner, *gitlab.Response, error) {
+		runners, resp, err := fetch(page)
+		denied = isForbidden(resp)
+		return runners, resp, err
+	})
+	if denied {
+		c.mu.Lock()
+		c.denied[scope] = true
+		c.mu.Unlock()
+		c.logger.WithField("scope", scope).Warn("listing runners forbidden, skipping scope")
+		return nil, nil
+	}
+	return list, err
+}
+
+// listPages collects every page of a runner listing.
+func (c *RunnersCollector) listPages(fetch func(page int) ([]*gitlab.Runner, *gitlab.Response, error)) ([]*gitlab.Runner, error) {
+	var all []*gitlab.Runner
+	for page := 1; page > 0; {
+		runners, resp, err := fetch(page)
+		if err != nil {
+			return all, err
+		}
+		all = append(all, runners...)
+		page = resp.NextPage
+	}
+	return all, nil
+}
+
+// tagGroupName returns the sorted, comma-separated tag set used to match
+// jobs to runners, or "untagged".
+func tagGroupName(tags []string) string {
+	if len(tags) == 0 {
+		return untaggedGroup
+	}
+	sorted := append([]string(nil), tags...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// isForbidden reports whether a response is a 401 or 403.
+func isForbidden(resp *gitlab.Response) bool {
+	return resp != nil && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized)
+}
+
+func isNotFound(resp *gitlab.Response) bool {
+	return resp != nil && resp.StatusCode == http.StatusNotFound
+}
+
+func boolGauge(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
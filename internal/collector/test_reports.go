@@ -162,15 +162,11 @@ func (c *TestReportsCollector) Run(ctx context.Context) error {
 	copy(projects, c.projects)
 	c.mu.RUnlock()
 
-	for _, project := range projects {
-		if err := c.collectProject(ctx, project); err != nil {
-			c.logger.WithFields(logrus.Fields{
-				"project": project,
-				"error":   err,
-			}).Error("failed to collect test reports")
-		}
-	}
-	return nil
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects))
+	w.projects(projects, "failed to collect test reports", func(project string) error {
+		return c.collectProject(ctx, project)
+	})
+	return w.interrupted
 }
 
 // collectProject fetches recent pipelines and their test reports for a single project.
@@ -9,6 +9,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
 
 	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
 	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
@@ -144,7 +145,6 @@ func (c *ValueStreamCollector) Collect(ch chan<- prometheus.Metric) {
 // Run performs one collection cycle.
 func (c *ValueStreamCollector) Run(ctx context.Context) error {
 	start := time.Now()
-	var errCount float64
 
 	// Gate on tier feature availability.
 	if features := c.client.Features(); features == nil || !features.HasValueStream {
@@ -165,18 +165,14 @@ func (c *ValueStreamCollector) Run(ctx context.Context) error {
 	obs := valueStreamObservations{}
 	rest := c.client.REST()
 
-	for _, project := range projects {
-		if err := ctx.Err(); err != nil {
-			return err
-		}
-
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects))
+	w.eachProject(projects, func(project string) {
 		// Step 1: List value streams for the project.
 		vsPath := fmt.Sprintf("projects/%s/analytics/value_stream_analytics/value_streams", project)
-		req, err := rest.NewRequest(http.MethodGet, vsPath, nil, nil)
+		req, err := rest.NewRequest(http.MethodGet, vsPath, nil, []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
 		if err != nil {
-			c.logger.WithError(err).WithField("project", project).Error("failed to create value streams request")
-			errCount++
-			continue
+			w.fail(err, "project", project, "failed to create value streams request")
+			return
 		}
 
 		var valueStreams []vsaValueStreamResponse
@@ -184,15 +180,14 @@ func (c *ValueStreamCollector) Run(ctx context.Context) error {
 		if err != nil {
 			if resp != nil && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound) {
 				c.logger.WithField("project", project).Debug("value stream analytics not accessible for project")
-				continue
+				return
 			}
-			c.logger.WithError(err).WithField("project", project).Error("failed to list value streams")
-			errCount++
-			continue
+			w.fail(err, "project", project, "failed to list value streams")
+			return
 		}
 
 		if len(valueStreams) == 0 {
-			continue
+			return
 		}
 
 		// Use the first (default) value stream.
@@ -200,19 +195,17 @@ func (c *ValueStreamCollector) Run(ctx context.Context) error {
 
 		// Step 2: List stages for this value stream.
 		stagesPath := fmt.Sprintf("projects/%s/analytics/value_stream_analytics/value_streams/%d/stages", project, vsID)
-		req, err = rest.NewRequest(http.MethodGet, stagesPath, nil, nil)
+		req, err = rest.NewRequest(http.MethodGet, stagesPath, nil, []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
 		if err != nil {
-			c.logger.WithError(err).WithField("project", project).Error("failed to create stages request")
-			errCount++
-			continue
+			w.fail(err, "project", project, "failed to create stages request")
+			return
 		}
 
 		var stages []vsaStageResponse
 		_, err = rest.Do(req, &stages)
 		if err != nil {
-			c.logger.WithError(err).WithField("project", project).Error("failed to list VSA stages")
-			errCount++
-			continue
+			w.fail(err, "project", project, "failed to list VSA stages")
+			return
 		}
 
 		var totalCycleTime float64
@@ -228,13 +221,13 @@ func (c *ValueStreamCollector) Run(ctx context.Context) error {
 				"projects/%s/analytics/value_stream_analytics/value_streams/%d/stages/%d/median",
 				project, vsID, stage.ID,
 			)
-			req, err = rest.NewRequest(http.MethodGet, medianPath, nil, nil)
+			req, err = rest.NewRequest(http.MethodGet, medianPath, nil, []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
 			if err != nil {
 				c.logger.WithError(err).WithFields(logrus.Fields{
 					"project": project,
 					"stage":   stageName,
 				}).Error("failed to create stage median request")
-				errCount++
+				w.errors++
 				continue
 			}
 
@@ -266,10 +259,10 @@ func (c *ValueStreamCollector) Run(ctx context.Context) error {
 			labels: []string{project},
 			value:  totalCycleTime,
 		})
-	}
+	})
 
 	obs.scrapeDuration = time.Since(start).Seconds()
-	obs.scrapeErrors = errCount
+	obs.scrapeErrors = w.errors
 
 	c.mu.Lock()
 	c.observations = obs
@@ -277,9 +270,9 @@ func (c *ValueStreamCollector) Run(ctx context.Context) error {
 
 	c.logger.WithFields(logrus.Fields{
 		"duration": obs.scrapeDuration,
-		"errors":   errCount,
+		"errors":   w.errors,
 		"projects": len(projects),
 	}).Debug("value_stream collection completed")
 
-	return nil
+	return w.interrupted
 }
@@ -0,0 +1,320 @@
+package collector
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+)
+
+// VulnerabilitiesCollector reports the vulnerability report of the tracked
+// projects (Ultimate tier): counts of open findings by severity, scanner
+// and state, the age of the oldest open critical finding and the mean time
+// to resolve, plus severity counts of the configured groups.
+type VulnerabilitiesCollector struct {
+	client   *gitlabclient.Client
+	config   config.VulnerabilitiesCollectorConfig
+	projects []string
+	mu       sync.RWMutex
+	logger   *logrus.Entry
+
+	graphQLOnce sync.Once
+
+	// Prometheus descriptors
+	count         *prometheus.Desc
+	oldestCrit    *prometheus.Desc
+	timeToResolve *prometheus.Desc
+	groupCount    *prometheus.Desc
+
+	// Internal operational metrics
+	scrapeDuration *prometheus.Desc
+	scrapeErrors   *prometheus.Desc
+
+	// Collected observations (mutex-protected)
+	observations vulnerabilityObservations
+}
+
+type vulnerabilityObservations struct {
+	count          []labeledGauge
+	oldestCrit     []labeledGauge
+	timeToResolve  []labeledGauge
+	groupCount     []labeledGauge
+	scrapeDuration float64
+	scrapeErrors   float64
+}
+
+// NewVulnerabilitiesCollector creates a new vulnerabilities collector.
+func NewVulnerabilitiesCollector(client *gitlabclient.Client, cfg config.VulnerabilitiesCollectorConfig, projects []string) *VulnerabilitiesCollector {
+	return &VulnerabilitiesCollector{
+		client:   client,
+		config:   cfg,
+		projects: projects,
+		logger:   logrus.WithField("collector", "vulnerabilities"),
+
+		count: prometheus.NewDesc(
+			"age_vulnerability_count",
+			"Number of detected or confirmed vulnerabilities by severity, scanner report type and state.",
+			[]string{"project", "severity", "report_type", "state"}, nil,
+		),
+		oldestCrit: prometheus.NewDesc(
+			"age_vulnerability_oldest_critical_age_seconds",
+			"Age of the oldest detected or confirmed critical vulnerability in seconds.",
+			[]string{"project"}, nil,
+		),
+		timeToResolve: prometheus.NewDesc(
+			"age_vulnerability_mean_time_to_resolve_seconds",
+			"Mean time from detection to resolution of vulnerabilities resolved within the window, in seconds.",
+			[]string{"project", "severity"}, nil,
+		),
+		groupCount: prometheus.NewDesc(
+			"age_vulnerability_group_count",
+			"Number of vulnerabilities of the group and its subgroups by severity and state.",
+			[]string{"group", "severity", "state"}, nil,
+		),
+
+		scrapeDuration: prometheus.NewDesc(
+			"age_scrape_duration_seconds",
+			"Time taken by the collector scrape.",
+			[]string{"collector_type"}, nil,
+		),
+		scrapeErrors: prometheus.NewDesc(
+			"age_scrape_errors_total",
+			"Total number of scrape errors.",
+			[]string{"collector_type"}, nil,
+		),
+	}
+}
+
+func (c *VulnerabilitiesCollector) Name() string  { return "vulnerabilities" }
+func (c *VulnerabilitiesCollector) Enabled() bool { return c.config.Enabled }
+
+// SetProjects updates the list of tracked projects.
+func (c *VulnerabilitiesCollector) SetProjects(projects []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.projects = projects
+}
+
+// Describe implements prometheus.Collector.
+func (c *VulnerabilitiesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.count
+	ch <- c.oldestCrit
+	ch <- c.timeToResolve
+	ch <- c.groupCount
+	ch <- c.scrapeDuration
+	ch <- c.scrapeErrors
+}
+
+// Collect implements prometheus.Collector.
+func (c *VulnerabilitiesCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	obs := c.observations
+	c.mu.RUnlock()
+
+	gauges := []struct {
+		desc *prometheus.Desc
+		obs  []labeledGauge
+	}{
+		{c.count, obs.count},
+		{c.oldestCrit, obs.oldestCrit},
+		{c.timeToResolve, obs.timeToResolve},
+		{c.groupCount, obs.groupCount},
+	}
+	for _, g := range gauges {
+		for _, o := range g.obs {
+			ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, o.value, o.labels...)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.scrapeDuration, prometheus.GaugeValue, obs.scrapeDuration, "vulnerabilities")
+	ch <- prometheus.MustNewConstMetric(c.scrapeErrors, prometheus.CounterValue, obs.scrapeErrors, "vulnerabilities")
+}
+
+// Run performs one collection cycle.
+func (c *VulnerabilitiesCollector) Run(ctx context.Context) error {
+	start := time.Now()
+
+	// Gate on tier feature availability.
+	if features := c.client.Features(); features == nil || !features.HasVulnerabilities {
+		c.logger.Debug("vulnerability report not available on this GitLab instance, skipping")
+		c.mu.Lock()
+		c.observations = vulnerabilityObservations{
+			scrapeDuration: time.Since(start).Seconds(),
+		}
+		c.mu.Unlock()
+		return nil
+	}
+	// The vulnerability report has no REST listing.
+	if !c.client.GraphQLEnabled() {
+		c.graphQLOnce.Do(func() {
+			c.logger.Warn("vulnerabilities collector requires gitlab.use_graphql, skipping")
+		})
+		return nil
+	}
+
+	c.mu.RLock()
+	projects := make([]string, len(c.projects))
+	copy(projects, c.projects)
+	c.mu.RUnlock()
+
+	obs := vulnerabilityObservations{}
+
+	w := newWalk(ctx, c.client, c.Name(), c.logger, len(projects)+len(c.config.Groups))
+	w.projects(projects, "failed to collect vulnerabilities", func(project string) error {
+		return c.collectProject(ctx, project, &obs)
+	})
+	w.groups(c.config.Groups, "failed to collect group vulnerability counts", func(group string) error {
+		counts, err := c.client.FetchGroupVulnerabilityCounts(ctx, group)
+		if err != nil {
+			return err
+		}
+		for _, state := range []string{"detected", "confirmed", "dismissed", "resolved"} {
+			s := counts[state]
+			for _, sev := range []struct {
+				name  string
+				count int
+			}{
+				{"critical", s.Critical},
+				{"high", s.High},
+				{"medium", s.Medium},
+				{"low", s.Low},
+				{"info", s.Info},
+				{"unknown", s.Unknown},
+			} {
+				obs.groupCount = append(obs.groupCount, labeledGauge{
+					labels: []string{group, sev.name, state},
+					value:  float64(sev.count),
+				})
+			}
+		}
+		return nil
+	})
+
+	obs.scrapeDuration = time.Since(start).Seconds()
+	obs.scrapeErrors = w.errors
+
+	c.mu.Lock()
+	c.observations = obs
+	c.mu.Unlock()
+
+	c.logger.WithFields(logrus.Fields{
+		"duration": obs.scrapeDuration,
+		"errors":   w.errors,
+		"projects": len(projects),
+		"groups":   len(c.config.Groups),
+	}).Debug("vulnerabilities collection completed")
+
+	return w.interrupted
+}
+
+// collectProject records the counts of open vulnerabilities, the oldest
+// open critical finding and the mean time to resolve of one project.
+// Resolved vulnerabilities are read most recently detected first, and
+// paging stops at the first page with none resolved within the window:
+// the report cannot be sorted by resolution time, but a page of old
+// resolutions means the rest of the history is older still.
+func (c *VulnerabilitiesCollector) collectProject(ctx context.Context, project string, obs *vulnerabilityObservations) error {
+	open, err := c.client.FetchProjectVulnerabilities(ctx, project, []string{"DETECTED", "CONFIRMED"}, nil)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	windowStart := now.Add(-c.config.ResolvedWindow())
+
+	inWindow := func(v gitlabclient.VulnerabilityNode) bool {
+		if v.ResolvedAt == nil {
+			return false
+		}
+		resolvedAt, err := time.Parse(time.RFC3339, *v.ResolvedAt)
+		return err == nil && !resolvedAt.Before(windowStart)
+	}
+	resolved, err := c.client.FetchProjectVulnerabilities(ctx, project, []string{"RESOLVED"}, func(page []gitlabclient.VulnerabilityNode) bool {
+		return slices.ContainsFunc(page, inWindow)
+	})
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[[3]string]int) // severity, report type, state
+	resolveSum := make(map[string]float64)
+	resolveCount := make(map[string]int)
+	var oldestCrit time.Time
+
+	for _, v := range open {
+		severity := strings.ToLower(v.Severity)
+		counts[[3]string{severity, strings.ToLower(v.ReportType), strings.ToLower(v.State)}]++
+
+		detectedAt, err := time.Parse(time.RFC3339, v.DetectedAt)
+		if err != nil {
+			continue
+		}
+		if severity == "critical" && (oldestCrit.IsZero() || detectedAt.Before(oldestCrit)) {
+			oldestCrit = detectedAt
+		}
+	}
+
+	for _, v := range resolved {
+		if !inWindow(v) {
+			continue
+		}
+		detectedAt, err := time.Parse(time.RFC3339, v.DetectedAt)
+		if err != nil {
+			continue
+		}
+		resolvedAt, _ := time.Parse(time.RFC3339, *v.ResolvedAt)
+		if resolvedAt.Before(detectedAt) {
+			continue
+		}
+		severity := strings.ToLower(v.Severity)
+		resolveSum[severity] += resolvedAt.Sub(detectedAt).Seconds()
+		resolveCount[severity]++
+	}
+
+	keys := make([][3]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		for n := range keys[i] {
+			if keys[i][n] != keys[j][n] {
+				return keys[i][n] < keys[j][n]
+			}
+		}
+		return false
+	})
+	for _, k := range keys {
+		obs.count = append(obs.count, labeledGauge{
+			labels: []string{project, k[0], k[1], k[2]},
+			value:  float64(counts[k]),
+		})
+	}
+
+	if !oldestCrit.IsZero() {
+		obs.oldestCrit = append(obs.oldestCrit, labeledGauge{
+			labels: []string{project},
+			value:  now.Sub(oldestCrit).Seconds(),
+		})
+	}
+
+	severities := make([]string, 0, len(resolveCount))
+	for sev := range resolveCount {
+		severities = append(severities, sev)
+	}
+	sort.Strings(severities)
+	for _, sev := range severities {
+		obs.timeToResolve = append(obs.timeToResolve, labeledGauge{
+			labels: []string{project, sev},
+			value:  resolveSum[sev] / float64(resolveCount[sev]),
+		})
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+)
+
+func TestVulnerabilitiesPagesByState(t *testing.T) {
+	now := time.Now().UTC()
+	ago := func(days int) string { return now.AddDate(0, 0, -days).Format(time.RFC3339) }
+	node := func(severity, state, detected, resolved string) string {
+		r := "null"
+		if resolved != "" {
+			r = fmt.Sprintf("%q", resolved)
+		}
+		return fmt.Sprintf(`{"severity":%q,"state":%q,"reportType":"SAST","detectedAt":%q,"resolvedAt":%s}`,
+			severity, state, detected, r)
+	}
+	// Pages of resolved vulnerabilities, most recently detected first. The
+	// second page has nothing resolved within the window, so the third
+	// must not be read.
+	resolvedPages := [][]string{
+		{node("HIGH", "RESOLVED", ago(20), ago(10)), node("HIGH", "RESOLVED", ago(200), ago(100))},
+		{node("LOW", "RESOLVED", ago(300), ago(250))},
+		{node("LOW", "RESOLVED", ago(400), ago(5))},
+	}
+
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string `json:"query"`
+			Variables struct {
+				State []string `json:"state"`
+				After *string  `json:"after"`
+			} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !strings.Contains(body.Query, "$state:[VulnerabilityState!]!") {
+			t.Errorf("query = %s, want a VulnerabilityState list variable", body.Query)
+		}
+		state := strings.Join(body.Variables.State, ",")
+		page := 0
+		if body.Variables.After != nil {
+			fmt.Sscan(*body.Variables.After, &page)
+		}
+		mu.Lock()
+		requests = append(requests, fmt.Sprintf("%s#%d", state, page))
+		mu.Unlock()
+
+		var nodes []string
+		switch state {
+		case "DETECTED,CONFIRMED":
+			nodes = []string{
+				node("CRITICAL", "DETECTED", ago(30), ""),
+				node("CRITICAL", "CONFIRMED", ago(60), ""),
+				node("HIGH", "DETECTED", ago(1), ""),
+			}
+		case "RESOLVED":
+			nodes = resolvedPages[page]
+		}
+		hasNext := state == "RESOLVED" && page+1 < len(resolvedPages)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data":{"project":{"vulnerabilities":{"nodes":[%s],"pageInfo":{"hasNextPage":%t,"endCursor":"%d"}}}}}`,
+			strings.Join(nodes, ","), hasNext, page+1)
+	}))
+	defer srv.Close()
+
+	client, err := gitlabclient.New(srv.URL, "token", 100, 100, true, logrus.NewEntry(logrus.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewVulnerabilitiesCollector(client, config.VulnerabilitiesCollectorConfig{ResolvedWindowDays: 90}, []string{"g/p"})
+
+	obs := vulnerabilityObservations{}
+	if err := c.collectProject(context.Background(), "g/p", &obs); err != nil {
+		t.Fatalf("collectProject: %v", err)
+	}
+
+	if want := []string{"DETECTED,CONFIRMED#0", "RESOLVED#0", "RESOLVED#1"}; !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+	wantCounts := []labeledGauge{
+		{labels: []string{"g/p", "critical", "sast", "confirmed"}, value: 1},
+		{labels: []string{"g/p", "critical", "sast", "detected"}, value: 1},
+		{labels: []string{"g/p", "high", "sast", "detected"}, value: 1},
+	}
+	if !reflect.DeepEqual(obs.count, wantCounts) {
+		t.Errorf("count = %+v, want %+v", obs.count, wantCounts)
+	}
+	if len(obs.oldestCrit) != 1 || obs.oldestCrit[0].value < 60*86400-60 {
+		t.Errorf("oldestCrit = %+v, want the confirmed finding of 60 days ago", obs.oldestCrit)
+	}
+	wantResolve := []labeledGauge{{labels: []string{"g/p", "high"}, value: 10 * 86400}}
+	if !reflect.DeepEqual(obs.timeToResolve, wantResolve) {
+		t.Errorf("timeToResolve = %+v, want %+v", obs.timeToResolve, wantResolve)
+	}
+}
@@ -0,0 +1,95 @@
+package collector
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/scheduler"
+)
+
+// walk spreads the work units of one collection run (projects, groups,
+// runners) evenly over the run's stagger window and tallies the units that
+// failed. A failed unit is logged and counted rather than aborting the run.
+// A run cut short by its timeout still publishes what it collected: Run
+// swaps in its observations and then returns interrupted.
+type walk struct {
+	ctx       context.Context
+	client    *gitlabclient.Client
+	collector string
+	logger    *logrus.Entry
+	stagger   *scheduler.Stagger
+	next      int
+
+	errors      float64
+	interrupted error
+}
+
+// newWalk returns a walk over the given number of work units of
+// collector's run.
+func newWalk(ctx context.Context, client *gitlabclient.Client, collector string, logger *logrus.Entry, units int) *walk {
+	return &walk{
+		ctx:       ctx,
+		client:    client,
+		collector: collector,
+		logger:    logger,
+		stagger:   scheduler.NewStagger(ctx, units),
+	}
+}
+
+// wait blocks until the slot of the next unit has come and reports whether
+// the run may go on.
+func (w *walk) wait() bool {
+	if w.interrupted != nil {
+		return false
+	}
+	if w.interrupted = w.stagger.Wait(w.ctx, w.next); w.interrupted != nil {
+		return false
+	}
+	w.next++
+	return true
+}
+
+// fail logs err for the unit identified by field and value and counts it
+// as a scrape error.
+func (w *walk) fail(err error, field string, value any, msg string) {
+	w.logger.WithError(err).WithField(field, value).Error(msg)
+	w.errors++
+}
+
+// eachProject calls fn for every project the collector may read; fn
+// reports its own failures through fail. Projects the collector may not
+// read still take their slot.
+func (w *walk) eachProject(projects []string, fn func(project string)) {
+	for _, project := range projects {
+		if !w.wait() {
+			return
+		}
+		if !w.client.ProjectAllowed(w.collector, project) {
+			continue
+		}
+		fn(project)
+	}
+}
+
+// projects is eachProject for an fn whose failure is logged with msg.
+func (w *walk) projects(projects []string, msg string, fn func(project string) error) {
+	w.eachProject(projects, func(project string) {
+		if err := fn(project); err != nil {
+			w.fail(err, "project", project, msg)
+		}
+	})
+}
+
+// groups calls fn for every group, logging the failures with msg.
+func (w *walk) groups(groups []string, msg string, fn func(group string) error) {
+	for _, group := range groups {
+		if !w.wait() {
+			return
+		}
+		if err := fn(group); err != nil {
+			w.fail(err, "group", group, msg)
+		}
+	}
+}
@@ -24,6 +24,74 @@ type Config struct {
 	Defaults   ProjectDefaults  `yaml:"defaults"    json:"defaults"`
 	Projects   []ProjectConfig  `yaml:"projects"    json:"projects"`
 	Wildcards  []WildcardConfig `yaml:"wildcards"   json:"wildcards"`
+	Instances  []InstanceConfig `yaml:"instances"   json:"instances"   validate:"omitempty,dive"`
+	Reload     ReloadConfig     `yaml:"reload"      json:"reload"`
+}
+
+// DefaultInstanceName is the instance label used when the top-level gitlab,
+// projects and wildcards settings describe a single GitLab instance.
+const DefaultInstanceName = "default"
+
+// InstanceConfig describes one named GitLab instance with its own
+// connection settings, projects and wildcards. Connection settings are
+// inlined, so an entry reads like the top-level gitlab section plus a name.
+type InstanceConfig struct {
+	Name         string `yaml:"name" json:"name" validate:"required"`
+	GitLabConfig `yaml:",inline"`
+	Projects     []ProjectConfig  `yaml:"projects"  json:"projects"`
+	Wildcards    []WildcardConfig `yaml:"wildcards" json:"wildcards"`
+}
+
+// UnmarshalYAML decodes an instance entry on top of the GitLab defaults so
+// that omitted connection settings behave like the top-level section.
+func (i *InstanceConfig) UnmarshalYAML(value *yaml.Node) error {
+	type plain InstanceConfig
+	p := plain{GitLabConfig: defaultGitLabConfig()}
+	if err := value.Decode(&p); err != nil {
+		return err
+	}
+	*i = InstanceConfig(p)
+	return nil
+}
+
+// ProjectTags returns the refs.tags settings of the explicitly listed
+// projects that override them, keyed by project path. Other projects use
+// the tags settings of defaults.
+func (i InstanceConfig) ProjectTags() map[string]TagsConfig {
+	tags := make(map[string]TagsConfig)
+	for _, p := range i.Projects {
+		if p.Refs != nil {
+			tags[p.Name] = p.Refs.Tags
+		}
+	}
+	return tags
+}
+
+// GitLabInstances returns the configured GitLab instances. When no
+// instances are listed, the top-level gitlab, projects and wildcards
+// settings form a single instance named DefaultInstanceName.
+func (c *Config) GitLabInstances() []InstanceConfig {
+	if len(c.Instances) > 0 {
+		return c.Instances
+	}
+	return []InstanceConfig{{
+		Name:         DefaultInstanceName,
+		GitLabConfig: c.GitLab,
+		Projects:     c.Projects,
+		Wildcards:    c.Wildcards,
+	}}
+}
+
+// ReloadConfig controls live configuration reloads. A reload is always
+// triggered by SIGHUP; WatchFile additionally polls the config file for changes.
+type ReloadConfig struct {
+	WatchFile           bool `yaml:"watch_file"            json:"watch_file"            env:"AGE_RELOAD_WATCH_FILE"`
+	PollIntervalSeconds int  `yaml:"poll_interval_seconds" json:"poll_interval_seconds" env:"AGE_RELOAD_POLL_INTERVAL_SECONDS" validate:"omitempty,min=1"`
+}
+
+// PollInterval returns the config file poll interval as a time.Duration.
+func (c ReloadConfig) PollInterval() time.Duration {
+	return time.Duration(c.PollIntervalSeconds) * time.Second
 }
 
 // LogConfig holds logging configuration.
@@ -34,49 +102,208 @@ type LogConfig struct {
 
 // ServerConfig holds HTTP server settings.
 type ServerConfig struct {
-	ListenAddress string        `yaml:"listen_address" json:"listen_address" env:"AGE_LISTEN_ADDRESS" validate:"required"`
-	EnablePprof   bool          `yaml:"enable_pprof"   json:"enable_pprof"   env:"AGE_ENABLE_PPROF"`
-	Webhook       WebhookConfig `yaml:"webhook"        json:"webhook"`
+	ListenAddress string          `yaml:"listen_address" json:"listen_address" env:"AGE_LISTEN_ADDRESS" validate:"required"`
+	EnablePprof   bool            `yaml:"enable_pprof"   json:"enable_pprof"   env:"AGE_ENABLE_PPROF"`
+	Webhook       WebhookConfig   `yaml:"webhook"        json:"webhook"`
+	Readiness     ReadinessConfig `yaml:"readiness"      json:"readiness"`
+}
+
+// ReadinessConfig controls when /ready reports the exporter as ready. Every
+// GitLab instance must always have started; in addition, require_first_collection waits
+// until every enabled collector has completed one cycle, and
+// gitlab_reachable_within_minutes requires a successful GitLab response
+// within the last N minutes for every instance (0 disables the check).
+type ReadinessConfig struct {
+	RequireFirstCollection       bool `yaml:"require_first_collection"        json:"require_first_collection"`
+	GitLabReachableWithinMinutes int  `yaml:"gitlab_reachable_within_minutes" json:"gitlab_reachable_within_minutes" validate:"min=0"`
+}
+
+// GitLabReachableWithin returns the GitLab reachability window.
+func (c ReadinessConfig) GitLabReachableWithin() time.Duration {
+	return time.Duration(c.GitLabReachableWithinMinutes) * time.Minute
 }
 
 // WebhookConfig holds webhook receiver settings.
 type WebhookConfig struct {
-	Enabled     bool   `yaml:"enabled"      json:"enabled"      env:"AGE_WEBHOOK_ENABLED"`
-	SecretToken string `yaml:"secret_token" json:"secret_token" env:"AGE_WEBHOOK_SECRET_TOKEN"`
+	Enabled         bool   `yaml:"enabled"           json:"enabled"           env:"AGE_WEBHOOK_ENABLED"`
+	SecretToken     string `yaml:"secret_token"      json:"secret_token"      env:"AGE_WEBHOOK_SECRET_TOKEN"`
+	SecretTokenFile string `yaml:"secret_token_file" json:"secret_token_file" env:"AGE_WEBHOOK_SECRET_TOKEN_FILE" validate:"omitempty,file"`
 }
 
-// RedisConfig holds Redis connection settings.
+// RedisConfig holds Redis connection settings. Mode selects the client
+// topology: standalone (URL or first entry of Addrs), sentinel (MasterName
+// plus sentinel Addrs) or cluster (seed Addrs). SharedRateLimit keeps the
+// GitLab rate-limit budget of every token in Redis, so that replicas and
+// other tools sharing a token share its budget.
 type RedisConfig struct {
-	URL          string `yaml:"url"            json:"url"            env:"AGE_REDIS_URL"`
-	PoolSize     int    `yaml:"pool_size"      json:"pool_size"      env:"AGE_REDIS_POOL_SIZE"      validate:"omitempty,min=1"`
-	MinIdleConns int    `yaml:"min_idle_conns" json:"min_idle_conns" env:"AGE_REDIS_MIN_IDLE_CONNS"  validate:"omitempty,min=0"`
+	Mode               string         `yaml:"mode"                 json:"mode"                 env:"AGE_REDIS_MODE"                 validate:"omitempty,oneof=standalone sentinel cluster"`
+	URL                string         `yaml:"url"                  json:"url"                  env:"AGE_REDIS_URL"`
+	URLFile            string         `yaml:"url_file"             json:"url_file"             env:"AGE_REDIS_URL_FILE"             validate:"omitempty,file"`
+	Addrs              []string       `yaml:"addrs"                json:"addrs"                env:"AGE_REDIS_ADDRS"`
+	MasterName         string         `yaml:"master_name"          json:"master_name"          env:"AGE_REDIS_MASTER_NAME"          validate:"required_if=Mode sentinel"`
+	DB                 int            `yaml:"db"                   json:"db"                   env:"AGE_REDIS_DB"                   validate:"min=0"`
+	Username           string         `yaml:"username"             json:"username"             env:"AGE_REDIS_USERNAME"`
+	UsernameFile       string         `yaml:"username_file"        json:"username_file"        env:"AGE_REDIS_USERNAME_FILE"        validate:"omitempty,file"`
+	Password           string         `yaml:"password"             json:"password"             env:"AGE_REDIS_PASSWORD"`
+	PasswordFile       string         `yaml:"password_file"        json:"password_file"        env:"AGE_REDIS_PASSWORD_FILE"        validate:"omitempty,file"`
+	SentinelPassword   string         `yaml:"sentinel_password"    json:"sentinel_password"    env:"AGE_REDIS_SENTINEL_PASSWORD"`
+	KeyPrefix          string         `yaml:"key_prefix"           json:"key_prefix"           env:"AGE_REDIS_KEY_PREFIX"`
+	PoolSize           int            `yaml:"pool_size"            json:"pool_size"            env:"AGE_REDIS_POOL_SIZE"            validate:"omitempty,min=1"`
+	MinIdleConns       int            `yaml:"min_idle_conns"       json:"min_idle_conns"       env:"AGE_REDIS_MIN_IDLE_CONNS"       validate:"min=0"`
+	MaxIdleConns       int            `yaml:"max_idle_conns"       json:"max_idle_conns"       env:"AGE_REDIS_MAX_IDLE_CONNS"       validate:"min=0"`
+	DialTimeoutSeconds int            `yaml:"dial_timeout_seconds" json:"dial_timeout_seconds" env:"AGE_REDIS_DIAL_TIMEOUT_SECONDS" validate:"omitempty,min=1"`
+	SharedRateLimit    bool           `yaml:"shared_rate_limit"    json:"shared_rate_limit"    env:"AGE_REDIS_SHARED_RATE_LIMIT"`
+	TLS                RedisTLSConfig `yaml:"tls"                  json:"tls"`
+}
+
+// Enabled reports whether a Redis backend has been configured.
+func (c RedisConfig) Enabled() bool {
+	return c.URL != "" || len(c.Addrs) > 0
+}
+
+// RedisTLSConfig holds TLS settings for Redis connections.
+type RedisTLSConfig struct {
+	Enabled            bool   `yaml:"enabled"              json:"enabled"              env:"AGE_REDIS_TLS_ENABLED"`
+	CACertPath         string `yaml:"ca_cert_path"         json:"ca_cert_path"         env:"AGE_REDIS_TLS_CA_CERT_PATH"         validate:"omitempty,file"`
+	CertPath           string `yaml:"cert_path"            json:"cert_path"            env:"AGE_REDIS_TLS_CERT_PATH"            validate:"omitempty,file"`
+	KeyPath            string `yaml:"key_path"             json:"key_path"             env:"AGE_REDIS_TLS_KEY_PATH"             validate:"omitempty,file"`
+	ServerName         string `yaml:"server_name"          json:"server_name"          env:"AGE_REDIS_TLS_SERVER_NAME"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify" json:"insecure_skip_verify" env:"AGE_REDIS_TLS_INSECURE_SKIP_VERIFY"`
 }
 
 // GitLabConfig holds GitLab API connection settings.
 type GitLabConfig struct {
-	URL                    string `yaml:"url"                       json:"url"                       env:"AGE_GITLAB_URL"                  validate:"required,url"`
-	Token                  string `yaml:"token"                     json:"token"                     env:"AGE_GITLAB_TOKEN"                validate:"required"`
-	EnableTLSVerify        bool   `yaml:"enable_tls_verify"         json:"enable_tls_verify"         env:"AGE_GITLAB_ENABLE_TLS_VERIFY"`
-	CACertPath             string `yaml:"ca_cert_path"              json:"ca_cert_path"              env:"AGE_GITLAB_CA_CERT_PATH"         validate:"omitempty,file"`
-	MaxRequestsPerSecond   int    `yaml:"max_requests_per_second"   json:"max_requests_per_second"   env:"AGE_GITLAB_MAX_RPS"              validate:"omitempty,min=0"`
-	BurstRequestsPerSecond int    `yaml:"burst_requests_per_second" json:"burst_requests_per_second" env:"AGE_GITLAB_BURST_RPS"            validate:"omitempty,min=0"`
-	UseGraphQL             bool   `yaml:"use_graphql"               json:"use_graphql"               env:"AGE_GITLAB_USE_GRAPHQL"`
-	GraphQLPageSize        int    `yaml:"graphql_page_size"         json:"graphql_page_size"         env:"AGE_GITLAB_GRAPHQL_PAGE_SIZE"    validate:"omitempty,min=1,max=100"`
-	RESTPageSize           int    `yaml:"rest_page_size"            json:"rest_page_size"            env:"AGE_GITLAB_REST_PAGE_SIZE"       validate:"omitempty,min=1,max=100"`
+	URL                    string        `yaml:"url"                       json:"url"                       env:"AGE_GITLAB_URL"               validate:"required,url"`
+	Token                  string        `yaml:"token"                     json:"token"                     env:"AGE_GITLAB_TOKEN"`
+	TokenFile              string        `yaml:"token_file"                json:"token_file"                env:"AGE_GITLAB_TOKEN_FILE"        validate:"omitempty,file"`
+	EnableTLSVerify        bool          `yaml:"enable_tls_verify"         json:"enable_tls_verify"         env:"AGE_GITLAB_ENABLE_TLS_VERIFY"`
+	CACertPath             string        `yaml:"ca_cert_path"              json:"ca_cert_path"              env:"AGE_GITLAB_CA_CERT_PATH"      validate:"omitempty,file"`
+	MaxRequestsPerSecond   int           `yaml:"max_requests_per_second"   json:"max_requests_per_second"   env:"AGE_GITLAB_MAX_RPS"           validate:"min=0"`
+	BurstRequestsPerSecond int           `yaml:"burst_requests_per_second" json:"burst_requests_per_second" env:"AGE_GITLAB_BURST_RPS"         validate:"min=0"`
+	UseGraphQL             bool          `yaml:"use_graphql"               json:"use_graphql"               env:"AGE_GITLAB_USE_GRAPHQL"`
+	GraphQLPageSize        int           `yaml:"graphql_page_size"         json:"graphql_page_size"         env:"AGE_GITLAB_GRAPHQL_PAGE_SIZE" validate:"omitempty,min=1,max=100"`
+	RESTPageSize           int           `yaml:"rest_page_size"            json:"rest_page_size"            env:"AGE_GITLAB_REST_PAGE_SIZE"    validate:"omitempty,min=1,max=100"`
+	Tokens                 []TokenConfig `yaml:"tokens" json:"tokens" validate:"omitempty,dive"`
+
+	PriorityClasses []PriorityClassConfig `yaml:"priority_classes" json:"priority_classes" validate:"omitempty,dive"`
+	EndpointLimits  []EndpointLimitConfig `yaml:"endpoint_limits"  json:"endpoint_limits"  validate:"omitempty,dive"`
+}
+
+// PriorityClassConfig is a share of the instance's request rate. While
+// requests wait for max_requests_per_second, every class with waiting
+// requests gets a share proportional to its weight. Collectors pick a class
+// with priority_class; requests outside collectors (project discovery, tier
+// detection) use the "default" class, and classes that are not listed have
+// weight 1.
+type PriorityClassConfig struct {
+	Name   string `yaml:"name"   json:"name"   validate:"required"`
+	Weight int    `yaml:"weight" json:"weight" validate:"required,min=1"`
+}
+
+// EndpointLimitConfig throttles the requests whose URL path (e.g.
+// /api/v4/projects) matches PathRegexp to their own rate, on top of
+// max_requests_per_second, for GitLab endpoints with a dedicated limit.
+type EndpointLimitConfig struct {
+	Name                   string `yaml:"name"                      json:"name"                      validate:"required"`
+	PathRegexp             string `yaml:"path_regexp"               json:"path_regexp"               validate:"required"`
+	MaxRequestsPerSecond   int    `yaml:"max_requests_per_second"   json:"max_requests_per_second"   validate:"required,min=1"`
+	BurstRequestsPerSecond int    `yaml:"burst_requests_per_second" json:"burst_requests_per_second" validate:"min=0"`
+}
+
+// TokenConfig is an additional access token in the instance's token pool.
+// Requests for projects and groups under one of Namespaces (path prefixes
+// such as "my-group" or "my-group/sub") use this token; a token without
+// namespaces serves any request, alongside the main gitlab token.
+type TokenConfig struct {
+	Name       string   `yaml:"name"       json:"name"       validate:"required"`
+	Token      string   `yaml:"token"      json:"token"`
+	TokenFile  string   `yaml:"token_file" json:"token_file" validate:"omitempty,file"`
+	Namespaces []string `yaml:"namespaces" json:"namespaces"`
 }
 
 // CollectorsConfig wraps individual collector configurations.
 type CollectorsConfig struct {
-	Pipelines     PipelinesCollectorConfig     `yaml:"pipelines"      json:"pipelines"`
-	Jobs          JobsCollectorConfig          `yaml:"jobs"           json:"jobs"`
-	MergeRequests MergeRequestsCollectorConfig `yaml:"merge_requests" json:"merge_requests"`
-	Environments  EnvironmentsCollectorConfig  `yaml:"environments"   json:"environments"`
-	TestReports   TestReportsCollectorConfig   `yaml:"test_reports"   json:"test_reports"`
-	DORA          DORACollectorConfig          `yaml:"dora"           json:"dora"`
-	ValueStream   ValueStreamCollectorConfig   `yaml:"value_stream"   json:"value_stream"`
-	CodeReview    CodeReviewCollectorConfig    `yaml:"code_review"    json:"code_review"`
-	Repository    RepositoryCollectorConfig    `yaml:"repository"     json:"repository"`
-	Contributors  ContributorsCollectorConfig  `yaml:"contributors"   json:"contributors"`
+	Pipelines         PipelinesCollectorConfig         `yaml:"pipelines"          json:"pipelines"`
+	Jobs              JobsCollectorConfig              `yaml:"jobs"               json:"jobs"`
+	MergeRequests     MergeRequestsCollectorConfig     `yaml:"merge_requests"     json:"merge_requests"`
+	Environments      EnvironmentsCollectorConfig      `yaml:"environments"       json:"environments"`
+	TestReports       TestReportsCollectorConfig       `yaml:"test_reports"       json:"test_reports"`
+	DORA              DORACollectorConfig              `yaml:"dora"               json:"dora"`
+	ValueStream       ValueStreamCollectorConfig       `yaml:"value_stream"       json:"value_stream"`
+	CodeReview        CodeReviewCollectorConfig        `yaml:"code_review"        json:"code_review"`
+	Repository        RepositoryCollectorConfig        `yaml:"repository"         json:"repository"`
+	Contributors      ContributorsCollectorConfig      `yaml:"contributors"       json:"contributors"`
+	Runners           RunnersCollectorConfig           `yaml:"runners"            json:"runners"`
+	JobQueue          JobQueueCollectorConfig          `yaml:"job_queue"          json:"job_queue"`
+	Issues            IssuesCollectorConfig            `yaml:"issues"             json:"issues"`
+	Incidents         IncidentsCollectorConfig         `yaml:"incidents"          json:"incidents"`
+	Releases          ReleasesCollectorConfig          `yaml:"releases"           json:"releases"`
+	Registry          RegistryCollectorConfig          `yaml:"container_registry" json:"container_registry"`
+	Packages          PackagesCollectorConfig          `yaml:"packages"           json:"packages"`
+	Vulnerabilities   VulnerabilitiesCollectorConfig   `yaml:"vulnerabilities"    json:"vulnerabilities"`
+	PipelineSchedules PipelineSchedulesCollectorConfig `yaml:"pipeline_schedules" json:"pipeline_schedules"`
+	MergeTrains       MergeTrainsCollectorConfig       `yaml:"merge_trains"       json:"merge_trains"`
+	Compliance        ComplianceCollectorConfig        `yaml:"compliance"         json:"compliance"`
+	Milestones        MilestonesCollectorConfig        `yaml:"milestones"         json:"milestones"`
+	Adaptive          AdaptiveConfig                   `yaml:"adaptive"           json:"adaptive"`
+}
+
+// AdaptiveConfig controls adaptive collection intervals. When enabled, the
+// API requests each collector makes per cycle are compared with the GitLab
+// rate limit: while they would use more than TargetBudgetPercent of it (or
+// the remaining budget runs low), the interval of the lowest-priority
+// collector is doubled, up to MaxStretch times its configured value; when
+// headroom returns, the highest-priority stretched collector is shrunk back
+// toward its configured interval. Collectors on a cron schedule are not
+// adapted.
+type AdaptiveConfig struct {
+	Enabled                   bool `yaml:"enabled"                     json:"enabled"`
+	TargetBudgetPercent       int  `yaml:"target_budget_percent"       json:"target_budget_percent"       validate:"omitempty,min=1,max=100"`
+	MaxStretch                int  `yaml:"max_stretch"                 json:"max_stretch"                 validate:"omitempty,min=1"`
+	EvaluationIntervalSeconds int  `yaml:"evaluation_interval_seconds" json:"evaluation_interval_seconds" validate:"omitempty,min=1"`
+}
+
+// EvaluationInterval returns how often intervals are re-evaluated.
+func (c AdaptiveConfig) EvaluationInterval() time.Duration {
+	return time.Duration(c.EvaluationIntervalSeconds) * time.Second
+}
+
+// ScheduleConfig holds the scheduling options shared by every collector.
+// Schedule is a five-field cron expression evaluated in UTC (e.g.
+// "0 2 * * *"); when set it replaces interval_seconds, after one run at
+// startup. Jitter adds a random delay of up to JitterSeconds to every run,
+// and StaggerProjects spreads the projects of a cycle over most of the
+// time until the next one. A run is cancelled after TimeoutSeconds, keeping
+// what it collected so far; Overlap decides what happens when a run is due
+// while the previous one is still executing (skip, queue or
+// cancel_previous). Priority orders collectors for adaptive intervals
+// (see AdaptiveConfig): higher-priority collectors are slowed down last.
+// PriorityClass is the gitlab priority class the collector's requests
+// queue in when the request rate is saturated (see PriorityClassConfig).
+type ScheduleConfig struct {
+	Schedule            string `yaml:"schedule"              json:"schedule"`
+	InitialDelaySeconds int    `yaml:"initial_delay_seconds" json:"initial_delay_seconds" validate:"min=0"`
+	JitterSeconds       int    `yaml:"jitter_seconds"        json:"jitter_seconds"        validate:"min=0"`
+	StaggerProjects     bool   `yaml:"stagger_projects"      json:"stagger_projects"`
+	TimeoutSeconds      int    `yaml:"timeout_seconds"       json:"timeout_seconds"       validate:"min=0"`
+	Overlap             string `yaml:"overlap"               json:"overlap"               validate:"omitempty,oneof=skip queue cancel_previous"`
+	Priority            int    `yaml:"priority"              json:"priority"`
+	PriorityClass       string `yaml:"priority_class"        json:"priority_class"`
+}
+
+// InitialDelay returns the delay before the first run.
+func (c ScheduleConfig) InitialDelay() time.Duration {
+	return time.Duration(c.InitialDelaySeconds) * time.Second
+}
+
+// Jitter returns the maximum random delay added to every run.
+func (c ScheduleConfig) Jitter() time.Duration {
+	return time.Duration(c.JitterSeconds) * time.Second
+}
+
+// Timeout returns the deadline of a single run (0 = none).
+func (c ScheduleConfig) Timeout() time.Duration {
+	return time.Duration(c.TimeoutSeconds) * time.Second
 }
 
 // PipelinesCollectorConfig holds pipeline collector settings.
@@ -86,6 +313,8 @@ type PipelinesCollectorConfig struct {
 	IncludeChildPipelines bool      `yaml:"include_child_pipelines" json:"include_child_pipelines"`
 	HistogramBuckets      []float64 `yaml:"histogram_buckets"      json:"histogram_buckets"`
 	MaxPipelinesPerRef    int       `yaml:"max_pipelines_per_ref"  json:"max_pipelines_per_ref"  validate:"omitempty,min=1"`
+
+	ScheduleConfig `yaml:",inline"`
 }
 
 // Interval returns the collector interval as a time.Duration.
@@ -99,6 +328,8 @@ type JobsCollectorConfig struct {
 	IntervalSeconds      int       `yaml:"interval_seconds"      json:"interval_seconds"       validate:"omitempty,min=1"`
 	HistogramBuckets     []float64 `yaml:"histogram_buckets"     json:"histogram_buckets"`
 	IncludeRunnerDetails bool      `yaml:"include_runner_details" json:"include_runner_details"`
+
+	ScheduleConfig `yaml:",inline"`
 }
 
 // Interval returns the collector interval as a time.Duration.
@@ -111,6 +342,8 @@ type MergeRequestsCollectorConfig struct {
 	Enabled          bool      `yaml:"enabled"          json:"enabled"`
 	IntervalSeconds  int       `yaml:"interval_seconds" json:"interval_seconds" validate:"omitempty,min=1"`
 	HistogramBuckets []float64 `yaml:"histogram_buckets" json:"histogram_buckets"`
+
+	ScheduleConfig `yaml:",inline"`
 }
 
 // Interval returns the collector interval as a time.Duration.
@@ -123,6 +356,8 @@ type EnvironmentsCollectorConfig struct {
 	Enabled         bool `yaml:"enabled"          json:"enabled"`
 	IntervalSeconds int  `yaml:"interval_seconds" json:"interval_seconds" validate:"omitempty,min=1"`
 	ExcludeStopped  bool `yaml:"exclude_stopped"  json:"exclude_stopped"`
+
+	ScheduleConfig `yaml:",inline"`
 }
 
 // Interval returns the collector interval as a time.Duration.
@@ -135,6 +370,8 @@ type TestReportsCollectorConfig struct {
 	Enabled          bool `yaml:"enabled"            json:"enabled"`
 	IntervalSeconds  int  `yaml:"interval_seconds"   json:"interval_seconds" validate:"omitempty,min=1"`
 	IncludeTestCases bool `yaml:"include_test_cases"  json:"include_test_cases"`
+
+	ScheduleConfig `yaml:",inline"`
 }
 
 // Interval returns the collector interval as a time.Duration.
@@ -147,6 +384,8 @@ type DORACollectorConfig struct {
 	Enabled          bool     `yaml:"enabled"           json:"enabled"`
 	IntervalSeconds  int      `yaml:"interval_seconds"  json:"interval_seconds" validate:"omitempty,min=1"`
 	EnvironmentTiers []string `yaml:"environment_tiers" json:"environment_tiers"`
+
+	ScheduleConfig `yaml:",inline"`
 }
 
 // Interval returns the collector interval as a time.Duration.
@@ -158,6 +397,8 @@ func (c DORACollectorConfig) Interval() time.Duration {
 type ValueStreamCollectorConfig struct {
 	Enabled         bool `yaml:"enabled"          json:"enabled"`
 	IntervalSeconds int  `yaml:"interval_seconds" json:"interval_seconds" validate:"omitempty,min=1"`
+
+	ScheduleConfig `yaml:",inline"`
 }
 
 // Interval returns the collector interval as a time.Duration.
@@ -169,6 +410,8 @@ func (c ValueStreamCollectorConfig) Interval() time.Duration {
 type CodeReviewCollectorConfig struct {
 	Enabled         bool `yaml:"enabled"          json:"enabled"`
 	IntervalSeconds int  `yaml:"interval_seconds" json:"interval_seconds" validate:"omitempty,min=1"`
+
+	ScheduleConfig `yaml:",inline"`
 }
 
 // Interval returns the collector interval as a time.Duration.
@@ -180,6 +423,8 @@ func (c CodeReviewCollectorConfig) Interval() time.Duration {
 type RepositoryCollectorConfig struct {
 	Enabled         bool `yaml:"enabled"          json:"enabled"`
 	IntervalSeconds int  `yaml:"interval_seconds" json:"interval_seconds" validate:"omitempty,min=1"`
+
+	ScheduleConfig `yaml:",inline"`
 }
 
 // Interval returns the collector interval as a time.Duration.
@@ -191,6 +436,8 @@ func (c RepositoryCollectorConfig) Interval() time.Duration {
 type ContributorsCollectorConfig struct {
 	Enabled         bool `yaml:"enabled"          json:"enabled"`
 	IntervalSeconds int  `yaml:"interval_seconds" json:"interval_seconds" validate:"omitempty,min=1"`
+
+	ScheduleConfig `yaml:",inline"`
 }
 
 // Interval returns the collector interval as a time.Duration.
@@ -198,6 +445,270 @@ func (c ContributorsCollectorConfig) Interval() time.Duration {
 	return time.Duration(c.IntervalSeconds) * time.Second
 }
 
+// RunnersCollectorConfig holds runner collector settings. Instance runners
+// are listed through the admin endpoint, falling back to the runners the
+// token can see when it is not an administrator; Groups lists the runners
+// of the given group paths, and ProjectRunners those of every tracked
+// project the token maintains. GitLab does not report a runner's
+// concurrent setting, so MaxConcurrency declares it per runner description.
+type RunnersCollectorConfig struct {
+	Enabled         bool           `yaml:"enabled"          json:"enabled"`
+	IntervalSeconds int            `yaml:"interval_seconds" json:"interval_seconds" validate:"omitempty,min=1"`
+	InstanceRunners bool           `yaml:"instance_runners" json:"instance_runners"`
+	Groups          []string       `yaml:"groups"           json:"groups"`
+	ProjectRunners  bool           `yaml:"project_runners"  json:"project_runners"`
+	MaxConcurrency  map[string]int `yaml:"max_concurrency"  json:"max_concurrency"  validate:"omitempty,dive,min=1"`
+
+	ScheduleConfig `yaml:",inline"`
+}
+
+// Interval returns the collector interval as a time.Duration.
+func (c RunnersCollectorConfig) Interval() time.Duration {
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// JobQueueCollectorConfig holds job queue collector settings. The queue
+// wait histogram covers the jobs of the tracked projects that were created
+// within the last WaitWindowSeconds and have left the queue.
+type JobQueueCollectorConfig struct {
+	Enabled           bool      `yaml:"enabled"             json:"enabled"`
+	IntervalSeconds   int       `yaml:"interval_seconds"    json:"interval_seconds"    validate:"omitempty,min=1"`
+	HistogramBuckets  []float64 `yaml:"histogram_buckets"   json:"histogram_buckets"`
+	WaitWindowSeconds int       `yaml:"wait_window_seconds" json:"wait_window_seconds" validate:"omitempty,min=1"`
+
+	ScheduleConfig `yaml:",inline"`
+}
+
+// Interval returns the collector interval as a time.Duration.
+func (c JobQueueCollectorConfig) Interval() time.Duration {
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// WaitWindow returns the queue wait histogram window as a time.Duration.
+func (c JobQueueCollectorConfig) WaitWindow() time.Duration {
+	return time.Duration(c.WaitWindowSeconds) * time.Second
+}
+
+// IssuesCollectorConfig holds issue collector settings. Only the labels
+// listed in Labels get an open-issue count of their own, which keeps the
+// label dimension bounded. Issues are fetched incrementally by updated_at;
+// a full re-sync every FullSyncIntervalSeconds drops deleted issues.
+type IssuesCollectorConfig struct {
+	Enabled                 bool      `yaml:"enabled"                    json:"enabled"`
+	IntervalSeconds         int       `yaml:"interval_seconds"           json:"interval_seconds"           validate:"omitempty,min=1"`
+	HistogramBuckets        []float64 `yaml:"histogram_buckets"          json:"histogram_buckets"`
+	Labels                  []string  `yaml:"labels"                     json:"labels"`
+	ClosedWindowDays        int       `yaml:"closed_window_days"         json:"closed_window_days"         validate:"omitempty,min=1"`
+	FullSyncIntervalSeconds int       `yaml:"full_sync_interval_seconds" json:"full_sync_interval_seconds" validate:"omitempty,min=1"`
+
+	ScheduleConfig `yaml:",inline"`
+}
+
+// Interval returns the collector interval as a time.Duration.
+func (c IssuesCollectorConfig) Interval() time.Duration {
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// ClosedWindow returns the time-to-close window as a time.Duration.
+func (c IssuesCollectorConfig) ClosedWindow() time.Duration {
+	return time.Duration(c.ClosedWindowDays) * 24 * time.Hour
+}
+
+// FullSyncInterval returns the full re-sync interval as a time.Duration.
+func (c IssuesCollectorConfig) FullSyncInterval() time.Duration {
+	return time.Duration(c.FullSyncIntervalSeconds) * time.Second
+}
+
+// IncidentsCollectorConfig holds incident collector settings. Severities
+// are read from labels starting with SeverityLabelPrefix; incidents
+// resolved within ResolvedWindowDays feed the time-to-resolve histograms.
+// IncludeAlerts additionally reads alert management alerts over GraphQL.
+type IncidentsCollectorConfig struct {
+	Enabled                 bool      `yaml:"enabled"                    json:"enabled"`
+	IntervalSeconds         int       `yaml:"interval_seconds"           json:"interval_seconds"           validate:"omitempty,min=1"`
+	HistogramBuckets        []float64 `yaml:"histogram_buckets"          json:"histogram_buckets"`
+	SeverityLabelPrefix     string    `yaml:"severity_label_prefix"      json:"severity_label_prefix"`
+	ResolvedWindowDays      int       `yaml:"resolved_window_days"       json:"resolved_window_days"       validate:"omitempty,min=1"`
+	FullSyncIntervalSeconds int       `yaml:"full_sync_interval_seconds" json:"full_sync_interval_seconds" validate:"omitempty,min=1"`
+	IncludeAlerts           bool      `yaml:"include_alerts"             json:"include_alerts"`
+
+	ScheduleConfig `yaml:",inline"`
+}
+
+// Interval returns the collector interval as a time.Duration.
+func (c IncidentsCollectorConfig) Interval() time.Duration {
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// ResolvedWindow returns the time-to-resolve window as a time.Duration.
+func (c IncidentsCollectorConfig) ResolvedWindow() time.Duration {
+	return time.Duration(c.ResolvedWindowDays) * 24 * time.Hour
+}
+
+// FullSyncInterval returns the full re-sync interval as a time.Duration.
+func (c IncidentsCollectorConfig) FullSyncInterval() time.Duration {
+	return time.Duration(c.FullSyncIntervalSeconds) * time.Second
+}
+
+// ReleasesCollectorConfig holds release collector settings. Releases are
+// filtered by the project's refs.tags settings. IncludeChanges compares
+// each release with the previous one to count the commits and merge
+// requests it shipped and their lead time from commit to release, one
+// compare request per new release.
+type ReleasesCollectorConfig struct {
+	Enabled          bool      `yaml:"enabled"           json:"enabled"`
+	IntervalSeconds  int       `yaml:"interval_seconds"  json:"interval_seconds"  validate:"omitempty,min=1"`
+	HistogramBuckets []float64 `yaml:"histogram_buckets" json:"histogram_buckets"`
+	IncludeChanges   bool      `yaml:"include_changes"   json:"include_changes"`
+
+	ScheduleConfig `yaml:",inline"`
+}
+
+// Interval returns the collector interval as a time.Duration.
+func (c ReleasesCollectorConfig) Interval() time.Duration {
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// RegistryCollectorConfig holds container registry collector settings.
+// Tag details (size and creation time) take one request per tag, so at
+// most MaxTagsPerRepository tags are walked per repository, paced at
+// TagDetailsPerSecond on top of the client rate limit (0 for no extra
+// pacing).
+type RegistryCollectorConfig struct {
+	Enabled              bool    `yaml:"enabled"                 json:"enabled"`
+	IntervalSeconds      int     `yaml:"interval_seconds"        json:"interval_seconds"        validate:"omitempty,min=1"`
+	MaxTagsPerRepository int     `yaml:"max_tags_per_repository" json:"max_tags_per_repository" validate:"omitempty,min=1"`
+	TagDetailsPerSecond  float64 `yaml:"tag_details_per_second"  json:"tag_details_per_second"  validate:"omitempty,min=0"`
+
+	ScheduleConfig `yaml:",inline"`
+}
+
+// Interval returns the collector interval as a time.Duration.
+func (c RegistryCollectorConfig) Interval() time.Duration {
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// PackagesCollectorConfig holds package registry collector settings.
+// Groups lists group paths whose packages are read with one group-level
+// listing instead of one listing per project; tracked projects outside
+// those groups are still listed individually. IncludeFiles reads the files
+// of every package version for sizes and publishing pipelines, one request
+// per version the first time it is seen.
+type PackagesCollectorConfig struct {
+	Enabled         bool     `yaml:"enabled"          json:"enabled"`
+	IntervalSeconds int      `yaml:"interval_seconds" json:"interval_seconds" validate:"omitempty,min=1"`
+	Groups          []string `yaml:"groups"           json:"groups"`
+	IncludeFiles    bool     `yaml:"include_files"    json:"include_files"`
+
+	ScheduleConfig `yaml:",inline"`
+}
+
+// Interval returns the collector interval as a time.Duration.
+func (c PackagesCollectorConfig) Interval() time.Duration {
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// VulnerabilitiesCollectorConfig holds vulnerability collector settings.
+// Project vulnerabilities are read from the vulnerability report over
+// GraphQL; those resolved within ResolvedWindowDays feed the mean time to
+// resolve. Groups lists group paths whose severity counts are exported as
+// well.
+type VulnerabilitiesCollectorConfig struct {
+	Enabled            bool     `yaml:"enabled"              json:"enabled"`
+	IntervalSeconds    int      `yaml:"interval_seconds"     json:"interval_seconds"     validate:"omitempty,min=1"`
+	ResolvedWindowDays int      `yaml:"resolved_window_days" json:"resolved_window_days" validate:"omitempty,min=1"`
+	Groups             []string `yaml:"groups"               json:"groups"`
+
+	ScheduleConfig `yaml:",inline"`
+}
+
+// Interval returns the collector interval as a time.Duration.
+func (c VulnerabilitiesCollectorConfig) Interval() time.Duration {
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// ResolvedWindow returns the time-to-resolve window as a time.Duration.
+func (c VulnerabilitiesCollectorConfig) ResolvedWindow() time.Duration {
+	return time.Duration(c.ResolvedWindowDays) * 24 * time.Hour
+}
+
+// PipelineSchedulesCollectorConfig holds pipeline schedule collector
+// settings.
+type PipelineSchedulesCollectorConfig struct {
+	Enabled         bool `yaml:"enabled"          json:"enabled"`
+	IntervalSeconds int  `yaml:"interval_seconds" json:"interval_seconds" validate:"omitempty,min=1"`
+
+	ScheduleConfig `yaml:",inline"`
+}
+
+// Interval returns the collector interval as a time.Duration.
+func (c PipelineSchedulesCollectorConfig) Interval() time.Duration {
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// MergeTrainsCollectorConfig holds merge train collector settings. Cars
+// merged within the last WindowHours feed the time-in-train histogram and
+// the hourly throughput.
+type MergeTrainsCollectorConfig struct {
+	Enabled          bool      `yaml:"enabled"           json:"enabled"`
+	IntervalSeconds  int       `yaml:"interval_seconds"  json:"interval_seconds"  validate:"omitempty,min=1"`
+	HistogramBuckets []float64 `yaml:"histogram_buckets" json:"histogram_buckets"`
+	WindowHours      int       `yaml:"window_hours"      json:"window_hours"      validate:"omitempty,min=1"`
+
+	ScheduleConfig `yaml:",inline"`
+}
+
+// Interval returns the collector interval as a time.Duration.
+func (c MergeTrainsCollectorConfig) Interval() time.Duration {
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// Window returns the throughput window as a time.Duration.
+func (c MergeTrainsCollectorConfig) Window() time.Duration {
+	return time.Duration(c.WindowHours) * time.Hour
+}
+
+// ComplianceCollectorConfig holds compliance collector settings. Checks
+// lists the checks that count towards the compliance score (all of them
+// when empty). The default branch passes the access level checks when
+// every role allowed to push or merge is at least MinPushAccessLevel or
+// MinMergeAccessLevel (10 Guest, 20 Reporter, 30 Developer, 40
+// Maintainer; 0 "No one" always passes), and the approval check when at
+// least MinApprovals approvals are required.
+type ComplianceCollectorConfig struct {
+	Enabled             bool     `yaml:"enabled"                json:"enabled"`
+	IntervalSeconds     int      `yaml:"interval_seconds"       json:"interval_seconds"       validate:"omitempty,min=1"`
+	Checks              []string `yaml:"checks"                 json:"checks"                 validate:"omitempty,dive,oneof=default_branch_protected push_access_level merge_access_level force_push_disabled required_approvals pipelines_must_succeed resolve_discussions reset_approvals_on_push delete_source_branch"`
+	MinPushAccessLevel  int      `yaml:"min_push_access_level"  json:"min_push_access_level"  validate:"omitempty,oneof=10 20 30 40 50 60"`
+	MinMergeAccessLevel int      `yaml:"min_merge_access_level" json:"min_merge_access_level" validate:"omitempty,oneof=10 20 30 40 50 60"`
+	MinApprovals        int      `yaml:"min_approvals"          json:"min_approvals"          validate:"min=0"`
+
+	ScheduleConfig `yaml:",inline"`
+}
+
+// Interval returns the collector interval as a time.Duration.
+func (c ComplianceCollectorConfig) Interval() time.Duration {
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// MilestonesCollectorConfig holds milestone collector settings. Active
+// milestones of the tracked projects and of the Groups paths are
+// reported; IncludeIterations adds the current iterations of those groups
+// on Premium instances.
+type MilestonesCollectorConfig struct {
+	Enabled           bool     `yaml:"enabled"            json:"enabled"`
+	IntervalSeconds   int      `yaml:"interval_seconds"   json:"interval_seconds"   validate:"omitempty,min=1"`
+	Groups            []string `yaml:"groups"             json:"groups"`
+	IncludeIterations bool     `yaml:"include_iterations" json:"include_iterations"`
+
+	ScheduleConfig `yaml:",inline"`
+}
+
+// Interval returns the collector interval as a time.Duration.
+func (c MilestonesCollectorConfig) Interval() time.Duration {
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
 // ProjectDefaults holds default settings applied to all projects.
 type ProjectDefaults struct {
 	OutputSparseStatusMetrics bool       `yaml:"output_sparse_status_metrics" json:"output_sparse_status_metrics"`
@@ -215,8 +726,8 @@ type RefsConfig struct {
 type BranchesConfig struct {
 	Enabled        bool   `yaml:"enabled"        json:"enabled"`
 	Regexp         string `yaml:"regexp"         json:"regexp"`
-	MostRecent     int    `yaml:"most_recent"    json:"most_recent"    validate:"omitempty,min=0"`
-	MaxAgeDays     int    `yaml:"max_age_days"   json:"max_age_days"   validate:"omitempty,min=0"`
+	MostRecent     int    `yaml:"most_recent"    json:"most_recent"    validate:"min=0"`
+	MaxAgeDays     int    `yaml:"max_age_days"   json:"max_age_days"   validate:"min=0"`
 	ExcludeDeleted bool   `yaml:"exclude_deleted" json:"exclude_deleted"`
 }
 
@@ -224,8 +735,8 @@ type BranchesConfig struct {
 type TagsConfig struct {
 	Enabled        bool   `yaml:"enabled"        json:"enabled"`
 	Regexp         string `yaml:"regexp"         json:"regexp"`
-	MostRecent     int    `yaml:"most_recent"    json:"most_recent"    validate:"omitempty,min=0"`
-	MaxAgeDays     int    `yaml:"max_age_days"   json:"max_age_days"   validate:"omitempty,min=0"`
+	MostRecent     int    `yaml:"most_recent"    json:"most_recent"    validate:"min=0"`
+	MaxAgeDays     int    `yaml:"max_age_days"   json:"max_age_days"   validate:"min=0"`
 	ExcludeDeleted bool   `yaml:"exclude_deleted" json:"exclude_deleted"`
 }
 
@@ -233,8 +744,8 @@ type TagsConfig struct {
 type MergeRequestsRefConfig struct {
 	Enabled    bool     `yaml:"enabled"      json:"enabled"`
 	States     []string `yaml:"states"       json:"states"`
-	MostRecent int      `yaml:"most_recent"  json:"most_recent"  validate:"omitempty,min=0"`
-	MaxAgeDays int      `yaml:"max_age_days" json:"max_age_days" validate:"omitempty,min=0"`
+	MostRecent int      `yaml:"most_recent"  json:"most_recent"  validate:"min=0"`
+	MaxAgeDays int      `yaml:"max_age_days" json:"max_age_days" validate:"min=0"`
 }
 
 // ProjectConfig represents a single project to monitor.
@@ -259,8 +770,9 @@ type OwnerConfig struct {
 	IncludeSubgroups bool   `yaml:"include_subgroups" json:"include_subgroups"`
 }
 
-// Load reads a YAML configuration file, applies defaults, applies environment
-// variable overrides, and validates the result.
+// Load reads a YAML configuration file, expands ${VAR} and ${VAR:-default}
+// references, applies defaults, applies environment variable overrides,
+// resolves file-sourced secrets, and validates the result.
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -270,12 +782,16 @@ func Load(path string) (*Config, error) {
 	cfg := &Config{}
 	ApplyDefaults(cfg)
 
-	if err := yaml.Unmarshal(data, cfg); err != nil {
+	if err := yaml.Unmarshal(interpolateEnv(data), cfg); err != nil {
 		return nil, fmt.Errorf("parsing config file: %w", err)
 	}
 
 	applyEnvOverrides(cfg)
 
+	if err := resolveSecretFiles(cfg); err != nil {
+		return nil, err
+	}
+
 	if err := Validate(cfg); err != nil {
 		return nil, err
 	}
@@ -399,12 +915,36 @@ func redactString(s string) string {
 	return "****"
 }
 
-// Redacted returns a copy of the Config with sensitive fields masked.
+// redacted returns a copy of the GitLab section with its tokens masked.
+func (g GitLabConfig) redacted() GitLabConfig {
+	g.Token = redactString(g.Token)
+	if len(g.Tokens) > 0 {
+		tokens := make([]TokenConfig, len(g.Tokens))
+		for i, t := range g.Tokens {
+			t.Token = redactString(t.Token)
+			tokens[i] = t
+		}
+		g.Tokens = tokens
+	}
+	return g
+}
+
+// Redacted returns a copy of the Config with sensitive fields masked. Values
+// loaded from *_file secrets are masked as well; the file paths are kept.
 func (c *Config) Redacted() Config {
 	cp := *c
-	cp.GitLab.Token = redactString(cp.GitLab.Token)
+	cp.GitLab = c.GitLab.redacted()
+	if len(c.Instances) > 0 {
+		cp.Instances = make([]InstanceConfig, len(c.Instances))
+		for i, inst := range c.Instances {
+			inst.GitLabConfig = inst.GitLabConfig.redacted()
+			cp.Instances[i] = inst
+		}
+	}
 	cp.Server.Webhook.SecretToken = redactString(cp.Server.Webhook.SecretToken)
 	cp.Redis.URL = redactString(cp.Redis.URL)
+	cp.Redis.Password = redactString(cp.Redis.Password)
+	cp.Redis.SentinelPassword = redactString(cp.Redis.SentinelPassword)
 	return cp
 }
 
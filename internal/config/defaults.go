@@ -10,21 +10,25 @@ func ApplyDefaults(cfg *Config) {
 
 	// --- Server ---
 	cfg.Server.ListenAddress = ":8080"
+	cfg.Server.Readiness.RequireFirstCollection = true
+	cfg.Server.Readiness.GitLabReachableWithinMinutes = 5
+
+	// --- Redis ---
+	cfg.Redis.Mode = "standalone"
+	cfg.Redis.KeyPrefix = "age:"
+	cfg.Redis.PoolSize = 10
+	cfg.Redis.MinIdleConns = 1
+	cfg.Redis.DialTimeoutSeconds = 5
 
 	// --- GitLab ---
-	cfg.GitLab.URL = "https://gitlab.com"
-	cfg.GitLab.EnableTLSVerify = true
-	cfg.GitLab.MaxRequestsPerSecond = 10
-	cfg.GitLab.BurstRequestsPerSecond = 20
-	cfg.GitLab.UseGraphQL = true
-	cfg.GitLab.GraphQLPageSize = 100
-	cfg.GitLab.RESTPageSize = 100
+	cfg.GitLab = defaultGitLabConfig()
 
 	// --- Collectors ---
 
 	// Pipelines
 	cfg.Collectors.Pipelines.Enabled = true
 	cfg.Collectors.Pipelines.IntervalSeconds = 30
+	cfg.Collectors.Pipelines.ScheduleConfig = defaultSchedule(30, 100, "high")
 	cfg.Collectors.Pipelines.IncludeChildPipelines = true
 	cfg.Collectors.Pipelines.HistogramBuckets = []float64{5, 10, 30, 60, 120, 300, 600, 1800, 3600}
 	cfg.Collectors.Pipelines.MaxPipelinesPerRef = 10
@@ -32,43 +36,145 @@ func ApplyDefaults(cfg *Config) {
 	// Jobs
 	cfg.Collectors.Jobs.Enabled = true
 	cfg.Collectors.Jobs.IntervalSeconds = 30
+	cfg.Collectors.Jobs.ScheduleConfig = defaultSchedule(30, 90, "high")
 	cfg.Collectors.Jobs.HistogramBuckets = []float64{5, 10, 30, 60, 120, 300, 600, 1800}
 	cfg.Collectors.Jobs.IncludeRunnerDetails = true
 
 	// Merge Requests
 	cfg.Collectors.MergeRequests.Enabled = true
 	cfg.Collectors.MergeRequests.IntervalSeconds = 120
+	cfg.Collectors.MergeRequests.ScheduleConfig = defaultSchedule(120, 70, "normal")
 	cfg.Collectors.MergeRequests.HistogramBuckets = []float64{3600, 7200, 14400, 28800, 86400, 172800, 604800}
 
 	// Environments
 	cfg.Collectors.Environments.Enabled = false
 	cfg.Collectors.Environments.IntervalSeconds = 300
+	cfg.Collectors.Environments.ScheduleConfig = defaultSchedule(300, 60, "normal")
 	cfg.Collectors.Environments.ExcludeStopped = true
 
 	// Test Reports
 	cfg.Collectors.TestReports.Enabled = false
 	cfg.Collectors.TestReports.IntervalSeconds = 60
+	cfg.Collectors.TestReports.ScheduleConfig = defaultSchedule(60, 50, "low")
 
 	// DORA
 	cfg.Collectors.DORA.Enabled = true
 	cfg.Collectors.DORA.IntervalSeconds = 3600
+	cfg.Collectors.DORA.ScheduleConfig = defaultSchedule(3600, 40, "low")
 	cfg.Collectors.DORA.EnvironmentTiers = []string{"production", "staging"}
 
 	// Value Stream
 	cfg.Collectors.ValueStream.Enabled = true
 	cfg.Collectors.ValueStream.IntervalSeconds = 3600
+	cfg.Collectors.ValueStream.ScheduleConfig = defaultSchedule(3600, 30, "low")
 
 	// Code Review
 	cfg.Collectors.CodeReview.Enabled = true
 	cfg.Collectors.CodeReview.IntervalSeconds = 300
+	cfg.Collectors.CodeReview.ScheduleConfig = defaultSchedule(300, 50, "normal")
 
 	// Repository
 	cfg.Collectors.Repository.Enabled = true
 	cfg.Collectors.Repository.IntervalSeconds = 3600
+	cfg.Collectors.Repository.ScheduleConfig = defaultSchedule(3600, 20, "low")
 
 	// Contributors
 	cfg.Collectors.Contributors.Enabled = false
 	cfg.Collectors.Contributors.IntervalSeconds = 3600
+	cfg.Collectors.Contributors.ScheduleConfig = defaultSchedule(3600, 10, "low")
+
+	// Runners
+	cfg.Collectors.Runners.Enabled = false
+	cfg.Collectors.Runners.IntervalSeconds = 120
+	cfg.Collectors.Runners.ScheduleConfig = defaultSchedule(120, 80, "normal")
+	cfg.Collectors.Runners.InstanceRunners = true
+	cfg.Collectors.Runners.ProjectRunners = true
+
+	// Job queue
+	cfg.Collectors.JobQueue.Enabled = false
+	cfg.Collectors.JobQueue.IntervalSeconds = 60
+	cfg.Collectors.JobQueue.ScheduleConfig = defaultSchedule(60, 85, "high")
+	cfg.Collectors.JobQueue.HistogramBuckets = []float64{5, 10, 30, 60, 120, 300, 600, 1800, 3600}
+	cfg.Collectors.JobQueue.WaitWindowSeconds = 3600
+
+	// Issues
+	cfg.Collectors.Issues.Enabled = false
+	cfg.Collectors.Issues.IntervalSeconds = 300
+	cfg.Collectors.Issues.ScheduleConfig = defaultSchedule(300, 45, "low")
+	cfg.Collectors.Issues.HistogramBuckets = []float64{3600, 86400, 259200, 604800, 1209600, 2592000, 7776000, 15552000, 31536000}
+	cfg.Collectors.Issues.ClosedWindowDays = 30
+	cfg.Collectors.Issues.FullSyncIntervalSeconds = 21600
+
+	// Incidents
+	cfg.Collectors.Incidents.Enabled = false
+	cfg.Collectors.Incidents.IntervalSeconds = 120
+	cfg.Collectors.Incidents.ScheduleConfig = defaultSchedule(120, 65, "normal")
+	cfg.Collectors.Incidents.HistogramBuckets = []float64{60, 300, 900, 1800, 3600, 7200, 14400, 43200, 86400, 259200, 604800}
+	cfg.Collectors.Incidents.SeverityLabelPrefix = "severity::"
+	cfg.Collectors.Incidents.ResolvedWindowDays = 30
+	cfg.Collectors.Incidents.FullSyncIntervalSeconds = 21600
+
+	// Releases
+	cfg.Collectors.Releases.Enabled = false
+	cfg.Collectors.Releases.IntervalSeconds = 900
+	cfg.Collectors.Releases.ScheduleConfig = defaultSchedule(900, 25, "low")
+	cfg.Collectors.Releases.HistogramBuckets = []float64{86400, 259200, 604800, 1209600, 2592000, 5184000, 7776000, 15552000}
+	cfg.Collectors.Releases.IncludeChanges = true
+
+	// Container registry
+	cfg.Collectors.Registry.Enabled = false
+	cfg.Collectors.Registry.IntervalSeconds = 3600
+	cfg.Collectors.Registry.ScheduleConfig = defaultSchedule(3600, 15, "low")
+	cfg.Collectors.Registry.MaxTagsPerRepository = 100
+	cfg.Collectors.Registry.TagDetailsPerSecond = 5
+
+	// Packages
+	cfg.Collectors.Packages.Enabled = false
+	cfg.Collectors.Packages.IntervalSeconds = 1800
+	cfg.Collectors.Packages.ScheduleConfig = defaultSchedule(1800, 15, "low")
+	cfg.Collectors.Packages.IncludeFiles = true
+
+	// Vulnerabilities
+	cfg.Collectors.Vulnerabilities.Enabled = false
+	cfg.Collectors.Vulnerabilities.IntervalSeconds = 3600
+	cfg.Collectors.Vulnerabilities.ScheduleConfig = defaultSchedule(3600, 20, "low")
+	cfg.Collectors.Vulnerabilities.ResolvedWindowDays = 90
+
+	// Pipeline schedules
+	cfg.Collectors.PipelineSchedules.Enabled = false
+	cfg.Collectors.PipelineSchedules.IntervalSeconds = 600
+	cfg.Collectors.PipelineSchedules.ScheduleConfig = defaultSchedule(600, 35, "low")
+
+	// Merge trains
+	cfg.Collectors.MergeTrains.Enabled = false
+	cfg.Collectors.MergeTrains.IntervalSeconds = 120
+	cfg.Collectors.MergeTrains.ScheduleConfig = defaultSchedule(120, 60, "normal")
+	cfg.Collectors.MergeTrains.HistogramBuckets = []float64{300, 600, 900, 1800, 3600, 7200, 14400, 28800}
+	cfg.Collectors.MergeTrains.WindowHours = 24
+
+	// Compliance
+	cfg.Collectors.Compliance.Enabled = false
+	cfg.Collectors.Compliance.IntervalSeconds = 3600
+	cfg.Collectors.Compliance.ScheduleConfig = defaultSchedule(3600, 10, "low")
+	cfg.Collectors.Compliance.MinPushAccessLevel = 40
+	cfg.Collectors.Compliance.MinMergeAccessLevel = 30
+	cfg.Collectors.Compliance.MinApprovals = 1
+
+	// Milestones
+	cfg.Collectors.Milestones.Enabled = false
+	cfg.Collectors.Milestones.IntervalSeconds = 900
+	cfg.Collectors.Milestones.ScheduleConfig = defaultSchedule(900, 30, "low")
+	cfg.Collectors.Milestones.IncludeIterations = true
+
+	// Adaptive intervals
+	cfg.Collectors.Adaptive.Enabled = false
+	cfg.Collectors.Adaptive.TargetBudgetPercent = 80
+	cfg.Collectors.Adaptive.MaxStretch = 8
+	cfg.Collectors.Adaptive.EvaluationIntervalSeconds = 30
+
+	// --- Reload ---
+	cfg.Reload.WatchFile = true
+	cfg.Reload.PollIntervalSeconds = 10
 
 	// --- Project Defaults ---
 	cfg.Defaults.OutputSparseStatusMetrics = true
@@ -91,3 +197,36 @@ func ApplyDefaults(cfg *Config) {
 	cfg.Defaults.Refs.MergeRequests.MostRecent = 20
 	cfg.Defaults.Refs.MergeRequests.MaxAgeDays = 30
 }
+
+// defaultGitLabConfig returns the default GitLab connection settings, shared
+// by the top-level gitlab section and every entry under instances.
+func defaultGitLabConfig() GitLabConfig {
+	return GitLabConfig{
+		URL:                    "https://gitlab.com",
+		EnableTLSVerify:        true,
+		MaxRequestsPerSecond:   10,
+		BurstRequestsPerSecond: 20,
+		UseGraphQL:             true,
+		GraphQLPageSize:        100,
+		RESTPageSize:           100,
+		PriorityClasses: []PriorityClassConfig{
+			{Name: "high", Weight: 4},
+			{Name: "normal", Weight: 2},
+			{Name: "low", Weight: 1},
+		},
+	}
+}
+
+// defaultSchedule jitters every run by up to a tenth of the collector
+// interval, staggers projects across the cycle, bounds a run to five
+// intervals and skips runs that come due while the previous one executes.
+func defaultSchedule(intervalSeconds, priority int, priorityClass string) ScheduleConfig {
+	return ScheduleConfig{
+		JitterSeconds:   intervalSeconds / 10,
+		StaggerProjects: true,
+		TimeoutSeconds:  5 * intervalSeconds,
+		Overlap:         "skip",
+		Priority:        priority,
+		PriorityClass:   priorityClass,
+	}
+}
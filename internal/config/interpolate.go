@@ -0,0 +1,30 @@
+package config
+
+import (
+	"os"
+	"regexp"
+)
+
+// interpolationPattern matches ${VAR} and ${VAR:-default} references. A
+// leading "$$" escapes the reference so it is emitted literally.
+var interpolationPattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// interpolateEnv expands ${VAR} and ${VAR:-default} references in raw YAML
+// using the process environment. ${VAR} expands to an empty string when VAR
+// is unset; ${VAR:-default} expands to default when VAR is unset or empty.
+// $${VAR} is left in place as the literal text ${VAR}.
+func interpolateEnv(data []byte) []byte {
+	return interpolationPattern.ReplaceAllFunc(data, func(match []byte) []byte {
+		if len(match) > 1 && match[0] == '$' && match[1] == '$' {
+			return match[1:]
+		}
+
+		groups := interpolationPattern.FindSubmatch(match)
+		name := string(groups[1])
+		val, ok := os.LookupEnv(name)
+		if (!ok || val == "") && groups[2] != nil {
+			return groups[2]
+		}
+		return []byte(val)
+	})
+}
@@ -0,0 +1,33 @@
+package config
+
+import "testing"
+
+func TestInterpolateEnv(t *testing.T) {
+	t.Setenv("AGE_TEST_TOKEN", "secret")
+	t.Setenv("AGE_TEST_EMPTY", "")
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"set variable", "token: ${AGE_TEST_TOKEN}", "token: secret"},
+		{"unset variable", "token: ${AGE_TEST_UNSET}", "token: "},
+		{"default for unset", "url: ${AGE_TEST_UNSET:-https://gitlab.com}", "url: https://gitlab.com"},
+		{"default for empty", "url: ${AGE_TEST_EMPTY:-https://gitlab.com}", "url: https://gitlab.com"},
+		{"default ignored when set", "token: ${AGE_TEST_TOKEN:-fallback}", "token: secret"},
+		{"empty default", "token: ${AGE_TEST_UNSET:-}", "token: "},
+		{"escaped reference", "script: $${AGE_TEST_TOKEN}", "script: ${AGE_TEST_TOKEN}"},
+		{"escaped reference with default", "script: $${AGE_TEST_UNSET:-x}", "script: ${AGE_TEST_UNSET:-x}"},
+		{"escape next to a reference", "$${AGE_TEST_TOKEN}${AGE_TEST_TOKEN}", "${AGE_TEST_TOKEN}secret"},
+		{"bare dollar untouched", "price: $5 and $AGE_TEST_TOKEN", "price: $5 and $AGE_TEST_TOKEN"},
+		{"invalid name untouched", "x: ${1ABC}", "x: ${1ABC}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(interpolateEnv([]byte(tt.in))); got != tt.want {
+				t.Errorf("interpolateEnv(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ReadSecretFile reads a secret from disk, trimming surrounding whitespace
+// (Kubernetes secret mounts frequently end in a newline).
+func ReadSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// secretRef points at a secret value and the file it may be read from.
+type secretRef struct {
+	name string
+	path string
+	dest *string
+}
+
+// gitlabSecrets returns the token references of a GitLab connection section.
+func gitlabSecrets(prefix string, g *GitLabConfig) []secretRef {
+	refs := []secretRef{{prefix + ".token_file", g.TokenFile, &g.Token}}
+	for i := range g.Tokens {
+		t := &g.Tokens[i]
+		refs = append(refs, secretRef{fmt.Sprintf("%s.tokens[%s].token_file", prefix, t.Name), t.TokenFile, &t.Token})
+	}
+	return refs
+}
+
+// resolveSecretFiles replaces secret values with the contents of their
+// *_file counterparts. File-sourced values take precedence over literal
+// values and environment overrides. Because Load calls this on every
+// invocation, a configuration reload picks up rotated secrets.
+func resolveSecretFiles(cfg *Config) error {
+	secrets := gitlabSecrets("gitlab", &cfg.GitLab)
+	secrets = append(secrets,
+		secretRef{"server.webhook.secret_token_file", cfg.Server.Webhook.SecretTokenFile, &cfg.Server.Webhook.SecretToken},
+		secretRef{"redis.url_file", cfg.Redis.URLFile, &cfg.Redis.URL},
+	)
+	for i := range cfg.Instances {
+		inst := &cfg.Instances[i]
+		secrets = append(secrets, gitlabSecrets(fmt.Sprintf("instances[%s]", inst.Name), &inst.GitLabConfig)...)
+	}
+
+	for _, s := range secrets {
+		if s.path == "" {
+			continue
+		}
+		val, err := ReadSecretFile(s.path)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", s.name, err)
+		}
+		if val == "" {
+			return fmt.Errorf("reading %s: file %s is empty", s.name, s.path)
+		}
+		*s.dest = val
+	}
+
+	return nil
+}
+
+// SecretFiles returns the paths of all file-sourced secrets referenced by
+// the configuration, so they can be watched for rotation.
+func (c *Config) SecretFiles() []string {
+	refs := gitlabSecrets("gitlab", &c.GitLab)
+	for i := range c.Instances {
+		refs = append(refs, gitlabSecrets("", &c.Instances[i].GitLabConfig)...)
+	}
+
+	var paths []string
+	for _, r := range refs {
+		if r.path != "" {
+			paths = append(paths, r.path)
+		}
+	}
+	for _, p := range []string{
+		c.Server.Webhook.SecretTokenFile,
+		c.Redis.URLFile,
+		c.Redis.UsernameFile,
+		c.Redis.PasswordFile,
+	} {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
@@ -2,16 +2,121 @@ package config
 
 import (
 	"fmt"
+	"regexp"
 
 	"github.com/go-playground/validator/v10"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/scheduler"
 )
 
 // Validate validates the configuration using struct tags registered with
-// the go-playground/validator library.
+// the go-playground/validator library, then checks the GitLab instances.
 func Validate(cfg *Config) error {
 	v := validator.New()
 	if err := v.Struct(cfg); err != nil {
 		return fmt.Errorf("config validation failed: %w", err)
 	}
+	if err := validateInstances(cfg); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+	if err := validateSchedules(cfg.Collectors); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+	if err := validateTagFilters(cfg); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+	return nil
+}
+
+// validateTagFilters checks that the refs.tags patterns of the project
+// defaults and of every explicitly listed project compile.
+func validateTagFilters(cfg *Config) error {
+	if _, err := regexp.Compile(cfg.Defaults.Refs.Tags.Regexp); err != nil {
+		return fmt.Errorf("defaults.refs.tags.regexp: %w", err)
+	}
+	for _, inst := range cfg.GitLabInstances() {
+		for name, tags := range inst.ProjectTags() {
+			if _, err := regexp.Compile(tags.Regexp); err != nil {
+				return fmt.Errorf("gitlab instance %q: project %q: refs.tags.regexp: %w", inst.Name, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateSchedules checks that every collector cron schedule parses.
+func validateSchedules(c CollectorsConfig) error {
+	schedules := map[string]string{
+		"pipelines":          c.Pipelines.Schedule,
+		"jobs":               c.Jobs.Schedule,
+		"merge_requests":     c.MergeRequests.Schedule,
+		"environments":       c.Environments.Schedule,
+		"test_reports":       c.TestReports.Schedule,
+		"dora":               c.DORA.Schedule,
+		"value_stream":       c.ValueStream.Schedule,
+		"code_review":        c.CodeReview.Schedule,
+		"repository":         c.Repository.Schedule,
+		"contributors":       c.Contributors.Schedule,
+		"runners":            c.Runners.Schedule,
+		"job_queue":          c.JobQueue.Schedule,
+		"issues":             c.Issues.Schedule,
+		"incidents":          c.Incidents.Schedule,
+		"releases":           c.Releases.Schedule,
+		"container_registry": c.Registry.Schedule,
+		"packages":           c.Packages.Schedule,
+		"vulnerabilities":    c.Vulnerabilities.Schedule,
+		"pipeline_schedules": c.PipelineSchedules.Schedule,
+		"merge_trains":       c.MergeTrains.Schedule,
+		"compliance":         c.Compliance.Schedule,
+		"milestones":         c.Milestones.Schedule,
+	}
+	for name, expr := range schedules {
+		if expr == "" {
+			continue
+		}
+		if _, err := scheduler.ParseCron(expr); err != nil {
+			return fmt.Errorf("collectors.%s.schedule: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// validateInstances checks that every GitLab instance has at least one
+// token, that pooled tokens are set, that names are unique, and that
+// endpoint limit patterns compile.
+func validateInstances(cfg *Config) error {
+	seen := make(map[string]struct{})
+	for _, inst := range cfg.GitLabInstances() {
+		if _, dup := seen[inst.Name]; dup {
+			return fmt.Errorf("duplicate gitlab instance name %q", inst.Name)
+		}
+		seen[inst.Name] = struct{}{}
+		if inst.Token == "" && len(inst.Tokens) == 0 {
+			return fmt.Errorf("gitlab instance %q: token is required", inst.Name)
+		}
+		tokens := make(map[string]struct{})
+		for _, t := range inst.Tokens {
+			if _, dup := tokens[t.Name]; dup {
+				return fmt.Errorf("gitlab instance %q: duplicate token name %q", inst.Name, t.Name)
+			}
+			tokens[t.Name] = struct{}{}
+			if t.Name == "default" {
+				return fmt.Errorf("gitlab instance %q: token name %q is reserved for the main token", inst.Name, t.Name)
+			}
+			if t.Token == "" {
+				return fmt.Errorf("gitlab instance %q: token %q requires token or token_file", inst.Name, t.Name)
+			}
+		}
+		endpoints := make(map[string]struct{})
+		for _, l := range inst.EndpointLimits {
+			if _, dup := endpoints[l.Name]; dup {
+				return fmt.Errorf("gitlab instance %q: duplicate endpoint limit name %q", inst.Name, l.Name)
+			}
+			endpoints[l.Name] = struct{}{}
+			if _, err := regexp.Compile(l.PathRegexp); err != nil {
+				return fmt.Errorf("gitlab instance %q: endpoint limit %q: %w", inst.Name, l.Name, err)
+			}
+		}
+	}
 	return nil
 }
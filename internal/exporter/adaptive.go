@@ -0,0 +1,147 @@
+package exporter
+
+import (
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+)
+
+const (
+	// lowBudgetDivisor marks the remaining budget as low once less than
+	// 1/lowBudgetDivisor of the limit is left.
+	lowBudgetDivisor = 10
+	// shrinkMargin is the share of the target budget the projected demand
+	// must stay under before an interval is shrunk, so that intervals do
+	// not flap around the target.
+	shrinkMargin = 0.8
+)
+
+// adaptIntervals stretches or shrinks one collector interval of inst per
+// evaluation, based on the GitLab requests its collectors made in their
+// last cycle and the rate-limit budget (see config.AdaptiveConfig).
+// Evaluations are skipped while a reload holds e.mu.
+func (e *Exporter) adaptIntervals(inst *instance) {
+	if !e.mu.TryLock() {
+		return
+	}
+	defer e.mu.Unlock()
+
+	cfg := e.config.Collectors.Adaptive
+
+	type candidate struct {
+		name string
+		ac   *activeCollector
+	}
+	var candidates []candidate
+	for name, ac := range inst.active {
+		// Cron-scheduled collectors keep their schedule.
+		if ac.task != nil && ac.task.Schedule == nil {
+			candidates = append(candidates, candidate{name, ac})
+		}
+	}
+
+	if !cfg.Enabled {
+		for _, c := range candidates {
+			inst.setStretch(c.name, c.ac, 1)
+		}
+		return
+	}
+
+	budget := inst.client.Budget()
+	if budget.Limit <= 0 {
+		return
+	}
+
+	// Lowest priority first.
+	sort.Slice(candidates, func(i, j int) bool {
+		pi, pj := candidates[i].ac.schedule.Priority, candidates[j].ac.schedule.Priority
+		if pi != pj {
+			return pi < pj
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	capacity := float64(budget.Limit) / gitlabclient.RateLimitWindow.Seconds() *
+		float64(cfg.TargetBudgetPercent) / 100
+	var demand float64
+	for _, c := range candidates {
+		if c.ac.stretch > cfg.MaxStretch {
+			inst.setStretch(c.name, c.ac, cfg.MaxStretch)
+		}
+		demand += c.ac.demand(c.ac.stretch)
+	}
+
+	log := inst.logger.WithFields(logrus.Fields{
+		"demand_rps":   demand,
+		"capacity_rps": capacity,
+		"remaining":    budget.Remaining,
+	})
+
+	if demand > capacity || budget.Remaining*lowBudgetDivisor < budget.Limit {
+		for _, c := range candidates {
+			if c.ac.stretch >= cfg.MaxStretch {
+				continue
+			}
+			stretch := min(c.ac.stretch*2, cfg.MaxStretch)
+			inst.setStretch(c.name, c.ac, stretch)
+			log.WithFields(logrus.Fields{
+				"collector": c.name,
+				"interval":  c.ac.interval * time.Duration(stretch),
+			}).Info("API budget short, stretching collector interval")
+			return
+		}
+		return
+	}
+
+	// Headroom: shrink the highest-priority stretched collector, provided
+	// the projected demand still fits.
+	for i := len(candidates) - 1; i >= 0; i-- {
+		c := candidates[i]
+		if c.ac.stretch <= 1 {
+			continue
+		}
+		stretch := c.ac.stretch / 2
+		projected := demand - c.ac.demand(c.ac.stretch) + c.ac.demand(stretch)
+		if projected <= capacity*shrinkMargin {
+			inst.setStretch(c.name, c.ac, stretch)
+			log.WithFields(logrus.Fields{
+				"collector": c.name,
+				"interval":  c.ac.interval * time.Duration(stretch),
+			}).Info("API budget recovered, shrinking collector interval")
+		}
+		return
+	}
+}
+
+// demand returns the requests per second the collector makes when run at
+// stretch times its configured interval.
+func (ac *activeCollector) demand(stretch int) float64 {
+	period := (ac.interval * time.Duration(stretch)).Seconds()
+	if period <= 0 {
+		return 0
+	}
+	return float64(ac.requests.Load()) / period
+}
+
+// setStretch applies an adaptive interval multiplier to a collector and
+// exports its effective interval.
+func (inst *instance) setStretch(name string, ac *activeCollector, stretch int) {
+	if stretch < 1 {
+		stretch = 1
+	}
+	effective := ac.interval * time.Duration(stretch)
+	if ac.stretch != stretch {
+		ac.stretch = stretch
+		if ac.task != nil {
+			ac.task.SetInterval(effective)
+		}
+	}
+	if ac.task != nil && ac.task.Schedule != nil {
+		collectorInterval.DeleteLabelValues(inst.name, name)
+		return
+	}
+	collectorInterval.WithLabelValues(inst.name, name).Set(effective.Seconds())
+}
@@ -5,11 +5,13 @@ package exporter
 import (
 	"context"
 	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
-	gitlab "gitlab.com/gitlab-org/api/client-go"
 
 	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/collector"
 	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
@@ -21,18 +23,34 @@ import (
 
 // Operational metrics.
 var (
-	projectsTracked = prometheus.NewGauge(prometheus.GaugeOpts{
+	projectsTracked = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "age_projects_tracked",
 		Help: "Number of GitLab projects being monitored.",
-	})
-	gitlabTier = prometheus.NewGauge(prometheus.GaugeOpts{
+	}, []string{"instance"})
+	startupPhase = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "age_startup_phase",
+		Help: "Startup progress of a GitLab instance (0=waiting to connect, 1=detecting tier, 2=discovering projects, 3=auditing permissions, 4=running).",
+	}, []string{"instance"})
+	gitlabTier = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "age_gitlab_tier",
 		Help: "Detected GitLab tier (0=Free, 1=Premium, 2=Ultimate).",
-	})
+	}, []string{"instance"})
 	collectorEnabled = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "age_collector_enabled",
 		Help: "Whether a collector is enabled (1) or disabled (0).",
-	}, []string{"collector_type"})
+	}, []string{"instance", "collector_type"})
+	tokenExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "age_token_expiry_timestamp",
+		Help: "Expiry date of a pooled GitLab access token (unix epoch seconds); absent for tokens that never expire.",
+	}, []string{"instance", "token"})
+	collectorInterval = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "age_collector_effective_interval_seconds",
+		Help: "Interval a collector currently runs at, including any adaptive stretching.",
+	}, []string{"instance", "collector_type"})
+	permissionOK = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "age_permission_ok",
+		Help: "Whether the startup permission audit found the token able to serve a collector on a project (1) or not (0).",
+	}, []string{"instance", "collector", "project"})
 	apiRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "age_api_requests_total",
 		Help: "Total GitLab API requests made.",
@@ -42,118 +60,182 @@ var (
 		Help:    "Duration of GitLab API requests.",
 		Buckets: prometheus.DefBuckets,
 	}, []string{"method", "endpoint"})
+	configReloadSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "age_config_reload_success",
+		Help: "Whether the last configuration reload attempt succeeded (1) or was rejected (0).",
+	})
+	configReloadTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "age_config_reload_success_timestamp_seconds",
+		Help: "Timestamp of the last successful configuration reload (unix epoch seconds).",
+	})
+)
+
+// Startup phases reported by age_startup_phase.
+const (
+	phaseWaiting = iota
+	phaseDetectingTier
+	phaseDiscoveringProjects
+	phaseAuditingPermissions
+	phaseRunning
+)
+
+// Startup retry backoff bounds.
+const (
+	startupBackoffMin = time.Second
+	startupBackoffMax = 2 * time.Minute
 )
 
 func init() {
 	prometheus.MustRegister(
 		projectsTracked,
+		startupPhase,
 		gitlabTier,
 		collectorEnabled,
+		collectorInterval,
+		tokenExpiry,
+		permissionOK,
 		apiRequestsTotal,
 		apiRequestDuration,
+		configReloadSuccess,
+		configReloadTimestamp,
 	)
 }
 
 // Exporter is the main application orchestrator.
 type Exporter struct {
 	config    *config.Config
-	client    *gitlabclient.Client
-	registry  *collector.Registry
 	scheduler *scheduler.Scheduler
 	server    *server.Server
 	store     store.Store
 	logger    *logrus.Entry
+
+	// mu guards config, store and instances during live reloads.
+	mu        sync.Mutex
+	instances []*instance
+
+	// connecting holds the instances that are still being brought up,
+	// keyed by name. Guarded by mu.
+	connecting map[string]*pendingInstance
+
+	// reloadMu serializes reloads, which prepare their changes without
+	// holding mu.
+	reloadMu sync.Mutex
+
+	startScheduler sync.Once
+
+	// phases tracks the startup phase of every configured instance.
+	phaseMu sync.Mutex
+	phases  map[string]int
+
+	// rateLimits exports the rate limiter queues of the instances.
+	rateLimits *rateLimitMetrics
+
+	// Readiness state (see readiness.go).
+	collected     atomic.Bool
+	readyMu       sync.Mutex
+	lastReadiness []server.Condition
+
+	// Live reload wiring (see WatchConfig).
+	configPath string
+	loader     ConfigLoader
+	reloadCh   <-chan os.Signal
+}
+
+// activeCollector tracks a registered collector together with the settings
+// it was created from, so reloads can tell what changed.
+type activeCollector struct {
+	collector collector.Collector
+	interval  time.Duration
+	schedule  config.ScheduleConfig
+	settings  interface{}
+	task      *scheduler.Task
+	// stretch is the adaptive interval multiplier (see adaptIntervals).
+	stretch int
+	// cycles counts finished collection cycles, failed ones included.
+	cycles atomic.Int64
+	// requests is the number of GitLab requests of the last cycle.
+	requests atomic.Int64
 }
 
-// NewExporter creates and initialises the exporter:
-//  1. Creates the GitLab client.
-//  2. Creates the store (Redis if configured, otherwise in-memory).
-//  3. Runs tier detection.
-//  4. Discovers projects.
-//  5. Creates and registers collectors.
-//  6. Creates the scheduler and HTTP server.
+// run executes one collection cycle in the collector's priority class,
+// counting it and recording the GitLab requests it made.
+func (ac *activeCollector) run(ctx context.Context) error {
+	var requests atomic.Int64
+	ctx = gitlabclient.WithPriorityClass(ctx, ac.schedule.PriorityClass)
+	err := ac.collector.Run(gitlabclient.WithRequestCounter(ctx, &requests))
+	ac.cycles.Add(1)
+	if err == nil {
+		ac.requests.Store(requests.Load())
+	} else if n := requests.Load(); n > ac.requests.Load() {
+		// An incomplete cycle made at least this many requests.
+		ac.requests.Store(n)
+	}
+	return err
+}
+
+// NewExporter creates the exporter without contacting GitLab:
+//  1. Creates the store (Redis if configured, otherwise in-memory).
+//  2. Creates the scheduler and HTTP server.
+//
+// GitLab instances are brought up by Run in the background, so an
+// unreachable GitLab does not prevent the process from starting.
 func NewExporter(cfg *config.Config, logger *logrus.Entry) (*Exporter, error) {
 	log := logger.WithField("component", "exporter")
 
-	// --- 1. GitLab client ---
-	client, err := gitlabclient.New(
-		cfg.GitLab.URL,
-		cfg.GitLab.Token,
-		cfg.GitLab.MaxRequestsPerSecond,
-		cfg.GitLab.BurstRequestsPerSecond,
-		cfg.GitLab.UseGraphQL,
-		log,
-	)
+	// --- 1. Store ---
+	st, err := newStore(cfg.Redis, log)
 	if err != nil {
-		return nil, fmt.Errorf("creating gitlab client: %w", err)
+		return nil, err
 	}
 
-	// --- 2. Store ---
-	var st store.Store
-	if cfg.Redis.URL != "" {
-		rs, err := store.NewRedisStore(cfg.Redis.URL)
-		if err != nil {
-			return nil, fmt.Errorf("creating redis store: %w", err)
-		}
-		st = rs
-		log.Info("using Redis store")
-	} else {
-		st = store.NewMemoryStore()
-		log.Info("using in-memory store")
-	}
-
-	// --- 3. Tier detection ---
-	features := client.Features()
-	if features != nil {
-		gitlabTier.Set(float64(features.Tier))
-		log.WithField("tier", features.Tier).Info("gitlab tier detected")
+	// --- 2. Scheduler and HTTP server ---
+	e := &Exporter{
+		config:     cfg,
+		scheduler:  scheduler.NewScheduler(log),
+		server:     server.NewServer(cfg, log),
+		store:      st,
+		logger:     log,
+		connecting: make(map[string]*pendingInstance),
+		phases:     make(map[string]int),
+		rateLimits: newRateLimitMetrics(),
+
+		lastReadiness: notEvaluated,
 	}
+	e.server.HandleDiagnostic("permissions", e.permissionReport)
+	e.server.HandleDiagnostic("tasks", e.taskReport)
+	prometheus.MustRegister(newTaskMetrics(e.scheduler), e.rateLimits)
 
-	// --- 4. Discover projects ---
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
+	e.server.SetReadinessCheck(e.readinessConditions)
 
-	projects, err := discoverProjects(ctx, cfg, client, log)
-	if err != nil {
-		return nil, fmt.Errorf("discovering projects: %w", err)
+	for _, ic := range cfg.GitLabInstances() {
+		e.setPhase(ic.Name, phaseWaiting)
 	}
-	projectsTracked.Set(float64(len(projects)))
-	log.WithField("count", len(projects)).Info("projects discovered")
-
-	// --- 5. Create and register collectors ---
-	registry := collector.NewRegistry(log)
-	sched := scheduler.NewScheduler(log)
-
-	registerCollectors(cfg, client, registry, sched, features, projects, log)
-
-	// --- 6. HTTP server ---
-	srv := server.NewServer(cfg, registry, log)
-
-	return &Exporter{
-		config:    cfg,
-		client:    client,
-		registry:  registry,
-		scheduler: sched,
-		server:    srv,
-		store:     st,
-		logger:    log,
-	}, nil
+	configReloadSuccess.Set(1)
+
+	return e, nil
 }
 
-// Run starts the scheduler and HTTP server, then blocks until ctx is
-// cancelled. On cancellation it performs a graceful shutdown.
+// Run starts the HTTP server right away, with /health answering and /ready
+// and /startup reporting not ready until the readiness policy is met, then
+// brings the GitLab instances up in the background (see bootstrap). Live
+// reloads are watched from the start, so a reload can fix the settings of
+// an instance that never comes up. It blocks until ctx is cancelled and
+// then performs a graceful shutdown.
 func (e *Exporter) Run(ctx context.Context) error {
-	// Start scheduler.
-	e.scheduler.Start(ctx)
-
 	// Start HTTP server.
 	if err := e.server.Start(ctx); err != nil {
 		return fmt.Errorf("starting server: %w", err)
 	}
-
-	// Mark ready.
+	// This only marks the listener as serving: /ready still requires every
+	// readiness condition, which report not ready until instances are up.
 	e.server.SetReady(true)
-	e.logger.Info("exporter is ready")
+
+	// Watch for configuration reloads.
+	if e.loader != nil {
+		go e.reloadLoop(ctx)
+	}
+
+	e.bootstrap(ctx)
 
 	// Block until context is cancelled.
 	<-ctx.Done()
@@ -172,85 +254,170 @@ func (e *Exporter) Run(ctx context.Context) error {
 
 	e.scheduler.Stop()
 
-	if err := e.store.Close(); err != nil {
+	e.mu.Lock()
+	st := e.store
+	e.mu.Unlock()
+	if err := st.Close(); err != nil {
 		e.logger.WithError(err).Error("error closing store")
 	}
 
 	return nil
 }
 
-// discoverProjects builds the list of project paths from the explicit list
-// and from wildcard expansion via the GitLab API.
-func discoverProjects(ctx context.Context, cfg *config.Config, client *gitlabclient.Client, logger *logrus.Entry) ([]string, error) {
-	seen := make(map[string]struct{})
-	var projects []string
-
-	// Explicit projects.
-	for _, p := range cfg.Projects {
-		if _, ok := seen[p.Name]; !ok {
-			seen[p.Name] = struct{}{}
-			projects = append(projects, p.Name)
+// bootstrap connects every configured GitLab instance concurrently (see
+// connect). It returns immediately.
+func (e *Exporter) bootstrap(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, ic := range e.config.GitLabInstances() {
+		e.connect(ctx, ic)
+	}
+}
+
+// pendingInstance is an instance that is still being brought up.
+type pendingInstance struct {
+	cfg    config.InstanceConfig
+	cancel context.CancelFunc
+}
+
+// connect brings ic up in the background: tier detection, project discovery
+// and the permission audit are retried with exponential backoff until they
+// succeed; the instance's collectors are then scheduled, and the scheduler
+// is started with the first instance that comes up. A reload that changes
+// or removes ic in the meantime cancels the attempt (see Reload). e.mu must
+// be held.
+func (e *Exporter) connect(ctx context.Context, ic config.InstanceConfig) {
+	connCtx, cancel := context.WithCancel(ctx)
+	p := &pendingInstance{cfg: ic, cancel: cancel}
+	e.connecting[ic.Name] = p
+	e.setPhase(ic.Name, phaseWaiting)
+
+	go func() {
+		defer cancel()
+		inst := e.connectInstance(connCtx, ic)
+		if inst == nil {
+			return
+		}
+
+		e.mu.Lock()
+		if e.connecting[ic.Name] != p {
+			// Superseded by a reload while connecting.
+			e.mu.Unlock()
+			return
+		}
+		delete(e.connecting, ic.Name)
+		e.addInstance(inst, e.config)
+		remaining := len(e.connecting)
+		e.mu.Unlock()
+
+		e.startScheduler.Do(func() { e.scheduler.Start(ctx) })
+		if remaining == 0 {
+			e.logger.Info("all gitlab instances are running")
 		}
+	}()
+}
+
+// cancelConnect abandons the pending connection of the named instance.
+// e.mu must be held.
+func (e *Exporter) cancelConnect(name string) {
+	if p, ok := e.connecting[name]; ok {
+		p.cancel()
+		delete(e.connecting, name)
+		e.deletePhase(name)
 	}
+}
 
-	// Wildcard expansion.
-	for _, wc := range cfg.Wildcards {
-		opts := &gitlab.ListProjectsOptions{
-			Search:      gitlab.Ptr(wc.Search),
-			Archived:    gitlab.Ptr(wc.Archived),
-			ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1},
+// connectInstance retries newInstance with exponential backoff until it
+// succeeds or ctx is cancelled, in which case it returns nil.
+func (e *Exporter) connectInstance(ctx context.Context, ic config.InstanceConfig) *instance {
+	backoff := startupBackoffMin
+	progress := func(phase int) {
+		if ctx.Err() == nil {
+			e.setPhase(ic.Name, phase)
 		}
-		for {
-			projs, resp, err := client.REST().Projects.ListProjects(opts, gitlab.WithContext(ctx))
-			if err != nil {
-				logger.WithError(err).WithField("owner", wc.Owner.Name).
-					Warn("failed to expand wildcard, skipping")
-				break
-			}
-			for _, p := range projs {
-				path := p.PathWithNamespace
-				if _, ok := seen[path]; !ok {
-					seen[path] = struct{}{}
-					projects = append(projects, path)
-				}
-			}
-			if resp.NextPage == 0 {
-				break
-			}
-			opts.Page = resp.NextPage
+	}
+	for attempt := 1; ; attempt++ {
+		inst, err := e.newInstance(ctx, ic, progress)
+		if err == nil {
+			return inst
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		progress(phaseWaiting)
+		e.logger.WithError(err).WithFields(logrus.Fields{
+			"instance": ic.Name,
+			"attempt":  attempt,
+			"retry_in": backoff,
+		}).Warn("gitlab instance not available yet, retrying")
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
 		}
+		backoff *= 2
+		if backoff > startupBackoffMax {
+			backoff = startupBackoffMax
+		}
+	}
+}
+
+// newStore creates the Redis store if Redis is configured, otherwise an
+// in-memory store.
+func newStore(cfg config.RedisConfig, logger *logrus.Entry) (store.Store, error) {
+	if !cfg.Enabled() {
+		logger.Info("using in-memory store")
+		return store.NewMemoryStore(), nil
+	}
+	rs, err := store.NewRedisStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating redis store: %w", err)
 	}
+	logger.WithField("mode", cfg.Mode).Info("using Redis store")
+	return rs, nil
+}
 
-	if len(projects) == 0 {
-		return nil, fmt.Errorf("no projects configured or discovered")
+// sharedLimiter returns the Redis-backed rate limiter shared with other
+// processes when cfg enables it and the store is Redis, otherwise nil.
+// e.mu must be held.
+func (e *Exporter) sharedLimiter(cfg config.RedisConfig) gitlabclient.SharedLimiter {
+	rs, ok := e.store.(*store.RedisStore)
+	if !ok || !cfg.SharedRateLimit {
+		return nil
 	}
+	return store.NewRedisRateLimiter(rs)
+}
 
-	return projects, nil
+// collectorDef describes how to build one collector from configuration.
+type collectorDef struct {
+	name     string
+	enabled  bool
+	interval time.Duration
+	schedule config.ScheduleConfig
+	// settings is the collector-specific configuration section; a change
+	// in it other than to the interval or schedule forces the collector to
+	// be re-created on reload.
+	settings interface{}
+	create   func() collector.Collector
 }
 
-// registerCollectors creates all enabled collectors, registers them with the
-// registry, and adds a scheduler task for each.
-func registerCollectors(
+// collectorDefs returns the definitions of every known collector.
+func collectorDefs(
 	cfg *config.Config,
+	ic config.InstanceConfig,
 	client *gitlabclient.Client,
-	registry *collector.Registry,
-	sched *scheduler.Scheduler,
 	features *gitlabclient.DetectedFeatures,
 	projects []string,
-	logger *logrus.Entry,
-) {
-	type collectorDef struct {
-		name     string
-		enabled  bool
-		interval time.Duration
-		create   func() collector.Collector
-	}
-
-	defs := []collectorDef{
+) []collectorDef {
+	return []collectorDef{
 		{
 			name:     "pipelines",
 			enabled:  cfg.Collectors.Pipelines.Enabled,
-			interval: time.Duration(cfg.Collectors.Pipelines.IntervalSeconds) * time.Second,
+			interval: cfg.Collectors.Pipelines.Interval(),
+			schedule: cfg.Collectors.Pipelines.ScheduleConfig,
+			settings: cfg.Collectors.Pipelines,
 			create: func() collector.Collector {
 				return collector.NewPipelinesCollector(client, cfg.Collectors.Pipelines, projects)
 			},
@@ -258,7 +425,9 @@ func registerCollectors(
 		{
 			name:     "jobs",
 			enabled:  cfg.Collectors.Jobs.Enabled,
-			interval: time.Duration(cfg.Collectors.Jobs.IntervalSeconds) * time.Second,
+			interval: cfg.Collectors.Jobs.Interval(),
+			schedule: cfg.Collectors.Jobs.ScheduleConfig,
+			settings: cfg.Collectors.Jobs,
 			create: func() collector.Collector {
 				return collector.NewJobsCollector(client, cfg.Collectors.Jobs, projects)
 			},
@@ -266,7 +435,9 @@ func registerCollectors(
 		{
 			name:     "merge_requests",
 			enabled:  cfg.Collectors.MergeRequests.Enabled,
-			interval: time.Duration(cfg.Collectors.MergeRequests.IntervalSeconds) * time.Second,
+			interval: cfg.Collectors.MergeRequests.Interval(),
+			schedule: cfg.Collectors.MergeRequests.ScheduleConfig,
+			settings: cfg.Collectors.MergeRequests,
 			create: func() collector.Collector {
 				return collector.NewMergeRequestsCollector(client, cfg.Collectors.MergeRequests, projects)
 			},
@@ -274,7 +445,9 @@ func registerCollectors(
 		{
 			name:     "environments",
 			enabled:  cfg.Collectors.Environments.Enabled,
-			interval: time.Duration(cfg.Collectors.Environments.IntervalSeconds) * time.Second,
+			interval: cfg.Collectors.Environments.Interval(),
+			schedule: cfg.Collectors.Environments.ScheduleConfig,
+			settings: cfg.Collectors.Environments,
 			create: func() collector.Collector {
 				return collector.NewEnvironmentsCollector(client, cfg.Collectors.Environments, projects)
 			},
@@ -282,7 +455,9 @@ func registerCollectors(
 		{
 			name:     "test_reports",
 			enabled:  cfg.Collectors.TestReports.Enabled,
-			interval: time.Duration(cfg.Collectors.TestReports.IntervalSeconds) * time.Second,
+			interval: cfg.Collectors.TestReports.Interval(),
+			schedule: cfg.Collectors.TestReports.ScheduleConfig,
+			settings: cfg.Collectors.TestReports,
 			create: func() collector.Collector {
 				return collector.NewTestReportsCollector(client, cfg.Collectors.TestReports, projects)
 			},
@@ -290,7 +465,9 @@ func registerCollectors(
 		{
 			name:     "dora",
 			enabled:  cfg.Collectors.DORA.Enabled && features != nil && features.HasDORA,
-			interval: time.Duration(cfg.Collectors.DORA.IntervalSeconds) * time.Second,
+			interval: cfg.Collectors.DORA.Interval(),
+			schedule: cfg.Collectors.DORA.ScheduleConfig,
+			settings: cfg.Collectors.DORA,
 			create: func() collector.Collector {
 				return collector.NewDORACollector(client, cfg.Collectors.DORA, projects)
 			},
@@ -298,7 +475,9 @@ func registerCollectors(
 		{
 			name:     "value_stream",
 			enabled:  cfg.Collectors.ValueStream.Enabled && features != nil && features.HasValueStream,
-			interval: time.Duration(cfg.Collectors.ValueStream.IntervalSeconds) * time.Second,
+			interval: cfg.Collectors.ValueStream.Interval(),
+			schedule: cfg.Collectors.ValueStream.ScheduleConfig,
+			settings: cfg.Collectors.ValueStream,
 			create: func() collector.Collector {
 				return collector.NewValueStreamCollector(client, cfg.Collectors.ValueStream, projects)
 			},
@@ -306,7 +485,9 @@ func registerCollectors(
 		{
 			name:     "code_review",
 			enabled:  cfg.Collectors.CodeReview.Enabled && features != nil && features.HasCodeReview,
-			interval: time.Duration(cfg.Collectors.CodeReview.IntervalSeconds) * time.Second,
+			interval: cfg.Collectors.CodeReview.Interval(),
+			schedule: cfg.Collectors.CodeReview.ScheduleConfig,
+			settings: cfg.Collectors.CodeReview,
 			create: func() collector.Collector {
 				return collector.NewCodeReviewCollector(client, cfg.Collectors.CodeReview, projects)
 			},
@@ -314,7 +495,9 @@ func registerCollectors(
 		{
 			name:     "repository",
 			enabled:  cfg.Collectors.Repository.Enabled,
-			interval: time.Duration(cfg.Collectors.Repository.IntervalSeconds) * time.Second,
+			interval: cfg.Collectors.Repository.Interval(),
+			schedule: cfg.Collectors.Repository.ScheduleConfig,
+			settings: cfg.Collectors.Repository,
 			create: func() collector.Collector {
 				return collector.NewRepositoryCollector(client, cfg.Collectors.Repository, projects)
 			},
@@ -322,39 +505,133 @@ func registerCollectors(
 		{
 			name:     "contributors",
 			enabled:  cfg.Collectors.Contributors.Enabled,
-			interval: time.Duration(cfg.Collectors.Contributors.IntervalSeconds) * time.Second,
+			interval: cfg.Collectors.Contributors.Interval(),
+			schedule: cfg.Collectors.Contributors.ScheduleConfig,
+			settings: cfg.Collectors.Contributors,
 			create: func() collector.Collector {
 				return collector.NewContributorsCollector(client, cfg.Collectors.Contributors, projects)
 			},
 		},
-	}
-
-	for _, d := range defs {
-		if d.enabled {
-			collectorEnabled.WithLabelValues(d.name).Set(1)
-		} else {
-			collectorEnabled.WithLabelValues(d.name).Set(0)
-		}
-
-		if !d.enabled {
-			logger.WithField("collector", d.name).Info("collector disabled, skipping")
-			continue
-		}
-
-		c := d.create()
-		registry.Register(c)
-
-		interval := d.interval
-		if interval <= 0 {
-			interval = 30 * time.Second
-		}
-
-		task := scheduler.NewTask(d.name, interval, c.Run, logger)
-		sched.AddTask(task)
-
-		logger.WithFields(logrus.Fields{
-			"collector": d.name,
-			"interval":  interval,
-		}).Info("collector registered")
+		{
+			name:     "runners",
+			enabled:  cfg.Collectors.Runners.Enabled,
+			interval: cfg.Collectors.Runners.Interval(),
+			schedule: cfg.Collectors.Runners.ScheduleConfig,
+			settings: cfg.Collectors.Runners,
+			create: func() collector.Collector {
+				return collector.NewRunnersCollector(client, cfg.Collectors.Runners, projects)
+			},
+		},
+		{
+			name:     "job_queue",
+			enabled:  cfg.Collectors.JobQueue.Enabled,
+			interval: cfg.Collectors.JobQueue.Interval(),
+			schedule: cfg.Collectors.JobQueue.ScheduleConfig,
+			settings: cfg.Collectors.JobQueue,
+			create: func() collector.Collector {
+				return collector.NewJobQueueCollector(client, cfg.Collectors.JobQueue, projects)
+			},
+		},
+		{
+			name:     "issues",
+			enabled:  cfg.Collectors.Issues.Enabled,
+			interval: cfg.Collectors.Issues.Interval(),
+			schedule: cfg.Collectors.Issues.ScheduleConfig,
+			settings: cfg.Collectors.Issues,
+			create: func() collector.Collector {
+				return collector.NewIssuesCollector(client, cfg.Collectors.Issues, projects)
+			},
+		},
+		{
+			name:     "incidents",
+			enabled:  cfg.Collectors.Incidents.Enabled,
+			interval: cfg.Collectors.Incidents.Interval(),
+			schedule: cfg.Collectors.Incidents.ScheduleConfig,
+			settings: cfg.Collectors.Incidents,
+			create: func() collector.Collector {
+				return collector.NewIncidentsCollector(client, cfg.Collectors.Incidents, projects)
+			},
+		},
+		{
+			name:     "releases",
+			enabled:  cfg.Collectors.Releases.Enabled,
+			interval: cfg.Collectors.Releases.Interval(),
+			schedule: cfg.Collectors.Releases.ScheduleConfig,
+			settings: []interface{}{cfg.Collectors.Releases, cfg.Defaults.Refs.Tags, ic.ProjectTags()},
+			create: func() collector.Collector {
+				return collector.NewReleasesCollector(client, cfg.Collectors.Releases,
+					cfg.Defaults.Refs.Tags, ic.ProjectTags(), projects)
+			},
+		},
+		{
+			name:     "container_registry",
+			enabled:  cfg.Collectors.Registry.Enabled,
+			interval: cfg.Collectors.Registry.Interval(),
+			schedule: cfg.Collectors.Registry.ScheduleConfig,
+			settings: cfg.Collectors.Registry,
+			create: func() collector.Collector {
+				return collector.NewRegistryCollector(client, cfg.Collectors.Registry, projects)
+			},
+		},
+		{
+			name:     "packages",
+			enabled:  cfg.Collectors.Packages.Enabled,
+			interval: cfg.Collectors.Packages.Interval(),
+			schedule: cfg.Collectors.Packages.ScheduleConfig,
+			settings: cfg.Collectors.Packages,
+			create: func() collector.Collector {
+				return collector.NewPackagesCollector(client, cfg.Collectors.Packages, projects)
+			},
+		},
+		{
+			name:     "vulnerabilities",
+			enabled:  cfg.Collectors.Vulnerabilities.Enabled && features != nil && features.HasVulnerabilities,
+			interval: cfg.Collectors.Vulnerabilities.Interval(),
+			schedule: cfg.Collectors.Vulnerabilities.ScheduleConfig,
+			settings: cfg.Collectors.Vulnerabilities,
+			create: func() collector.Collector {
+				return collector.NewVulnerabilitiesCollector(client, cfg.Collectors.Vulnerabilities, projects)
+			},
+		},
+		{
+			name:     "pipeline_schedules",
+			enabled:  cfg.Collectors.PipelineSchedules.Enabled,
+			interval: cfg.Collectors.PipelineSchedules.Interval(),
+			schedule: cfg.Collectors.PipelineSchedules.ScheduleConfig,
+			settings: cfg.Collectors.PipelineSchedules,
+			create: func() collector.Collector {
+				return collector.NewPipelineSchedulesCollector(client, cfg.Collectors.PipelineSchedules, projects)
+			},
+		},
+		{
+			name:     "merge_trains",
+			enabled:  cfg.Collectors.MergeTrains.Enabled && features != nil && features.Tier >= gitlabclient.TierPremium,
+			interval: cfg.Collectors.MergeTrains.Interval(),
+			schedule: cfg.Collectors.MergeTrains.ScheduleConfig,
+			settings: cfg.Collectors.MergeTrains,
+			create: func() collector.Collector {
+				return collector.NewMergeTrainsCollector(client, cfg.Collectors.MergeTrains, projects)
+			},
+		},
+		{
+			name:     "compliance",
+			enabled:  cfg.Collectors.Compliance.Enabled,
+			interval: cfg.Collectors.Compliance.Interval(),
+			schedule: cfg.Collectors.Compliance.ScheduleConfig,
+			settings: cfg.Collectors.Compliance,
+			create: func() collector.Collector {
+				return collector.NewComplianceCollector(client, cfg.Collectors.Compliance, projects)
+			},
+		},
+		{
+			name:     "milestones",
+			enabled:  cfg.Collectors.Milestones.Enabled,
+			interval: cfg.Collectors.Milestones.Interval(),
+			schedule: cfg.Collectors.Milestones.ScheduleConfig,
+			settings: cfg.Collectors.Milestones,
+			create: func() collector.Collector {
+				return collector.NewMilestonesCollector(client, cfg.Collectors.Milestones, projects)
+			},
+		},
 	}
 }
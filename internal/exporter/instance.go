@@ -0,0 +1,433 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/collector"
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/scheduler"
+)
+
+// instance holds everything the exporter runs for a single GitLab instance:
+// its own client (and therefore token and rate limiter), detected tier,
+// tracked projects and collectors. Every series exported for the instance
+// carries an "instance" label with its name.
+type instance struct {
+	name     string
+	cfg      config.InstanceConfig
+	client   *gitlabclient.Client
+	registry *collector.Registry
+	projects []string
+	active   map[string]*activeCollector
+	logger   *logrus.Entry
+
+	// expiryTokens are the token names currently exported by
+	// age_token_expiry_timestamp, so removed tokens can be dropped.
+	expiryTokens map[string]struct{}
+
+	// adaptiveEvery is the evaluation interval of the scheduled
+	// adaptive_intervals task (0 before it is scheduled).
+	adaptiveEvery time.Duration
+}
+
+// tokenExpiryInterval is how often token expiry dates are refreshed.
+const tokenExpiryInterval = time.Hour
+
+// newInstance creates the GitLab client for ic, detects the instance's
+// tier, discovers its projects, audits the token's permissions on them and
+// probes their vulnerability report, reporting each startup phase to
+// progress. Nothing is registered or scheduled until addInstance is called.
+func (e *Exporter) newInstance(ctx context.Context, ic config.InstanceConfig, progress func(phase int)) (*instance, error) {
+	log := e.logger.WithField("instance", ic.Name)
+
+	client, err := newClient(ic, log)
+	if err != nil {
+		return nil, err
+	}
+
+	progress(phaseDetectingTier)
+	detectCtx, cancelDetect := context.WithTimeout(ctx, 60*time.Second)
+	_, err = client.DetectFeatures(detectCtx)
+	cancelDetect()
+	if err != nil {
+		return nil, fmt.Errorf("instance %s: %w", ic.Name, err)
+	}
+
+	progress(phaseDiscoveringProjects)
+	discoverCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	projects, err := discoverProjects(discoverCtx, ic, client, log)
+	if err != nil {
+		return nil, fmt.Errorf("instance %s: discovering projects: %w", ic.Name, err)
+	}
+	log.WithField("count", len(projects)).Info("projects discovered")
+
+	progress(phaseAuditingPermissions)
+	auditPermissions(ctx, client, projects, log)
+	client.ProbeVulnerabilities(ctx, projects)
+
+	return &instance{
+		name:     ic.Name,
+		cfg:      ic,
+		client:   client,
+		registry: collector.NewRegistry(log),
+		projects: projects,
+		active:   make(map[string]*activeCollector),
+		logger:   log,
+	}, nil
+}
+
+// newClient builds the GitLab client of an instance with its tokens and
+// rate limits.
+func newClient(ic config.InstanceConfig, log *logrus.Entry) (*gitlabclient.Client, error) {
+	client, err := gitlabclient.New(
+		ic.URL,
+		ic.Token,
+		ic.MaxRequestsPerSecond,
+		ic.BurstRequestsPerSecond,
+		ic.UseGraphQL,
+		log,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("instance %s: creating gitlab client: %w", ic.Name, err)
+	}
+	if len(ic.Tokens) > 0 {
+		client.SetTokens(poolTokens(ic.Tokens))
+	}
+	applyRateLimits(client, ic.GitLabConfig)
+	return client, nil
+}
+
+// addInstance publishes the instance's metrics and schedules its collectors.
+// e.mu must be held by callers other than NewExporter.
+func (e *Exporter) addInstance(inst *instance, cfg *config.Config) {
+	features := inst.client.Features()
+	if features != nil {
+		gitlabTier.WithLabelValues(inst.name).Set(float64(features.Tier))
+		inst.logger.WithField("tier", features.Tier).Info("gitlab tier detected")
+	}
+	projectsTracked.WithLabelValues(inst.name).Set(float64(len(inst.projects)))
+	inst.exportPermissions()
+
+	e.server.AddRegistry(inst.name, inst.registry)
+	e.rateLimits.set(inst.name, inst.client.RateLimiter())
+	inst.client.SetSharedLimiter(e.sharedLimiter(cfg.Redis))
+	e.applyCollectors(inst, cfg)
+	e.scheduler.AddTask(scheduler.NewTask(inst.taskName("token_expiry"), tokenExpiryInterval, inst.refreshTokenExpiry, inst.logger))
+	e.instances = append(e.instances, inst)
+	e.setPhase(inst.name, phaseRunning)
+}
+
+// restartTokenExpiry re-schedules the token expiry task so that changed
+// tokens are looked up right away. e.mu must be held.
+func (e *Exporter) restartTokenExpiry(inst *instance) {
+	e.scheduler.RemoveTask(inst.taskName("token_expiry"))
+	e.scheduler.AddTask(scheduler.NewTask(inst.taskName("token_expiry"), tokenExpiryInterval, inst.refreshTokenExpiry, inst.logger))
+}
+
+// refreshTokenExpiry exports the expiry date of every pooled token.
+func (inst *instance) refreshTokenExpiry(ctx context.Context) error {
+	current := make(map[string]struct{})
+	for _, te := range inst.client.LookupTokens(ctx) {
+		current[te.Name] = struct{}{}
+		if te.ExpiresAt.IsZero() {
+			tokenExpiry.DeleteLabelValues(inst.name, te.Name)
+			continue
+		}
+		tokenExpiry.WithLabelValues(inst.name, te.Name).Set(float64(te.ExpiresAt.Unix()))
+	}
+	for name := range inst.expiryTokens {
+		if _, ok := current[name]; !ok {
+			tokenExpiry.DeleteLabelValues(inst.name, name)
+		}
+	}
+	inst.expiryTokens = current
+	return ctx.Err()
+}
+
+// auditPermissions checks the token's scopes and role on every project and
+// stores the resulting matrix on the client, logging one summary line per
+// collector that will skip projects.
+func auditPermissions(ctx context.Context, client *gitlabclient.Client, projects []string, logger *logrus.Entry) {
+	auditCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	m := client.AuditPermissions(auditCtx, projects)
+	client.SetPermissions(m)
+
+	results := m.Results()
+	for collectorName, denied := range m.Denied() {
+		if len(denied) == 0 {
+			continue
+		}
+		logger.WithFields(logrus.Fields{
+			"collector": collectorName,
+			"projects":  len(denied),
+			"example":   denied[0],
+			"reason":    results[collectorName][denied[0]].Reason,
+		}).Warn("token lacks permissions, collector will skip these projects")
+	}
+}
+
+// exportPermissions publishes the client's permission matrix as
+// age_permission_ok, replacing any previous series of the instance.
+func (inst *instance) exportPermissions() {
+	permissionOK.DeletePartialMatch(map[string]string{"instance": inst.name})
+	m := inst.client.Permissions()
+	if m == nil {
+		return
+	}
+	for collectorName, projects := range m.Results() {
+		for project, res := range projects {
+			v := 0.0
+			if res.OK {
+				v = 1
+			}
+			permissionOK.WithLabelValues(inst.name, collectorName, project).Set(v)
+		}
+	}
+}
+
+// permissionReport returns the permission audit of every instance for the
+// /diagnostics/permissions endpoint.
+func (e *Exporter) permissionReport() interface{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make(map[string]interface{}, len(e.instances))
+	for _, inst := range e.instances {
+		if m := inst.client.Permissions(); m != nil {
+			out[inst.name] = m.Report()
+		}
+	}
+	return out
+}
+
+// poolTokens converts configured pool tokens for the GitLab client.
+func poolTokens(tokens []config.TokenConfig) []gitlabclient.PoolToken {
+	out := make([]gitlabclient.PoolToken, len(tokens))
+	for i, t := range tokens {
+		out[i] = gitlabclient.PoolToken{Name: t.Name, Token: t.Token, Namespaces: t.Namespaces}
+	}
+	return out
+}
+
+// removeInstance stops and unregisters every collector of the instance and
+// drops its operational series. e.mu must be held.
+func (e *Exporter) removeInstance(inst *instance) {
+	for name := range inst.active {
+		e.removeCollector(inst, name)
+	}
+	e.scheduler.RemoveTask(inst.taskName("token_expiry"))
+	e.scheduler.RemoveTask(inst.taskName("adaptive_intervals"))
+	e.server.RemoveRegistry(inst.name)
+	e.rateLimits.remove(inst.name)
+
+	projectsTracked.DeleteLabelValues(inst.name)
+	e.deletePhase(inst.name)
+	gitlabTier.DeleteLabelValues(inst.name)
+	tokenExpiry.DeletePartialMatch(map[string]string{"instance": inst.name})
+	permissionOK.DeletePartialMatch(map[string]string{"instance": inst.name})
+	collectorEnabled.DeletePartialMatch(map[string]string{"instance": inst.name})
+
+	for i, other := range e.instances {
+		if other == inst {
+			e.instances = append(e.instances[:i], e.instances[i+1:]...)
+			break
+		}
+	}
+	inst.logger.Info("gitlab instance removed")
+}
+
+// taskName returns the scheduler task name for a collector of inst.
+func (inst *instance) taskName(collectorName string) string {
+	return inst.name + "/" + collectorName
+}
+
+// applyCollectors reconciles the instance's registered collectors and
+// scheduler tasks with cfg. Collectors whose settings are unchanged are kept
+// (preserving their in-memory state); a changed interval only re-creates the
+// scheduler task; any other settings change re-creates the collector. e.mu
+// must be held by callers other than NewExporter.
+func (e *Exporter) applyCollectors(inst *instance, cfg *config.Config) {
+	for _, d := range collectorDefs(cfg, inst.cfg, inst.client, inst.client.Features(), inst.projects) {
+		if d.enabled {
+			collectorEnabled.WithLabelValues(inst.name, d.name).Set(1)
+		} else {
+			collectorEnabled.WithLabelValues(inst.name, d.name).Set(0)
+		}
+
+		interval := d.interval
+		if interval <= 0 {
+			interval = 30 * time.Second
+		}
+
+		current, exists := inst.active[d.name]
+
+		if !d.enabled {
+			if exists {
+				e.removeCollector(inst, d.name)
+			}
+			inst.logger.WithField("collector", d.name).Info("collector disabled, skipping")
+			continue
+		}
+
+		if exists && reflect.DeepEqual(withoutScheduling(current.settings), withoutScheduling(d.settings)) {
+			current.settings = d.settings
+			if current.interval != interval || current.schedule != d.schedule {
+				current.interval = interval
+				current.schedule = d.schedule
+				e.scheduler.RemoveTask(inst.taskName(d.name))
+				e.scheduler.AddTask(inst.collectorTask(d.name, current))
+				inst.setStretch(d.name, current, 1)
+				inst.logger.WithFields(logrus.Fields{
+					"collector": d.name,
+					"interval":  interval,
+				}).Info("collector interval updated")
+			}
+			continue
+		}
+
+		if exists {
+			e.removeCollector(inst, d.name)
+		}
+
+		c := d.create()
+		ac := &activeCollector{
+			collector: c,
+			interval:  interval,
+			schedule:  d.schedule,
+			settings:  d.settings,
+		}
+		inst.registry.Register(c)
+		e.scheduler.AddTask(inst.collectorTask(d.name, ac))
+		inst.setStretch(d.name, ac, 1)
+		inst.active[d.name] = ac
+
+		inst.logger.WithFields(logrus.Fields{
+			"collector": d.name,
+			"interval":  interval,
+		}).Info("collector registered")
+	}
+
+	if every := cfg.Collectors.Adaptive.EvaluationInterval(); every != inst.adaptiveEvery {
+		e.scheduler.RemoveTask(inst.taskName("adaptive_intervals"))
+		e.scheduler.AddTask(scheduler.NewTask(inst.taskName("adaptive_intervals"), every, func(context.Context) error {
+			e.adaptIntervals(inst)
+			return nil
+		}, inst.logger))
+		inst.adaptiveEvery = every
+	}
+}
+
+// withoutScheduling returns a copy of collector settings with the interval
+// and the inlined ScheduleConfig zeroed, so that changing only those
+// re-creates the scheduler task but keeps the collector. Lists of settings
+// are handled element by element.
+func withoutScheduling(settings interface{}) interface{} {
+	if list, ok := settings.([]interface{}); ok {
+		out := make([]interface{}, len(list))
+		for i, s := range list {
+			out[i] = withoutScheduling(s)
+		}
+		return out
+	}
+	v := reflect.ValueOf(settings)
+	if v.Kind() != reflect.Struct {
+		return settings
+	}
+	cp := reflect.New(v.Type()).Elem()
+	cp.Set(v)
+	for _, name := range []string{"IntervalSeconds", "ScheduleConfig"} {
+		if f := cp.FieldByName(name); f.IsValid() && f.CanSet() {
+			f.SetZero()
+		}
+	}
+	return cp.Interface()
+}
+
+// collectorTask builds the scheduler task that runs ac and attaches it to
+// ac.
+func (inst *instance) collectorTask(name string, ac *activeCollector) *scheduler.Task {
+	task := scheduler.NewTask(inst.taskName(name), ac.interval, ac.run, inst.logger)
+	ac.task = task
+	task.InitialDelay = ac.schedule.InitialDelay()
+	task.Jitter = ac.schedule.Jitter()
+	task.Stagger = ac.schedule.StaggerProjects
+	task.Timeout = ac.schedule.Timeout()
+	task.Overlap = scheduler.OverlapPolicy(ac.schedule.Overlap)
+	if ac.schedule.Schedule != "" {
+		cron, err := scheduler.ParseCron(ac.schedule.Schedule)
+		if err != nil {
+			// Rejected by config validation; fall back to the interval.
+			inst.logger.WithError(err).WithField("collector", name).Warn("invalid collector schedule, using interval")
+		} else {
+			task.Schedule = cron
+		}
+	}
+	return task
+}
+
+// removeCollector stops the scheduler task and unregisters the collector.
+func (e *Exporter) removeCollector(inst *instance, name string) {
+	e.scheduler.RemoveTask(inst.taskName(name))
+	inst.registry.Unregister(name)
+	delete(inst.active, name)
+	collectorInterval.DeleteLabelValues(inst.name, name)
+}
+
+// discoverProjects builds the list of project paths from the instance's
+// explicit list and from wildcard expansion via the GitLab API.
+func discoverProjects(ctx context.Context, ic config.InstanceConfig, client *gitlabclient.Client, logger *logrus.Entry) ([]string, error) {
+	seen := make(map[string]struct{})
+	var projects []string
+
+	// Explicit projects.
+	for _, p := range ic.Projects {
+		if _, ok := seen[p.Name]; !ok {
+			seen[p.Name] = struct{}{}
+			projects = append(projects, p.Name)
+		}
+	}
+
+	// Wildcard expansion.
+	for _, wc := range ic.Wildcards {
+		opts := &gitlab.ListProjectsOptions{
+			Search:      gitlab.Ptr(wc.Search),
+			Archived:    gitlab.Ptr(wc.Archived),
+			ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1},
+		}
+		for {
+			projs, resp, err := client.REST().Projects.ListProjects(opts, gitlab.WithContext(ctx))
+			if err != nil {
+				logger.WithError(err).WithField("owner", wc.Owner.Name).
+					Warn("failed to expand wildcard, skipping")
+				break
+			}
+			for _, p := range projs {
+				path := p.PathWithNamespace
+				if _, ok := seen[path]; !ok {
+					seen[path] = struct{}{}
+					projects = append(projects, path)
+				}
+			}
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+	}
+
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no projects configured or discovered")
+	}
+
+	return projects, nil
+}
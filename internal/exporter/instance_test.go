@@ -0,0 +1,35 @@
+package exporter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+)
+
+func TestWithoutScheduling(t *testing.T) {
+	base := config.JobsCollectorConfig{Enabled: true, IntervalSeconds: 60}
+
+	rescheduled := base
+	rescheduled.IntervalSeconds = 120
+	rescheduled.TimeoutSeconds = 30
+
+	if !reflect.DeepEqual(withoutScheduling(base), withoutScheduling(rescheduled)) {
+		t.Error("an interval or schedule change re-creates the collector")
+	}
+	if base.IntervalSeconds != 60 {
+		t.Error("withoutScheduling modified its argument")
+	}
+
+	changed := base
+	changed.IncludeRunnerDetails = !base.IncludeRunnerDetails
+	if reflect.DeepEqual(withoutScheduling(base), withoutScheduling(changed)) {
+		t.Error("a settings change keeps the collector")
+	}
+
+	list := []interface{}{base, []string{"v*"}}
+	relist := []interface{}{rescheduled, []string{"v*"}}
+	if !reflect.DeepEqual(withoutScheduling(list), withoutScheduling(relist)) {
+		t.Error("an interval change inside a settings list re-creates the collector")
+	}
+}
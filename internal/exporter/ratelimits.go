@@ -0,0 +1,90 @@
+package exporter
+
+import (
+	"regexp"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	gitlabclient "github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/gitlab"
+)
+
+// rateLimitMetrics exports the priority class queues of every instance's
+// rate limiter at scrape time. Limiters are tracked separately from
+// e.instances so that scrapes do not wait for reloads.
+type rateLimitMetrics struct {
+	mu       sync.Mutex
+	limiters map[string]*gitlabclient.RateLimiter
+
+	queued *prometheus.Desc
+	wait   *prometheus.Desc
+}
+
+func newRateLimitMetrics() *rateLimitMetrics {
+	labels := []string{"instance", "class"}
+	return &rateLimitMetrics{
+		limiters: make(map[string]*gitlabclient.RateLimiter),
+		queued: prometheus.NewDesc("age_rate_limit_queue_depth",
+			"GitLab requests of the priority class currently waiting for the rate limiter.", labels, nil),
+		wait: prometheus.NewDesc("age_rate_limit_wait_seconds",
+			"Time GitLab requests of the priority class waited for the rate limiter.", labels, nil),
+	}
+}
+
+// set tracks the rate limiter of an instance.
+func (m *rateLimitMetrics) set(instance string, rl *gitlabclient.RateLimiter) {
+	m.mu.Lock()
+	m.limiters[instance] = rl
+	m.mu.Unlock()
+}
+
+// remove stops tracking an instance.
+func (m *rateLimitMetrics) remove(instance string) {
+	m.mu.Lock()
+	delete(m.limiters, instance)
+	m.mu.Unlock()
+}
+
+// Describe implements prometheus.Collector.
+func (m *rateLimitMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.queued
+	ch <- m.wait
+}
+
+// Collect implements prometheus.Collector.
+func (m *rateLimitMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.mu.Lock()
+	limiters := make(map[string]*gitlabclient.RateLimiter, len(m.limiters))
+	for name, rl := range m.limiters {
+		limiters[name] = rl
+	}
+	m.mu.Unlock()
+
+	for inst, rl := range limiters {
+		for _, s := range rl.ClassStats() {
+			ch <- prometheus.MustNewConstMetric(m.queued, prometheus.GaugeValue, float64(s.Queued), inst, s.Class)
+			ch <- prometheus.MustNewConstHistogram(m.wait, s.Requests, s.WaitSeconds, s.WaitBuckets, inst, s.Class)
+		}
+	}
+}
+
+// applyRateLimits configures the priority classes and endpoint limits of
+// a client's rate limiter. Patterns have been checked by config.Validate.
+func applyRateLimits(client *gitlabclient.Client, cfg config.GitLabConfig) {
+	classes := make([]gitlabclient.PriorityClass, len(cfg.PriorityClasses))
+	for i, c := range cfg.PriorityClasses {
+		classes[i] = gitlabclient.PriorityClass{Name: c.Name, Weight: c.Weight}
+	}
+	limits := make([]gitlabclient.EndpointLimit, len(cfg.EndpointLimits))
+	for i, l := range cfg.EndpointLimits {
+		limits[i] = gitlabclient.EndpointLimit{
+			Name:    l.Name,
+			Pattern: regexp.MustCompile(l.PathRegexp),
+			RPS:     l.MaxRequestsPerSecond,
+			Burst:   l.BurstRequestsPerSecond,
+		}
+	}
+	client.RateLimiter().SetPriorityClasses(classes)
+	client.RateLimiter().SetEndpointLimits(limits)
+}
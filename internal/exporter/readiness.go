@@ -0,0 +1,143 @@
+package exporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/server"
+)
+
+// phaseNames are the human-readable startup phases used in /startup and
+// /ready?verbose.
+var phaseNames = map[int]string{
+	phaseWaiting:             "waiting to connect",
+	phaseDetectingTier:       "detecting tier",
+	phaseDiscoveringProjects: "discovering projects",
+	phaseAuditingPermissions: "auditing permissions",
+	phaseRunning:             "running",
+}
+
+// setPhase records the startup phase of an instance.
+func (e *Exporter) setPhase(name string, phase int) {
+	e.phaseMu.Lock()
+	e.phases[name] = phase
+	e.phaseMu.Unlock()
+	startupPhase.WithLabelValues(name).Set(float64(phase))
+}
+
+// deletePhase forgets a removed instance.
+func (e *Exporter) deletePhase(name string) {
+	e.phaseMu.Lock()
+	delete(e.phases, name)
+	e.phaseMu.Unlock()
+	startupPhase.DeleteLabelValues(name)
+}
+
+// instanceConditions reports, per configured instance, whether it has
+// finished starting up.
+func (e *Exporter) instanceConditions() []server.Condition {
+	e.phaseMu.Lock()
+	defer e.phaseMu.Unlock()
+
+	names := make([]string, 0, len(e.phases))
+	for name := range e.phases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	conds := make([]server.Condition, 0, len(names))
+	for _, name := range names {
+		phase := e.phases[name]
+		conds = append(conds, server.Condition{
+			Name:    "instance/" + name,
+			OK:      phase == phaseRunning,
+			Message: phaseNames[phase],
+		})
+	}
+	return conds
+}
+
+// notEvaluated is the readiness result until the policy has been evaluated
+// once, so a probe that cannot take e.mu never reports ready by default.
+var notEvaluated = []server.Condition{{Name: "starting", Message: "readiness not evaluated yet"}}
+
+// readinessConditions evaluates the server.readiness policy: every
+// instance has started, every enabled collector has finished a cycle
+// (once, successful or not; later failures do not make the exporter
+// unready), and every instance answered recently. While a reload holds
+// e.mu the previous result is returned so probes do not block.
+func (e *Exporter) readinessConditions() []server.Condition {
+	if !e.mu.TryLock() {
+		e.readyMu.Lock()
+		defer e.readyMu.Unlock()
+		return e.lastReadiness
+	}
+	policy := e.config.Server.Readiness
+	conds := e.instanceConditions()
+	if policy.RequireFirstCollection {
+		conds = append(conds, e.firstCollectionCondition())
+	}
+	if window := policy.GitLabReachableWithin(); window > 0 {
+		for _, inst := range e.instances {
+			conds = append(conds, reachableCondition(inst, window))
+		}
+	}
+	e.mu.Unlock()
+
+	e.readyMu.Lock()
+	e.lastReadiness = conds
+	e.readyMu.Unlock()
+	return conds
+}
+
+// firstCollectionCondition reports whether every enabled collector of every
+// instance has finished a collection cycle. A collector whose cycles fail,
+// for example on a project it is denied, still counts: its errors are
+// reported by age_scrape_errors_total. e.mu must be held.
+func (e *Exporter) firstCollectionCondition() server.Condition {
+	cond := server.Condition{Name: "first_collection", OK: true}
+	if e.collected.Load() {
+		return cond
+	}
+
+	if len(e.instances) < len(e.config.GitLabInstances()) {
+		cond.OK = false
+		cond.Message = "waiting for instances to start"
+		return cond
+	}
+
+	var pending []string
+	for _, inst := range e.instances {
+		for name, ac := range inst.active {
+			if ac.cycles.Load() == 0 {
+				pending = append(pending, inst.taskName(name))
+			}
+		}
+	}
+	if len(pending) > 0 {
+		sort.Strings(pending)
+		cond.OK = false
+		cond.Message = "waiting for " + strings.Join(pending, ", ")
+		return cond
+	}
+
+	e.collected.Store(true)
+	return cond
+}
+
+// reachableCondition reports whether inst got an answer from GitLab within
+// window.
+func reachableCondition(inst *instance, window time.Duration) server.Condition {
+	cond := server.Condition{Name: "gitlab_reachable/" + inst.name}
+	last := inst.client.LastContact()
+	if last.IsZero() {
+		cond.Message = "no response from gitlab yet"
+		return cond
+	}
+	age := time.Since(last).Truncate(time.Second)
+	cond.OK = age <= window
+	cond.Message = fmt.Sprintf("last response %s ago", age)
+	return cond
+}
@@ -0,0 +1,356 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/store"
+)
+
+// ConfigLoader produces a fully resolved and validated configuration. It is
+// invoked on every reload so that CLI flag overrides can be re-applied on
+// top of the file contents.
+type ConfigLoader func() (*config.Config, error)
+
+// WatchConfig enables live configuration reloads. Every value received on
+// signals (typically SIGHUP) triggers a reload; if the configuration has
+// reload.watch_file enabled, path and any *_file secrets are additionally
+// polled for changes. It must be called before Run.
+func (e *Exporter) WatchConfig(path string, loader ConfigLoader, signals <-chan os.Signal) {
+	e.configPath = path
+	e.loader = loader
+	e.reloadCh = signals
+}
+
+// reloadLoop waits for reload triggers until ctx is cancelled. The polled
+// set covers the config file and every file-sourced secret, so rotating a
+// mounted token triggers a reload just like editing the config.
+func (e *Exporter) reloadLoop(ctx context.Context) {
+	e.mu.Lock()
+	reloadCfg := e.config.Reload
+	e.mu.Unlock()
+
+	var poll <-chan time.Time
+	if reloadCfg.WatchFile && reloadCfg.PollIntervalSeconds > 0 && len(e.watchedFiles()) > 0 {
+		ticker := time.NewTicker(reloadCfg.PollInterval())
+		defer ticker.Stop()
+		poll = ticker.C
+		e.logger.WithFields(logrus.Fields{
+			"files":    e.watchedFiles(),
+			"interval": reloadCfg.PollInterval(),
+		}).Info("watching configuration files for changes")
+	}
+
+	lastHash, _ := filesHash(e.watchedFiles())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.reloadCh:
+			e.logger.Info("reload signal received")
+			if err := e.Reload(ctx); err != nil {
+				e.logger.WithError(err).Error("configuration reload rejected")
+			}
+			lastHash, _ = filesHash(e.watchedFiles())
+		case <-poll:
+			h, err := filesHash(e.watchedFiles())
+			if err != nil {
+				e.logger.WithError(err).Debug("config file poll failed")
+				continue
+			}
+			if bytes.Equal(h, lastHash) {
+				continue
+			}
+			lastHash = h
+			e.logger.Info("configuration files changed")
+			if err := e.Reload(ctx); err != nil {
+				e.logger.WithError(err).Error("configuration reload rejected")
+			}
+		}
+	}
+}
+
+// Reload re-runs the configuration loader, validates the result and applies
+// it to the running exporter. GitLab instances are matched by name: new
+// instances are brought up in the background like at startup, removed ones
+// are torn down, and an instance whose URL or GraphQL setting changed is
+// torn down and brought up again, so an unreachable GitLab never rejects a
+// reload. For the remaining instances the access token and rate limits are
+// swapped on the client, projects are re-discovered when the project list
+// or wildcards changed, and collectors are enabled, disabled or
+// re-scheduled. Instances that are still being brought up are restarted in
+// the background with their new settings, or abandoned when removed. A
+// rejected reload leaves the running configuration untouched.
+//
+// Every step that can fail (store connection, project discovery) runs
+// first, without holding e.mu, so probes and diagnostics are not blocked by
+// GitLab or Redis round trips. The changes are then applied in one step
+// that cannot fail.
+func (e *Exporter) Reload(ctx context.Context) error {
+	if e.loader == nil {
+		return fmt.Errorf("configuration reload is not enabled")
+	}
+
+	e.reloadMu.Lock()
+	defer e.reloadMu.Unlock()
+
+	newCfg, err := e.loader()
+	if err != nil {
+		configReloadSuccess.Set(0)
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+
+	e.mu.Lock()
+	oldCfg := e.config
+	instances := append([]*instance(nil), e.instances...)
+	pending := make(map[string]*pendingInstance, len(e.connecting))
+	for name, p := range e.connecting {
+		pending[name] = p
+	}
+	e.mu.Unlock()
+
+	e.warnRestartRequired(oldCfg, newCfg)
+
+	// --- Prepare: nothing is applied until every step has succeeded. ---
+	current := make(map[string]*instance, len(instances))
+	for _, inst := range instances {
+		current[inst.name] = inst
+	}
+
+	var (
+		started   []config.InstanceConfig
+		replaced  []*instance
+		updates   []instanceUpdate
+		reconnect []config.InstanceConfig
+		abandoned []string
+	)
+	wanted := make(map[string]struct{})
+	for _, ic := range newCfg.GitLabInstances() {
+		wanted[ic.Name] = struct{}{}
+		if p, ok := pending[ic.Name]; ok {
+			if !reflect.DeepEqual(p.cfg, ic) {
+				reconnect = append(reconnect, ic)
+			}
+			continue
+		}
+		inst, ok := current[ic.Name]
+		if !ok || inst.cfg.URL != ic.URL || inst.cfg.UseGraphQL != ic.UseGraphQL {
+			started = append(started, ic)
+			if ok {
+				replaced = append(replaced, inst)
+			}
+			continue
+		}
+
+		u := instanceUpdate{inst: inst, cfg: ic, projects: inst.projects}
+		u.tokensChanged = u.cfg.Token != inst.cfg.Token || !reflect.DeepEqual(u.cfg.Tokens, inst.cfg.Tokens)
+		if !reflect.DeepEqual(inst.cfg.Projects, ic.Projects) ||
+			!reflect.DeepEqual(inst.cfg.Wildcards, ic.Wildcards) {
+			// Discover with the new tokens, which may replace revoked ones;
+			// the running client keeps the old ones until the commit.
+			client := inst.client
+			if u.tokensChanged {
+				if client, err = newClient(ic, inst.logger); err != nil {
+					configReloadSuccess.Set(0)
+					return err
+				}
+			}
+			discoverCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+			u.projects, err = discoverProjects(discoverCtx, ic, client, inst.logger)
+			cancel()
+			if err != nil {
+				configReloadSuccess.Set(0)
+				return fmt.Errorf("instance %s: discovering projects: %w", ic.Name, err)
+			}
+			u.projectsChanged = true
+		}
+		updates = append(updates, u)
+	}
+	for _, inst := range instances {
+		if _, ok := wanted[inst.name]; !ok {
+			replaced = append(replaced, inst)
+		}
+	}
+	for name := range pending {
+		if _, ok := wanted[name]; !ok {
+			abandoned = append(abandoned, name)
+		}
+	}
+
+	// Connect the new store when Redis settings (or a rotated
+	// redis.url_file) changed. The old store is closed only once the new
+	// one is in place.
+	redisChanged := !reflect.DeepEqual(oldCfg.Redis, newCfg.Redis)
+	var newSt store.Store
+	if redisChanged {
+		newSt, err = newStore(newCfg.Redis, e.logger)
+		if err != nil {
+			configReloadSuccess.Set(0)
+			return err
+		}
+	}
+
+	// --- Commit: nothing below can fail. ---
+	e.mu.Lock()
+
+	var oldStore store.Store
+	if redisChanged {
+		oldStore, e.store = e.store, newSt
+	}
+
+	for _, inst := range replaced {
+		e.removeInstance(inst)
+	}
+	for _, name := range abandoned {
+		e.abandonPending(name, pending[name])
+	}
+	for _, ic := range reconnect {
+		e.abandonPending(ic.Name, pending[ic.Name])
+		e.connect(ctx, ic)
+	}
+	for _, ic := range started {
+		e.connect(ctx, ic)
+	}
+
+	for _, u := range updates {
+		inst := u.inst
+		if u.cfg.Token != inst.cfg.Token {
+			inst.client.SetToken(u.cfg.Token)
+		}
+		if !reflect.DeepEqual(u.cfg.Tokens, inst.cfg.Tokens) {
+			inst.client.SetTokens(poolTokens(u.cfg.Tokens))
+		}
+		if u.cfg.MaxRequestsPerSecond != inst.cfg.MaxRequestsPerSecond ||
+			u.cfg.BurstRequestsPerSecond != inst.cfg.BurstRequestsPerSecond {
+			inst.client.RateLimiter().SetLimit(u.cfg.MaxRequestsPerSecond, u.cfg.BurstRequestsPerSecond)
+		}
+		if !reflect.DeepEqual(u.cfg.PriorityClasses, inst.cfg.PriorityClasses) ||
+			!reflect.DeepEqual(u.cfg.EndpointLimits, inst.cfg.EndpointLimits) {
+			applyRateLimits(inst.client, u.cfg.GitLabConfig)
+		}
+		if redisChanged {
+			inst.client.SetSharedLimiter(e.sharedLimiter(newCfg.Redis))
+		}
+		if u.projectsChanged {
+			for _, ac := range inst.active {
+				ac.collector.SetProjects(u.projects)
+			}
+			inst.projects = u.projects
+			projectsTracked.WithLabelValues(inst.name).Set(float64(len(u.projects)))
+			inst.logger.WithField("count", len(u.projects)).Info("tracked projects updated")
+		}
+		if u.tokensChanged {
+			e.restartTokenExpiry(inst)
+		}
+		inst.cfg = u.cfg
+		e.applyCollectors(inst, newCfg)
+	}
+
+	e.config = newCfg
+	e.server.SetConfig(newCfg)
+	e.mu.Unlock()
+
+	if oldStore != nil {
+		if err := oldStore.Close(); err != nil {
+			e.logger.WithError(err).Warn("error closing previous store")
+		}
+	}
+
+	configReloadSuccess.Set(1)
+	configReloadTimestamp.SetToCurrentTime()
+	e.logger.Info("configuration reloaded")
+
+	// Rebuild the permission matrix of instances whose projects or tokens
+	// changed, now that the new tokens are in place. Until it completes,
+	// collectors use the previous matrix.
+	for _, u := range updates {
+		if u.projectsChanged || u.tokensChanged {
+			auditPermissions(ctx, u.inst.client, u.projects, u.inst.logger)
+			u.inst.exportPermissions()
+		}
+	}
+
+	return nil
+}
+
+// abandonPending stops bringing up an instance a reload changed or removed.
+// An instance that came up while the reload was being prepared is removed
+// instead. e.mu must be held.
+func (e *Exporter) abandonPending(name string, p *pendingInstance) {
+	if e.connecting[name] == p {
+		e.cancelConnect(name)
+		return
+	}
+	for _, inst := range e.instances {
+		if inst.name == name {
+			e.removeInstance(inst)
+			return
+		}
+	}
+}
+
+// instanceUpdate is a pending change to an instance that is kept across a
+// reload.
+type instanceUpdate struct {
+	inst            *instance
+	cfg             config.InstanceConfig
+	projects        []string
+	projectsChanged bool
+	tokensChanged   bool
+}
+
+// warnRestartRequired logs settings that changed but can only take effect
+// after a restart.
+func (e *Exporter) warnRestartRequired(oldCfg, newCfg *config.Config) {
+	checks := []struct {
+		name    string
+		changed bool
+	}{
+		{"server.listen_address", oldCfg.Server.ListenAddress != newCfg.Server.ListenAddress},
+		{"server.enable_pprof", oldCfg.Server.EnablePprof != newCfg.Server.EnablePprof},
+		{"log", oldCfg.Log != newCfg.Log},
+		{"reload", oldCfg.Reload != newCfg.Reload},
+	}
+	for _, c := range checks {
+		if c.changed {
+			e.logger.WithField("setting", c.name).Warn("setting changed but requires a restart to take effect")
+		}
+	}
+}
+
+// watchedFiles returns the config file path (if any) followed by the
+// secret files referenced by the running configuration.
+func (e *Exporter) watchedFiles() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var files []string
+	if e.configPath != "" {
+		files = append(files, e.configPath)
+	}
+	return append(files, e.config.SecretFiles()...)
+}
+
+// filesHash returns a SHA-256 digest over the contents of paths. Hashing
+// the content (rather than comparing mtimes) also catches the atomic symlink
+// swaps Kubernetes performs when updating mounted ConfigMaps and Secrets.
+func filesHash(paths []string) ([]byte, error) {
+	h := sha256.New()
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		h.Write([]byte(p))
+		h.Write(data)
+	}
+	return h.Sum(nil), nil
+}
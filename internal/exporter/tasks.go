@@ -0,0 +1,87 @@
+package exporter
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/scheduler"
+)
+
+// taskMetrics exports the execution state of every scheduler task at scrape
+// time. Task names have the form "<instance>/<task>".
+type taskMetrics struct {
+	scheduler *scheduler.Scheduler
+
+	running      *prometheus.Desc
+	queued       *prometheus.Desc
+	runs         *prometheus.Desc
+	skipped      *prometheus.Desc
+	cancelled    *prometheus.Desc
+	timedOut     *prometheus.Desc
+	lastDuration *prometheus.Desc
+}
+
+func newTaskMetrics(s *scheduler.Scheduler) *taskMetrics {
+	labels := []string{"instance", "task"}
+	return &taskMetrics{
+		scheduler: s,
+		running: prometheus.NewDesc("age_task_running",
+			"Whether a run of the task is executing (1) or not (0).", labels, nil),
+		queued: prometheus.NewDesc("age_task_queued",
+			"Whether a run of the task is queued behind the executing one (1) or not (0).", labels, nil),
+		runs: prometheus.NewDesc("age_task_runs_total",
+			"Total runs started for the task.", labels, nil),
+		skipped: prometheus.NewDesc("age_task_skipped_runs_total",
+			"Total runs skipped because the previous run was still executing.", labels, nil),
+		cancelled: prometheus.NewDesc("age_task_cancelled_runs_total",
+			"Total runs cancelled in favour of a newer run.", labels, nil),
+		timedOut: prometheus.NewDesc("age_task_timeouts_total",
+			"Total runs that hit the task timeout.", labels, nil),
+		lastDuration: prometheus.NewDesc("age_task_last_duration_seconds",
+			"Duration of the last completed run of the task.", labels, nil),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *taskMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.running
+	ch <- m.queued
+	ch <- m.runs
+	ch <- m.skipped
+	ch <- m.cancelled
+	ch <- m.timedOut
+	ch <- m.lastDuration
+}
+
+// Collect implements prometheus.Collector.
+func (m *taskMetrics) Collect(ch chan<- prometheus.Metric) {
+	for _, t := range m.scheduler.Tasks() {
+		s := t.State()
+		inst, task, _ := strings.Cut(s.Name, "/")
+		ch <- prometheus.MustNewConstMetric(m.running, prometheus.GaugeValue, boolToFloat(s.Running), inst, task)
+		ch <- prometheus.MustNewConstMetric(m.queued, prometheus.GaugeValue, boolToFloat(s.Queued), inst, task)
+		ch <- prometheus.MustNewConstMetric(m.runs, prometheus.CounterValue, float64(s.Runs), inst, task)
+		ch <- prometheus.MustNewConstMetric(m.skipped, prometheus.CounterValue, float64(s.Skipped), inst, task)
+		ch <- prometheus.MustNewConstMetric(m.cancelled, prometheus.CounterValue, float64(s.Cancelled), inst, task)
+		ch <- prometheus.MustNewConstMetric(m.timedOut, prometheus.CounterValue, float64(s.TimedOut), inst, task)
+		ch <- prometheus.MustNewConstMetric(m.lastDuration, prometheus.GaugeValue, s.LastDuration, inst, task)
+	}
+}
+
+// taskReport serves the scheduler task states on /diagnostics/tasks.
+func (e *Exporter) taskReport() interface{} {
+	tasks := e.scheduler.Tasks()
+	states := make([]scheduler.TaskState, len(tasks))
+	for i, t := range tasks {
+		states[i] = t.State()
+	}
+	return states
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,34 @@
+package gitlab
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimitWindow is the window GitLab's RateLimit-Limit applies to.
+const RateLimitWindow = time.Minute
+
+// Budget is the remote API rate-limit budget of a client, summed over its
+// token pool.
+type Budget struct {
+	// Limit is the number of requests allowed per window and Remaining the
+	// number left in the current one; both are -1 when unknown.
+	Limit     int
+	Remaining int
+}
+
+// Budget returns the client's current rate-limit budget.
+func (c *Client) Budget() Budget {
+	return c.tokens.Budget()
+}
+
+// requestCounterKey carries a counter of the GitLab requests made with a
+// context.
+type requestCounterKey struct{}
+
+// WithRequestCounter returns a context that counts every GitLab request
+// made with it (retries with another token excluded) in n.
+func WithRequestCounter(ctx context.Context, n *atomic.Int64) context.Context {
+	return context.WithValue(ctx, requestCounterKey{}, n)
+}
@@ -6,6 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	goGitlab "gitlab.com/gitlab-org/api/client-go"
@@ -15,40 +18,123 @@ import (
 // rate limiter into a single entry-point for all GitLab API interactions.
 type Client struct {
 	rest        *goGitlab.Client
+	tokens      *TokenPool
+	transport   *tokenTransport
+	lastContact atomic.Int64 // unix nanoseconds of the last non-5xx response
 	rateLimiter *RateLimiter
 	features    *DetectedFeatures
+	permissions atomic.Pointer[PermissionMatrix]
 	logger      *logrus.Entry
 	baseURL     string
-	token       string
 	useGraphQL  bool
 }
 
 // New creates a new Client configured against the given GitLab instance.
 // rps and burst control the local token-bucket rate limiter (0 or negative
 // disables it). useGraphQL enables the GraphQL transport for batch queries.
+//
+// token is the main access token; additional pooled tokens can be added
+// with SetTokens. Every request is authenticated with a token picked from
+// the pool (see TokenPool).
 func New(baseURL, token string, rps, burst int, useGraphQL bool, logger *logrus.Entry) (*Client, error) {
-	rest, err := goGitlab.NewClient(token, goGitlab.WithBaseURL(baseURL))
+	c := &Client{
+		tokens:      NewTokenPool(token, logger.WithField("component", "token_pool")),
+		rateLimiter: NewRateLimiter(rps, burst, logger.WithField("component", "rate_limiter")),
+		logger:      logger,
+		baseURL:     baseURL,
+		useGraphQL:  useGraphQL,
+	}
+	c.transport = &tokenTransport{
+		pool:        c.tokens,
+		base:        http.DefaultTransport,
+		limiter:     c.rateLimiter,
+		lastContact: &c.lastContact,
+	}
+
+	rest, err := goGitlab.NewClient(token, goGitlab.WithBaseURL(baseURL), goGitlab.WithHTTPClient(&http.Client{Transport: c.transport}))
 	if err != nil {
 		return nil, fmt.Errorf("creating gitlab REST client: %w", err)
 	}
+	c.rest = rest
 
-	rl := NewRateLimiter(rps, burst, logger.WithField("component", "rate_limiter"))
-
-	return &Client{
-		rest:        rest,
-		rateLimiter: rl,
-		logger:      logger,
-		baseURL:     baseURL,
-		token:       token,
-		useGraphQL:  useGraphQL,
-	}, nil
+	return c, nil
 }
 
-// REST returns the underlying go-gitlab REST client.
+// REST returns the underlying go-gitlab REST client. Requests made through
+// it are authenticated by the token pool, so token changes performed via
+// SetToken and SetTokens apply to it immediately.
 func (c *Client) REST() *goGitlab.Client {
 	return c.rest
 }
 
+// SetToken replaces the main access token used for REST and GraphQL
+// requests. In-flight requests complete with the previous token.
+func (c *Client) SetToken(token string) {
+	c.tokens.SetMain(token)
+	c.logger.Info("gitlab access token updated")
+}
+
+// SetTokens replaces the additional pooled tokens, keeping the main token.
+func (c *Client) SetTokens(tokens []PoolToken) {
+	c.tokens.SetExtra(tokens)
+	c.logger.WithField("tokens", len(tokens)).Info("gitlab token pool updated")
+}
+
+// SetSharedLimiter shares the rate-limit budgets of the client's tokens
+// with other processes through shared (nil to stop sharing).
+func (c *Client) SetSharedLimiter(shared SharedLimiter) {
+	c.tokens.SetShared(shared)
+}
+
+// LastContact returns when GitLab last answered a request with a non-5xx
+// status, or the zero time if it never has.
+func (c *Client) LastContact() time.Time {
+	ns := c.lastContact.Load()
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// Tokens returns the client's token pool.
+func (c *Client) Tokens() *TokenPool {
+	return c.tokens
+}
+
+// TokenInfo describes one pooled token as reported by GitLab.
+type TokenInfo struct {
+	Name string
+	// ExpiresAt is zero for tokens that never expire.
+	ExpiresAt time.Time
+	Scopes    []string
+}
+
+// LookupTokens queries /personal_access_tokens/self with every token in
+// the pool. Group and project access tokens are personal access tokens of
+// bot users, so the endpoint covers them as well. Tokens whose lookup
+// fails are logged and omitted.
+func (c *Client) LookupTokens(ctx context.Context) []TokenInfo {
+	var out []TokenInfo
+	for _, name := range c.tokens.Names() {
+		if ctx.Err() != nil {
+			return out
+		}
+		pat, _, err := c.rest.PersonalAccessTokens.GetSinglePersonalAccessToken(
+			goGitlab.WithContext(withPinnedToken(ctx, name)),
+		)
+		if err != nil {
+			c.logger.WithError(err).WithField("token", name).Debug("failed to look up token")
+			continue
+		}
+		info := TokenInfo{Name: name, Scopes: pat.Scopes}
+		if pat.ExpiresAt != nil {
+			info.ExpiresAt = time.Time(*pat.ExpiresAt)
+		}
+		out = append(out, info)
+	}
+	return out
+}
+
 // Features returns the detected GitLab tier features, or nil if detection
 // has not been run yet.
 func (c *Client) Features() *DetectedFeatures {
@@ -61,11 +147,46 @@ func (c *Client) SetFeatures(f *DetectedFeatures) {
 	c.features = f
 }
 
+// DetectFeatures probes the GitLab instance for tier-dependent features and
+// stores the result on the client (see Features).
+func (c *Client) DetectFeatures(ctx context.Context) (*DetectedFeatures, error) {
+	features, err := NewTierDetector(c.rest, c.logger.WithField("component", "tier_detector")).Detect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.SetFeatures(features)
+	return features, nil
+}
+
+// Permissions returns the result of the last permission audit, or nil if
+// none has been run.
+func (c *Client) Permissions() *PermissionMatrix {
+	return c.permissions.Load()
+}
+
+// SetPermissions stores a permission audit result on the client so that
+// collectors can skip projects they are known not to be able to read.
+func (c *Client) SetPermissions(m *PermissionMatrix) {
+	c.permissions.Store(m)
+}
+
+// ProjectAllowed reports whether the named collector may read project
+// according to the last permission audit (true when unknown).
+func (c *Client) ProjectAllowed(collector, project string) bool {
+	return c.permissions.Load().Allowed(collector, project)
+}
+
 // RateLimiter returns the rate limiter associated with this client.
 func (c *Client) RateLimiter() *RateLimiter {
 	return c.rateLimiter
 }
 
+// GraphQLEnabled reports whether the GraphQL queries of the client may be
+// used.
+func (c *Client) GraphQLEnabled() bool {
+	return c.useGraphQL
+}
+
 // BaseURL returns the base URL of the GitLab instance.
 func (c *Client) BaseURL() string {
 	return c.baseURL
@@ -76,11 +197,6 @@ func (c *Client) BaseURL() string {
 // body is JSON-marshalled and sent as the request body if non-nil.
 // result, if non-nil, is JSON-unmarshalled from the response body.
 func (c *Client) DoREST(ctx context.Context, method, path string, body, result interface{}) (*goGitlab.Response, error) {
-	// Wait for rate limiter before issuing the request.
-	if err := c.rateLimiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limiter wait: %w", err)
-	}
-
 	var bodyReader io.Reader
 	if body != nil {
 		data, err := json.Marshal(body)
@@ -90,22 +206,18 @@ func (c *Client) DoREST(ctx context.Context, method, path string, body, result i
 		bodyReader = bytes.NewReader(data)
 	}
 
-	req, err := c.rest.NewRequest(method, path, bodyReader, nil)
+	rest := c.REST()
+	req, err := rest.NewRequest(method, path, bodyReader, nil)
 	if err != nil {
 		return nil, fmt.Errorf("building request: %w", err)
 	}
 	req = req.WithContext(ctx)
 
-	resp, err := c.rest.Do(req, nil)
+	resp, err := rest.Do(req, nil)
 	if err != nil && resp == nil {
 		return nil, fmt.Errorf("executing request: %w", err)
 	}
 
-	// Always update rate limiter from response headers.
-	if resp != nil {
-		c.rateLimiter.UpdateFromHeaders(resp.Header)
-	}
-
 	if result != nil && resp != nil && resp.Body != nil {
 		defer resp.Body.Close()
 		if decErr := json.NewDecoder(resp.Body).Decode(result); decErr != nil {
@@ -0,0 +1,312 @@
+package gitlab
+
+import (
+	"container/heap"
+	"context"
+	"regexp"
+	"sort"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultPriorityClass is the class of requests made with a context that
+// carries none, such as project discovery and tier detection.
+const DefaultPriorityClass = "default"
+
+// waitBucketBounds are the upper bounds (seconds) of the wait time
+// distribution reported in ClassStats.
+var waitBucketBounds = []float64{0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60}
+
+// PriorityClass is a share of the local request rate. While requests queue
+// behind the rate limiter, every class with waiting requests is served in
+// proportion to its Weight, so a busy low-weight class cannot starve a
+// high-weight one. Classes that are not configured have weight 1.
+type PriorityClass struct {
+	Name   string
+	Weight int
+}
+
+// EndpointLimit is an additional token bucket for requests whose URL path
+// matches Pattern, for GitLab endpoints with their own throttle (such as
+// the project list or raw file downloads). Matching requests wait for it
+// before they queue for the client-wide rate.
+type EndpointLimit struct {
+	Name    string
+	Pattern *regexp.Regexp
+	RPS     int
+	Burst   int
+}
+
+// ClassStats reports the queueing of one priority class.
+type ClassStats struct {
+	Class string
+	// Queued is the number of requests currently waiting.
+	Queued int
+	// Requests counts the requests that passed the limiter and WaitSeconds
+	// their total wait. WaitBuckets maps the bounds of waitBucketBounds to
+	// the cumulative number of requests that waited at most that long.
+	Requests    uint64
+	WaitSeconds float64
+	WaitBuckets map[float64]uint64
+}
+
+// priorityClassKey carries the priority class of the requests made with a
+// context.
+type priorityClassKey struct{}
+
+// WithPriorityClass returns a context whose GitLab requests queue in the
+// named priority class.
+func WithPriorityClass(ctx context.Context, class string) context.Context {
+	return context.WithValue(ctx, priorityClassKey{}, class)
+}
+
+// priorityClass returns the priority class carried by ctx.
+func priorityClass(ctx context.Context) string {
+	if class, ok := ctx.Value(priorityClassKey{}).(string); ok && class != "" {
+		return class
+	}
+	return DefaultPriorityClass
+}
+
+// classQueue is the fair-queuing state and statistics of one class.
+type classQueue struct {
+	weight int
+	// finish is the virtual finish tag of the class's last queued request.
+	finish float64
+
+	queued      int
+	requests    uint64
+	waitSeconds float64
+	waitBuckets []uint64
+}
+
+// waiter is a request queued for a token of the local bucket.
+type waiter struct {
+	tag       float64
+	seq       uint64
+	ready     chan struct{}
+	granted   bool
+	cancelled bool
+}
+
+// waiterHeap orders waiters by virtual finish tag, then arrival.
+type waiterHeap []*waiter
+
+func (h waiterHeap) Len() int { return len(h) }
+func (h waiterHeap) Less(i, j int) bool {
+	if h[i].tag != h[j].tag {
+		return h[i].tag < h[j].tag
+	}
+	return h[i].seq < h[j].seq
+}
+func (h waiterHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *waiterHeap) Push(x any)   { *h = append(*h, x.(*waiter)) }
+func (h *waiterHeap) Pop() any {
+	old := *h
+	w := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return w
+}
+
+// endpointBucket is the token bucket of one EndpointLimit.
+type endpointBucket struct {
+	name    string
+	pattern *regexp.Regexp
+	limiter *rate.Limiter
+}
+
+// SetPriorityClasses replaces the class weights. Classes that are not
+// listed fall back to weight 1; their statistics are kept.
+func (rl *RateLimiter) SetPriorityClasses(classes []PriorityClass) {
+	rl.qmu.Lock()
+	defer rl.qmu.Unlock()
+	for _, cq := range rl.classes {
+		cq.weight = 1
+	}
+	for _, c := range classes {
+		rl.class(c.Name).weight = max(c.Weight, 1)
+	}
+}
+
+// SetEndpointLimits replaces the per-endpoint token buckets. Buckets whose
+// name is unchanged keep their tokens.
+func (rl *RateLimiter) SetEndpointLimits(limits []EndpointLimit) {
+	rl.qmu.Lock()
+	defer rl.qmu.Unlock()
+
+	existing := make(map[string]*rate.Limiter, len(rl.endpoints))
+	for _, b := range rl.endpoints {
+		existing[b.name] = b.limiter
+	}
+
+	buckets := make([]*endpointBucket, 0, len(limits))
+	for _, l := range limits {
+		limit, burst := rate.Inf, 0
+		if l.RPS > 0 {
+			limit, burst = rate.Limit(l.RPS), max(l.Burst, 1)
+		}
+		limiter, ok := existing[l.Name]
+		if ok {
+			limiter.SetLimit(limit)
+			limiter.SetBurst(burst)
+		} else {
+			limiter = rate.NewLimiter(limit, burst)
+		}
+		buckets = append(buckets, &endpointBucket{name: l.Name, pattern: l.Pattern, limiter: limiter})
+	}
+	rl.endpoints = buckets
+}
+
+// ClassStats returns the queueing statistics of every class that has seen
+// a request, sorted by class name.
+func (rl *RateLimiter) ClassStats() []ClassStats {
+	rl.qmu.Lock()
+	defer rl.qmu.Unlock()
+
+	out := make([]ClassStats, 0, len(rl.classes))
+	for name, cq := range rl.classes {
+		if cq.requests == 0 && cq.queued == 0 {
+			continue
+		}
+		buckets := make(map[float64]uint64, len(waitBucketBounds))
+		for i, bound := range waitBucketBounds {
+			buckets[bound] = cq.waitBuckets[i]
+		}
+		out = append(out, ClassStats{
+			Class:       name,
+			Queued:      cq.queued,
+			Requests:    cq.requests,
+			WaitSeconds: cq.waitSeconds,
+			WaitBuckets: buckets,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Class < out[j].Class })
+	return out
+}
+
+// class returns the queue of the named class, creating it with weight 1.
+// rl.qmu must be held.
+func (rl *RateLimiter) class(name string) *classQueue {
+	cq, ok := rl.classes[name]
+	if !ok {
+		cq = &classQueue{weight: 1, waitBuckets: make([]uint64, len(waitBucketBounds))}
+		rl.classes[name] = cq
+	}
+	return cq
+}
+
+// waitEndpoints blocks until every endpoint bucket matching path has a
+// token.
+func (rl *RateLimiter) waitEndpoints(ctx context.Context, path string) error {
+	rl.qmu.Lock()
+	endpoints := rl.endpoints
+	rl.qmu.Unlock()
+
+	for _, b := range endpoints {
+		if path == "" || !b.pattern.MatchString(path) {
+			continue
+		}
+		if err := b.limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitFair queues the request in its class and blocks until the dispatcher
+// hands it a token of the local bucket.
+func (rl *RateLimiter) waitFair(ctx context.Context, class string) error {
+	if rl.local.Limit() == rate.Inf {
+		return nil
+	}
+
+	rl.qmu.Lock()
+	cq := rl.class(class)
+	// Start-time fair queuing: a request finishes 1/weight after the later
+	// of the current virtual time and its class's previous request.
+	w := &waiter{
+		tag:   max(rl.vtime, cq.finish) + 1/float64(cq.weight),
+		seq:   rl.seq,
+		ready: make(chan struct{}),
+	}
+	rl.seq++
+	cq.finish = w.tag
+	heap.Push(&rl.waiting, w)
+	if !rl.dispatching {
+		rl.dispatching = true
+		go rl.dispatch()
+	}
+	rl.qmu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		rl.qmu.Lock()
+		defer rl.qmu.Unlock()
+		if !w.granted {
+			w.cancelled = true
+		}
+		return ctx.Err()
+	}
+}
+
+// dispatch hands out local bucket tokens to the waiter with the smallest
+// virtual finish tag until no request is waiting. Taking the token before
+// picking the waiter lets requests that arrive meanwhile compete for it.
+func (rl *RateLimiter) dispatch() {
+	for {
+		rl.qmu.Lock()
+		rl.dropCancelled()
+		if rl.waiting.Len() == 0 {
+			rl.dispatching = false
+			rl.qmu.Unlock()
+			return
+		}
+		rl.qmu.Unlock()
+
+		// The bucket's burst is at least 1, so this only returns once a
+		// token is available.
+		_ = rl.local.Wait(context.Background())
+
+		rl.qmu.Lock()
+		rl.dropCancelled()
+		if rl.waiting.Len() > 0 {
+			w := heap.Pop(&rl.waiting).(*waiter)
+			w.granted = true
+			rl.vtime = w.tag
+			close(w.ready)
+		}
+		rl.qmu.Unlock()
+	}
+}
+
+// dropCancelled removes cancelled waiters from the head of the queue.
+// rl.qmu must be held.
+func (rl *RateLimiter) dropCancelled() {
+	for rl.waiting.Len() > 0 && rl.waiting[0].cancelled {
+		heap.Pop(&rl.waiting)
+	}
+}
+
+// trackQueued adjusts the number of requests of a class that are waiting.
+func (rl *RateLimiter) trackQueued(class string, delta int) {
+	rl.qmu.Lock()
+	rl.class(class).queued += delta
+	rl.qmu.Unlock()
+}
+
+// observeWait records the wait of a request that passed the limiter.
+func (rl *RateLimiter) observeWait(class string, wait time.Duration) {
+	rl.qmu.Lock()
+	defer rl.qmu.Unlock()
+	cq := rl.class(class)
+	cq.requests++
+	cq.waitSeconds += wait.Seconds()
+	for i, bound := range waitBucketBounds {
+		if wait.Seconds() <= bound {
+			cq.waitBuckets[i]++
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package gitlab
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// grantOrder queues one request per class name, in order, behind a local
+// bucket that is in debt, and returns the classes in the order the
+// dispatcher let them through.
+func grantOrder(t *testing.T, rl *RateLimiter, classes []string) []string {
+	t.Helper()
+
+	// Put the bucket in debt so that every request is queued before the
+	// dispatcher hands out the first token.
+	indebt(rl)
+
+	var (
+		mu    sync.Mutex
+		order []string
+		wg    sync.WaitGroup
+	)
+	for i, class := range classes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := rl.wait(WithPriorityClass(context.Background(), class), ""); err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			order = append(order, class)
+			mu.Unlock()
+		}()
+		for {
+			rl.qmu.Lock()
+			n := rl.waiting.Len()
+			rl.qmu.Unlock()
+			if n == i+1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	wg.Wait()
+	return order
+}
+
+// indebt reserves the next 100ms of tokens of the local bucket.
+func indebt(rl *RateLimiter) {
+	for range 5 {
+		rl.local.Reserve()
+	}
+}
+
+func TestFairQueueServesHigherWeightFirst(t *testing.T) {
+	rl := NewRateLimiter(50, 1, testLogger())
+	rl.SetPriorityClasses([]PriorityClass{{Name: "interactive", Weight: 4}})
+
+	got := grantOrder(t, rl, []string{"bulk", "bulk", "bulk", "bulk", "interactive", "interactive"})
+	want := []string{"interactive", "interactive", "bulk", "bulk", "bulk", "bulk"}
+	if !slices.Equal(got, want) {
+		t.Errorf("grant order = %v, want %v", got, want)
+	}
+}
+
+func TestFairQueueInterleavesEqualWeights(t *testing.T) {
+	rl := NewRateLimiter(50, 1, testLogger())
+
+	got := grantOrder(t, rl, []string{"a", "a", "a", "a", "b", "b"})
+	want := []string{"a", "b", "a", "b", "a", "a"}
+	if !slices.Equal(got, want) {
+		t.Errorf("grant order = %v, want the late class interleaved: %v", got, want)
+	}
+
+	stats := rl.ClassStats()
+	if len(stats) != 2 || stats[0].Class != "a" || stats[0].Requests != 4 || stats[1].Class != "b" || stats[1].Requests != 2 {
+		t.Errorf("ClassStats() = %+v, want 4 requests of a and 2 of b", stats)
+	}
+	for _, s := range stats {
+		if s.Queued != 0 {
+			t.Errorf("class %s still has %d queued requests", s.Class, s.Queued)
+		}
+	}
+}
+
+func TestFairQueueSkipsCancelledWaiters(t *testing.T) {
+	rl := NewRateLimiter(50, 1, testLogger())
+	indebt(rl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := rl.wait(ctx, ""); err != context.Canceled {
+		t.Fatalf("wait with a cancelled context = %v, want context.Canceled", err)
+	}
+	if err := rl.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait after a cancelled waiter = %v", err)
+	}
+}
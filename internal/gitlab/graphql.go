@@ -9,21 +9,6 @@ import (
 	graphql "github.com/hasura/go-graphql-client"
 )
 
-// --------------------------------------------------------------------------
-// GraphQL transport (adds the PRIVATE-TOKEN header)
-// --------------------------------------------------------------------------
-
-type tokenTransport struct {
-	token string
-	base  http.RoundTripper
-}
-
-func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	req = req.Clone(req.Context())
-	req.Header.Set("PRIVATE-TOKEN", t.token)
-	return t.base.RoundTrip(req)
-}
-
 // --------------------------------------------------------------------------
 // GraphQL client wrapper
 // --------------------------------------------------------------------------
@@ -34,14 +19,11 @@ type graphQLClient struct {
 }
 
 // newGraphQLClient creates a GraphQL client targeting the given GitLab
-// instance. The token is injected via a custom HTTP transport.
-func newGraphQLClient(baseURL, token string) *graphQLClient {
+// instance. Tokens are injected by the client's token transport.
+func newGraphQLClient(baseURL string, transport http.RoundTripper) *graphQLClient {
 	httpClient := &http.Client{
-		Transport: &tokenTransport{
-			token: token,
-			base:  http.DefaultTransport,
-		},
-		Timeout: 30 * time.Second,
+		Transport: transport,
+		Timeout:   30 * time.Second,
 	}
 
 	endpoint := baseURL + "/api/graphql"
@@ -92,6 +74,37 @@ type MergeRequestNode struct {
 	WebURL       string  `graphql:"webUrl"`
 }
 
+// AlertNode is the GraphQL representation of an alert management alert.
+type AlertNode struct {
+	IID       string  `graphql:"iid"`
+	Status    string  `graphql:"status"`
+	Severity  string  `graphql:"severity"`
+	StartedAt string  `graphql:"startedAt"`
+	EndedAt   *string `graphql:"endedAt"`
+}
+
+// VulnerabilityNode is the GraphQL representation of a vulnerability from
+// the vulnerability report. Severity, State and ReportType are upper-case
+// enum values such as "CRITICAL", "DETECTED" and "SAST".
+type VulnerabilityNode struct {
+	Severity   string  `graphql:"severity"`
+	State      string  `graphql:"state"`
+	ReportType string  `graphql:"reportType"`
+	DetectedAt string  `graphql:"detectedAt"`
+	ResolvedAt *string `graphql:"resolvedAt"`
+}
+
+// VulnerabilitySeverityCounts holds the number of vulnerabilities of each
+// severity, as returned by vulnerabilitySeveritiesCount.
+type VulnerabilitySeverityCounts struct {
+	Critical int `graphql:"critical"`
+	High     int `graphql:"high"`
+	Medium   int `graphql:"medium"`
+	Low      int `graphql:"low"`
+	Info     int `graphql:"info"`
+	Unknown  int `graphql:"unknown"`
+}
+
 // ProjectWithPipelines is a convenience type combining a project with its
 // most recent pipelines, as returned by the batch GraphQL query.
 type ProjectWithPipelines struct {
@@ -111,16 +124,12 @@ func (c *Client) FetchProjectsWithPipelines(ctx context.Context, projectPaths []
 		return nil, fmt.Errorf("GraphQL is not enabled on this client")
 	}
 
-	gql := newGraphQLClient(c.baseURL, c.token)
+	gql := newGraphQLClient(c.baseURL, c.transport)
 	results := make([]ProjectWithPipelines, 0, len(projectPaths))
 
 	// GraphQL doesn't natively support dynamic aliases in the hasura client,
 	// so we query each project individually but reuse the same connection.
 	for _, path := range projectPaths {
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return nil, fmt.Errorf("rate limiter: %w", err)
-		}
-
 		var query struct {
 			Project struct {
 				ID          string `graphql:"id"`
@@ -170,11 +179,7 @@ func (c *Client) FetchProjectMergeRequests(ctx context.Context, projectPath stri
 		return nil, fmt.Errorf("GraphQL is not enabled on this client")
 	}
 
-	if err := c.rateLimiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limiter: %w", err)
-	}
-
-	gql := newGraphQLClient(c.baseURL, c.token)
+	gql := newGraphQLClient(c.baseURL, c.transport)
 
 	var query struct {
 		Project struct {
@@ -196,3 +201,165 @@ func (c *Client) FetchProjectMergeRequests(ctx context.Context, projectPath stri
 
 	return query.Project.MergeRequests.Nodes, nil
 }
+
+// ciRunnerID is a GitLab global ID of a CI runner.
+type ciRunnerID string
+
+// GetGraphQLType implements graphql.GraphQLType.
+func (ciRunnerID) GetGraphQLType() string { return "CiRunnerID" }
+
+// FetchRunnerExecutor returns the executor a runner last advertised (e.g.
+// "docker" or "kubernetes"), which the REST API does not expose. It
+// returns an empty string when the runner has not reported one.
+func (c *Client) FetchRunnerExecutor(ctx context.Context, runnerID int) (string, error) {
+	if !c.useGraphQL {
+		return "", fmt.Errorf("GraphQL is not enabled on this client")
+	}
+
+	gql := newGraphQLClient(c.baseURL, c.transport)
+
+	var query struct {
+		Runner struct {
+			ExecutorName string `graphql:"executorName"`
+		} `graphql:"runner(id: $id)"`
+	}
+
+	variables := map[string]interface{}{
+		"id": ciRunnerID(fmt.Sprintf("gid://gitlab/Ci::Runner/%d", runnerID)),
+	}
+
+	if err := gql.client.Query(ctx, &query, variables); err != nil {
+		return "", fmt.Errorf("GraphQL: fetching executor of runner %d: %w", runnerID, err)
+	}
+
+	return query.Runner.ExecutorName, nil
+}
+
+// FetchProjectAlerts returns the open (triggered or acknowledged) alert
+// management alerts of a project and its most recently resolved ones.
+// first bounds both lists. Alerts have no REST listing endpoint.
+func (c *Client) FetchProjectAlerts(ctx context.Context, projectPath string, first int) (open, resolved []AlertNode, err error) {
+	if !c.useGraphQL {
+		return nil, nil, fmt.Errorf("GraphQL is not enabled on this client")
+	}
+
+	gql := newGraphQLClient(c.baseURL, c.transport)
+
+	var query struct {
+		Project struct {
+			Open struct {
+				Nodes []AlertNode `graphql:"nodes"`
+			} `graphql:"open: alertManagementAlerts(statuses: [TRIGGERED, ACKNOWLEDGED], first: $first)"`
+			Resolved struct {
+				Nodes []AlertNode `graphql:"nodes"`
+			} `graphql:"resolved: alertManagementAlerts(statuses: [RESOLVED], sort: ENDED_AT_DESC, first: $first)"`
+		} `graphql:"project(fullPath: $path)"`
+	}
+
+	variables := map[string]interface{}{
+		"path":  graphql.String(projectPath),
+		"first": graphql.Int(first),
+	}
+
+	if err := gql.client.Query(ctx, &query, variables); err != nil {
+		return nil, nil, fmt.Errorf("GraphQL: fetching alerts for %s: %w", projectPath, err)
+	}
+
+	return query.Project.Open.Nodes, query.Project.Resolved.Nodes, nil
+}
+
+// vulnerabilityState is a GitLab VulnerabilityState enum value, such as
+// "DETECTED".
+type vulnerabilityState string
+
+// GetGraphQLType implements graphql.GraphQLType.
+func (vulnerabilityState) GetGraphQLType() string { return "VulnerabilityState" }
+
+// FetchProjectVulnerabilities returns the vulnerabilities of a project's
+// vulnerability report in the given states (upper-case enum values such as
+// "DETECTED"), most recently detected first, following the connection
+// cursor. When more is non-nil, paging stops after the first page for
+// which it returns false.
+func (c *Client) FetchProjectVulnerabilities(ctx context.Context, projectPath string, states []string, more func(page []VulnerabilityNode) bool) ([]VulnerabilityNode, error) {
+	if !c.useGraphQL {
+		return nil, fmt.Errorf("GraphQL is not enabled on this client")
+	}
+
+	gql := newGraphQLClient(c.baseURL, c.transport)
+
+	stateValues := make([]vulnerabilityState, len(states))
+	for i, s := range states {
+		stateValues[i] = vulnerabilityState(s)
+	}
+
+	var (
+		nodes []VulnerabilityNode
+		after *graphql.String
+	)
+	for {
+		var query struct {
+			Project struct {
+				Vulnerabilities struct {
+					Nodes    []VulnerabilityNode `graphql:"nodes"`
+					PageInfo struct {
+						HasNextPage bool   `graphql:"hasNextPage"`
+						EndCursor   string `graphql:"endCursor"`
+					} `graphql:"pageInfo"`
+				} `graphql:"vulnerabilities(state: $state, sort: detected_desc, first: 100, after: $after)"`
+			} `graphql:"project(fullPath: $path)"`
+		}
+
+		variables := map[string]interface{}{
+			"path":  graphql.ID(projectPath),
+			"state": stateValues,
+			"after": after,
+		}
+
+		if err := gql.client.Query(ctx, &query, variables); err != nil {
+			return nil, fmt.Errorf("GraphQL: fetching vulnerabilities for %s: %w", projectPath, err)
+		}
+
+		page := query.Project.Vulnerabilities
+		nodes = append(nodes, page.Nodes...)
+		if !page.PageInfo.HasNextPage || (more != nil && !more(page.Nodes)) {
+			return nodes, nil
+		}
+		cursor := graphql.String(page.PageInfo.EndCursor)
+		after = &cursor
+	}
+}
+
+// FetchGroupVulnerabilityCounts returns the vulnerability counts by
+// severity of a group and its subgroups, keyed by lower-case state
+// ("detected", "confirmed", "dismissed" and "resolved").
+func (c *Client) FetchGroupVulnerabilityCounts(ctx context.Context, groupPath string) (map[string]VulnerabilitySeverityCounts, error) {
+	if !c.useGraphQL {
+		return nil, fmt.Errorf("GraphQL is not enabled on this client")
+	}
+
+	gql := newGraphQLClient(c.baseURL, c.transport)
+
+	var query struct {
+		Group struct {
+			Detected  VulnerabilitySeverityCounts `graphql:"detected: vulnerabilitySeveritiesCount(state: [DETECTED])"`
+			Confirmed VulnerabilitySeverityCounts `graphql:"confirmed: vulnerabilitySeveritiesCount(state: [CONFIRMED])"`
+			Dismissed VulnerabilitySeverityCounts `graphql:"dismissed: vulnerabilitySeveritiesCount(state: [DISMISSED])"`
+			Resolved  VulnerabilitySeverityCounts `graphql:"resolved: vulnerabilitySeveritiesCount(state: [RESOLVED])"`
+		} `graphql:"group(fullPath: $path)"`
+	}
+
+	variables := map[string]interface{}{
+		"path": graphql.ID(groupPath),
+	}
+
+	if err := gql.client.Query(ctx, &query, variables); err != nil {
+		return nil, fmt.Errorf("GraphQL: fetching vulnerability counts for %s: %w", groupPath, err)
+	}
+
+	return map[string]VulnerabilitySeverityCounts{
+		"detected":  query.Group.Detected,
+		"confirmed": query.Group.Confirmed,
+		"dismissed": query.Group.Dismissed,
+		"resolved":  query.Group.Resolved,
+	}, nil
+}
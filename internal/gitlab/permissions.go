@@ -0,0 +1,334 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	goGitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// Project membership access levels as reported by the GitLab API.
+const (
+	AccessNone       = 0
+	AccessGuest      = 10
+	AccessReporter   = 20
+	AccessDeveloper  = 30
+	AccessMaintainer = 40
+	AccessOwner      = 50
+)
+
+// Requirement is what a collector needs to read a project: a token with the
+// read_api (or api) scope, the project feature it reads from not being
+// disabled, and at least MinAccessLevel membership unless the feature is
+// readable by everyone on a public or internal project.
+type Requirement struct {
+	// Feature is the project feature access setting the collector depends
+	// on (e.g. "builds"), or empty when it depends on none.
+	Feature        string
+	MinAccessLevel int
+	// MembershipRequired drops the public/internal project exception, for
+	// endpoints that always check membership (e.g. project runners).
+	MembershipRequired bool
+}
+
+// CollectorRequirements maps collector names to their access requirements.
+var CollectorRequirements = map[string]Requirement{
+	"pipelines":          {Feature: "builds", MinAccessLevel: AccessReporter},
+	"jobs":               {Feature: "builds", MinAccessLevel: AccessReporter},
+	"test_reports":       {Feature: "builds", MinAccessLevel: AccessReporter},
+	"merge_requests":     {Feature: "merge_requests", MinAccessLevel: AccessReporter},
+	"code_review":        {Feature: "merge_requests", MinAccessLevel: AccessReporter},
+	"environments":       {Feature: "environments", MinAccessLevel: AccessReporter},
+	"repository":         {Feature: "repository", MinAccessLevel: AccessReporter},
+	"contributors":       {Feature: "repository", MinAccessLevel: AccessReporter},
+	"dora":               {Feature: "analytics", MinAccessLevel: AccessReporter},
+	"value_stream":       {Feature: "analytics", MinAccessLevel: AccessReporter},
+	"runners":            {Feature: "builds", MinAccessLevel: AccessMaintainer, MembershipRequired: true},
+	"job_queue":          {Feature: "builds", MinAccessLevel: AccessReporter},
+	"issues":             {Feature: "issues", MinAccessLevel: AccessGuest},
+	"incidents":          {Feature: "issues", MinAccessLevel: AccessGuest},
+	"releases":           {Feature: "repository", MinAccessLevel: AccessReporter},
+	"container_registry": {Feature: "container_registry", MinAccessLevel: AccessReporter},
+	"packages":           {Feature: "packages", MinAccessLevel: AccessReporter},
+	"vulnerabilities":    {MinAccessLevel: AccessDeveloper, MembershipRequired: true},
+	"pipeline_schedules": {Feature: "builds", MinAccessLevel: AccessReporter},
+	"merge_trains":       {Feature: "merge_requests", MinAccessLevel: AccessDeveloper, MembershipRequired: true},
+	"compliance":         {Feature: "repository", MinAccessLevel: AccessMaintainer, MembershipRequired: true},
+	"milestones":         {Feature: "issues", MinAccessLevel: AccessGuest},
+}
+
+// ProjectAccess is what the audit learned about one project.
+type ProjectAccess struct {
+	// Reachable is false when the project could not be fetched; Status then
+	// holds the HTTP status (0 for network errors).
+	Reachable   bool              `json:"reachable"`
+	Status      int               `json:"status,omitempty"`
+	AccessLevel int               `json:"access_level"`
+	Visibility  string            `json:"visibility,omitempty"`
+	Features    map[string]string `json:"features,omitempty"`
+	// Scopes are the scopes shared by every token that may serve this
+	// project; nil when they could not be determined.
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// PermissionResult is the verdict for one collector on one project.
+type PermissionResult struct {
+	OK     bool   `json:"ok"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// PermissionMatrix holds the per-collector, per-project audit results.
+type PermissionMatrix struct {
+	mu       sync.RWMutex
+	admin    bool
+	projects map[string]*ProjectAccess
+	results  map[string]map[string]PermissionResult // collector -> project
+
+	// adminKnown is true when the current user could be looked up.
+	adminKnown bool
+}
+
+// Allowed reports whether collector is expected to be able to read project.
+// Projects and collectors the audit has no verdict for are allowed.
+func (m *PermissionMatrix) Allowed(collector, project string) bool {
+	if m == nil {
+		return true
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	res, ok := m.results[collector][project]
+	return !ok || res.OK
+}
+
+// Admin reports whether the token belongs to an administrator; known is
+// false when the audit could not look the user up.
+func (m *PermissionMatrix) Admin() (admin, known bool) {
+	if m == nil {
+		return false, false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.admin, m.adminKnown
+}
+
+// Results returns a copy of the collector -> project -> result matrix.
+func (m *PermissionMatrix) Results() map[string]map[string]PermissionResult {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[string]map[string]PermissionResult, len(m.results))
+	for collector, projects := range m.results {
+		cp := make(map[string]PermissionResult, len(projects))
+		for p, r := range projects {
+			cp[p] = r
+		}
+		out[collector] = cp
+	}
+	return out
+}
+
+// PermissionReport is the JSON form of a PermissionMatrix.
+type PermissionReport struct {
+	Admin    bool                                   `json:"admin"`
+	Projects map[string]*ProjectAccess              `json:"projects"`
+	Matrix   map[string]map[string]PermissionResult `json:"matrix"`
+}
+
+// Report returns the matrix in a form suitable for JSON encoding.
+func (m *PermissionMatrix) Report() PermissionReport {
+	results := m.Results()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	projects := make(map[string]*ProjectAccess, len(m.projects))
+	for p, pa := range m.projects {
+		projects[p] = pa
+	}
+	return PermissionReport{Admin: m.admin, Projects: projects, Matrix: results}
+}
+
+// Denied returns, per collector, the sorted projects it is not allowed to
+// read.
+func (m *PermissionMatrix) Denied() map[string][]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[string][]string)
+	for collector, projects := range m.results {
+		for p, r := range projects {
+			if !r.OK {
+				out[collector] = append(out[collector], p)
+			}
+		}
+		sort.Strings(out[collector])
+	}
+	return out
+}
+
+// AuditPermissions inspects the scopes of every pooled token and the
+// token's role on each project, and evaluates CollectorRequirements
+// against them. Lookups that fail for reasons other than a definitive
+// 401/403/404 leave the affected projects allowed.
+func (c *Client) AuditPermissions(ctx context.Context, projects []string) *PermissionMatrix {
+	m := &PermissionMatrix{
+		projects: make(map[string]*ProjectAccess, len(projects)),
+		results:  make(map[string]map[string]PermissionResult, len(CollectorRequirements)),
+	}
+
+	user, _, err := c.rest.Users.CurrentUser(goGitlab.WithContext(ctx))
+	if err == nil {
+		m.admin, m.adminKnown = user.IsAdmin, true
+	}
+
+	scopes := make(map[string][]string)
+	for _, info := range c.LookupTokens(ctx) {
+		scopes[info.Name] = info.Scopes
+	}
+
+	for _, project := range projects {
+		if ctx.Err() != nil {
+			break
+		}
+		pa := c.projectAccess(ctx, project)
+		pa.Scopes = sharedScopes(c.tokens.candidates(project), scopes)
+		m.projects[project] = pa
+
+		for collector, req := range CollectorRequirements {
+			if m.results[collector] == nil {
+				m.results[collector] = make(map[string]PermissionResult, len(projects))
+			}
+			m.results[collector][project] = evaluate(req, pa, m.admin)
+		}
+	}
+
+	return m
+}
+
+// projectAccess fetches a project and extracts the token's access to it.
+func (c *Client) projectAccess(ctx context.Context, project string) *ProjectAccess {
+	pa := &ProjectAccess{}
+	p, resp, err := c.rest.Projects.GetProject(project, nil, goGitlab.WithContext(ctx))
+	if err != nil {
+		if resp != nil {
+			pa.Status = resp.StatusCode
+		}
+		return pa
+	}
+
+	pa.Reachable = true
+	pa.Visibility = string(p.Visibility)
+	if perms := p.Permissions; perms != nil {
+		if perms.ProjectAccess != nil {
+			pa.AccessLevel = int(perms.ProjectAccess.AccessLevel)
+		}
+		if perms.GroupAccess != nil && int(perms.GroupAccess.AccessLevel) > pa.AccessLevel {
+			pa.AccessLevel = int(perms.GroupAccess.AccessLevel)
+		}
+	}
+	pa.Features = map[string]string{
+		"builds":             string(p.BuildsAccessLevel),
+		"merge_requests":     string(p.MergeRequestsAccessLevel),
+		"environments":       string(p.EnvironmentsAccessLevel),
+		"repository":         string(p.RepositoryAccessLevel),
+		"analytics":          string(p.AnalyticsAccessLevel),
+		"issues":             string(p.IssuesAccessLevel),
+		"container_registry": string(p.ContainerRegistryAccessLevel),
+	}
+	// The package registry is a plain on/off setting rather than an access
+	// level; map it onto the same vocabulary.
+	if p.PackagesEnabled {
+		pa.Features["packages"] = string(goGitlab.EnabledAccessControl)
+	} else {
+		pa.Features["packages"] = string(goGitlab.DisabledAccessControl)
+	}
+	return pa
+}
+
+// sharedScopes returns the scopes common to every token in names whose
+// scopes are known, or nil when none are.
+func sharedScopes(names []string, scopes map[string][]string) []string {
+	var shared map[string]bool
+	for _, name := range names {
+		s, ok := scopes[name]
+		if !ok {
+			continue
+		}
+		set := make(map[string]bool, len(s))
+		for _, scope := range s {
+			if shared == nil || shared[scope] {
+				set[scope] = true
+			}
+		}
+		shared = set
+	}
+	if shared == nil {
+		return nil
+	}
+	out := make([]string, 0, len(shared))
+	for scope := range shared {
+		out = append(out, scope)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// evaluate applies a collector requirement to a project.
+func evaluate(req Requirement, pa *ProjectAccess, admin bool) PermissionResult {
+	if !pa.Reachable {
+		switch pa.Status {
+		case http.StatusUnauthorized:
+			return PermissionResult{Reason: "token rejected (401)"}
+		case http.StatusForbidden:
+			return PermissionResult{Reason: "project access forbidden (403)"}
+		case http.StatusNotFound:
+			return PermissionResult{Reason: "project not found or not visible to the token (404)"}
+		}
+		return PermissionResult{OK: true, Reason: "project access could not be checked"}
+	}
+
+	if pa.Scopes != nil && !hasScope(pa.Scopes, "api") && !hasScope(pa.Scopes, "read_api") {
+		return PermissionResult{Reason: "token lacks the read_api scope"}
+	}
+
+	feature := pa.Features[req.Feature]
+	if feature == string(goGitlab.DisabledAccessControl) {
+		return PermissionResult{Reason: fmt.Sprintf("%s feature is disabled", req.Feature)}
+	}
+
+	if admin || pa.AccessLevel >= req.MinAccessLevel {
+		return PermissionResult{OK: true}
+	}
+	if !req.MembershipRequired && pa.Visibility != string(goGitlab.PrivateVisibility) &&
+		(feature == "" || feature == string(goGitlab.EnabledAccessControl)) {
+		return PermissionResult{OK: true}
+	}
+	return PermissionResult{Reason: fmt.Sprintf("requires %s access (has %s)",
+		accessLevelName(req.MinAccessLevel), accessLevelName(pa.AccessLevel))}
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// accessLevelName returns the role name for a membership access level.
+func accessLevelName(level int) string {
+	switch {
+	case level >= AccessOwner:
+		return "Owner"
+	case level >= AccessMaintainer:
+		return "Maintainer"
+	case level >= AccessDeveloper:
+		return "Developer"
+	case level >= AccessReporter:
+		return "Reporter"
+	case level >= AccessGuest:
+		return "Guest"
+	default:
+		return "no membership"
+	}
+}
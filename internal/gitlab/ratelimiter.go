@@ -14,7 +14,9 @@ import (
 
 // RateLimiter combines a local token bucket with header-aware backoff
 // derived from GitLab's RateLimit-Remaining and RateLimit-Reset response headers.
-// It is safe for concurrent use.
+// Requests waiting for the local bucket are served by weighted fair queuing
+// between priority classes, and optional per-endpoint buckets throttle
+// individual endpoints (see fairqueue.go). It is safe for concurrent use.
 type RateLimiter struct {
 	mu sync.Mutex
 
@@ -28,10 +30,29 @@ type RateLimiter struct {
 	// headerRemaining is the last observed RateLimit-Remaining value.
 	headerRemaining int
 
+	// headerLimit is the last observed RateLimit-Limit value.
+	headerLimit int
+
 	// backoffUntil is the time until which we should wait because the
 	// remote limit is nearly (or fully) exhausted.
 	backoffUntil time.Time
 
+	// shared, if set, is the budget shared with other processes using the
+	// same token, stored under sharedKey (see shared.go). It is skipped
+	// until sharedRetryAt after a failure.
+	shared        SharedLimiter
+	sharedKey     string
+	sharedRetryAt time.Time
+
+	// Fair queuing state and per-endpoint buckets, guarded by qmu.
+	qmu         sync.Mutex
+	classes     map[string]*classQueue
+	waiting     waiterHeap
+	vtime       float64
+	seq         uint64
+	dispatching bool
+	endpoints   []*endpointBucket
+
 	logger *logrus.Entry
 }
 
@@ -50,14 +71,43 @@ func NewRateLimiter(rps int, burst int, logger *logrus.Entry) *RateLimiter {
 	return &RateLimiter{
 		local:           limiter,
 		headerRemaining: -1, // unknown
+		headerLimit:     -1, // unknown
+		classes:         make(map[string]*classQueue),
 		logger:          logger,
 	}
 }
 
+// SetLimit updates the local token bucket's rate and burst. As with
+// NewRateLimiter, a zero or negative rps disables local rate limiting.
+func (rl *RateLimiter) SetLimit(rps int, burst int) {
+	if rps <= 0 {
+		rl.local.SetLimit(rate.Inf)
+		rl.local.SetBurst(0)
+		return
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	rl.local.SetLimit(rate.Limit(rps))
+	rl.local.SetBurst(burst)
+}
+
 // Wait blocks until the rate limiter allows one more request, honouring
-// both the local token bucket and any header-derived backoff.  It returns
-// ctx.Err() if the context expires while waiting.
+// both the local token bucket and any header-derived backoff.  The request
+// queues in the priority class carried by ctx (see WithPriorityClass).  It
+// returns ctx.Err() if the context expires while waiting.
 func (rl *RateLimiter) Wait(ctx context.Context) error {
+	return rl.wait(ctx, "")
+}
+
+// wait is Wait for a request to path, which additionally waits for the
+// endpoint buckets matching it.
+func (rl *RateLimiter) wait(ctx context.Context, path string) error {
+	class := priorityClass(ctx)
+	start := time.Now()
+	rl.trackQueued(class, 1)
+	defer rl.trackQueued(class, -1)
+
 	// 1. Honour header-based backoff first.
 	rl.mu.Lock()
 	backoff := rl.backoffUntil
@@ -74,11 +124,23 @@ func (rl *RateLimiter) Wait(ctx context.Context) error {
 		}
 	}
 
-	// 2. Then wait for the local token bucket.
-	if err := rl.local.Wait(ctx); err != nil {
+	// 2. Then the budget shared with other processes, if any.
+	if err := rl.waitShared(ctx); err != nil {
+		return err
+	}
+
+	// 3. Then the endpoint buckets, so that a throttled endpoint does not
+	// hold up other requests in the fair queue.
+	if err := rl.waitEndpoints(ctx, path); err != nil {
+		return err
+	}
+
+	// 4. Then wait for the local token bucket, fairly between classes.
+	if err := rl.waitFair(ctx, class); err != nil {
 		return err
 	}
 
+	rl.observeWait(class, time.Since(start))
 	return nil
 }
 
@@ -87,10 +149,18 @@ func (rl *RateLimiter) Wait(ctx context.Context) error {
 //
 // Recognised headers (case-insensitive via http.Header canonical form):
 //
+//	RateLimit-Limit     – number of requests allowed per window.
 //	RateLimit-Remaining – number of requests remaining in the current window.
 //	RateLimit-Reset     – Unix epoch timestamp when the window resets.
 //	Retry-After         – seconds to wait (sent on 429 responses).
+//
+// The observed budget is also fed into the shared limiter, if any.
 func (rl *RateLimiter) UpdateFromHeaders(headers http.Header) {
+	rl.updateFromHeaders(headers)
+	rl.syncShared(headers)
+}
+
+func (rl *RateLimiter) updateFromHeaders(headers http.Header) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
@@ -107,6 +177,10 @@ func (rl *RateLimiter) UpdateFromHeaders(headers http.Header) {
 		}
 	}
 
+	if limit, err := strconv.Atoi(headers.Get("RateLimit-Limit")); err == nil {
+		rl.headerLimit = limit
+	}
+
 	// Parse RateLimit-Remaining.
 	remainStr := headers.Get("RateLimit-Remaining")
 	if remainStr == "" {
@@ -161,6 +235,26 @@ func (rl *RateLimiter) Remaining() int {
 	return rl.headerRemaining
 }
 
+// Limit returns the last observed RateLimit-Limit value, or -1 if no
+// header has been seen yet.
+func (rl *RateLimiter) Limit() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.headerLimit
+}
+
+// hasHeadroom reports whether the remote budget is known to have at least
+// headroomThreshold requests left (or is unknown) and no backoff is active.
+func (rl *RateLimiter) hasHeadroom() bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	now := time.Now()
+	if now.Before(rl.backoffUntil) {
+		return false
+	}
+	return rl.headerRemaining < 0 || rl.headerRemaining >= headroomThreshold || now.After(rl.headerReset)
+}
+
 // ResetAt returns the time at which the remote rate limit window resets.
 func (rl *RateLimiter) ResetAt() time.Time {
 	rl.mu.Lock()
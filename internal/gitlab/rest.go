@@ -49,17 +49,10 @@ func (c *Client) ListProjects(ctx context.Context, opts *goGitlab.ListProjectsOp
 	err := c.fetchAllPages(ctx, func(page int) (*goGitlab.Response, error) {
 		opts.Page = page
 
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return nil, fmt.Errorf("rate limiter: %w", err)
-		}
-
-		projects, resp, err := c.rest.Projects.ListProjects(opts)
+		projects, resp, err := c.REST().Projects.ListProjects(opts, goGitlab.WithContext(ctx))
 		if err != nil {
 			return resp, fmt.Errorf("listing projects (page %d): %w", page, err)
 		}
-		if resp != nil {
-			c.rateLimiter.UpdateFromHeaders(resp.Header)
-		}
 
 		all = append(all, projects...)
 		return resp, nil
@@ -70,14 +63,7 @@ func (c *Client) ListProjects(ctx context.Context, opts *goGitlab.ListProjectsOp
 
 // GetProject fetches a single project by ID.
 func (c *Client) GetProject(ctx context.Context, projectID int) (*goGitlab.Project, error) {
-	if err := c.rateLimiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limiter: %w", err)
-	}
-
-	project, resp, err := c.rest.Projects.GetProject(projectID, nil)
-	if resp != nil {
-		c.rateLimiter.UpdateFromHeaders(resp.Header)
-	}
+	project, _, err := c.REST().Projects.GetProject(projectID, nil, goGitlab.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("getting project %d: %w", projectID, err)
 	}
@@ -110,17 +96,10 @@ func (c *Client) ListPipelines(ctx context.Context, projectID int, opts *goGitla
 	err := c.fetchAllPages(ctx, func(page int) (*goGitlab.Response, error) {
 		opts.Page = page
 
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return nil, fmt.Errorf("rate limiter: %w", err)
-		}
-
-		pipelines, resp, err := c.rest.Pipelines.ListProjectPipelines(projectID, opts)
+		pipelines, resp, err := c.REST().Pipelines.ListProjectPipelines(projectID, opts, goGitlab.WithContext(ctx))
 		if err != nil {
 			return resp, fmt.Errorf("listing pipelines for project %d (page %d): %w", projectID, page, err)
 		}
-		if resp != nil {
-			c.rateLimiter.UpdateFromHeaders(resp.Header)
-		}
 
 		all = append(all, pipelines...)
 		return resp, nil
@@ -131,14 +110,7 @@ func (c *Client) ListPipelines(ctx context.Context, projectID int, opts *goGitla
 
 // GetPipeline fetches the full details of a single pipeline.
 func (c *Client) GetPipeline(ctx context.Context, projectID, pipelineID int) (*goGitlab.Pipeline, error) {
-	if err := c.rateLimiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limiter: %w", err)
-	}
-
-	pipeline, resp, err := c.rest.Pipelines.GetPipeline(projectID, pipelineID)
-	if resp != nil {
-		c.rateLimiter.UpdateFromHeaders(resp.Header)
-	}
+	pipeline, _, err := c.REST().Pipelines.GetPipeline(projectID, pipelineID, goGitlab.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("getting pipeline %d/%d: %w", projectID, pipelineID, err)
 	}
@@ -162,17 +134,10 @@ func (c *Client) ListPipelineJobs(ctx context.Context, projectID, pipelineID int
 	err := c.fetchAllPages(ctx, func(page int) (*goGitlab.Response, error) {
 		opts.Page = page
 
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return nil, fmt.Errorf("rate limiter: %w", err)
-		}
-
-		jobs, resp, err := c.rest.Jobs.ListPipelineJobs(projectID, pipelineID, opts)
+		jobs, resp, err := c.REST().Jobs.ListPipelineJobs(projectID, pipelineID, opts, goGitlab.WithContext(ctx))
 		if err != nil {
 			return resp, fmt.Errorf("listing jobs for pipeline %d/%d (page %d): %w", projectID, pipelineID, page, err)
 		}
-		if resp != nil {
-			c.rateLimiter.UpdateFromHeaders(resp.Header)
-		}
 
 		all = append(all, jobs...)
 		return resp, nil
@@ -193,17 +158,10 @@ func (c *Client) ListPipelineBridges(ctx context.Context, projectID, pipelineID
 	err := c.fetchAllPages(ctx, func(page int) (*goGitlab.Response, error) {
 		opts.Page = page
 
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return nil, fmt.Errorf("rate limiter: %w", err)
-		}
-
-		bridges, resp, err := c.rest.Jobs.ListPipelineBridges(projectID, pipelineID, opts)
+		bridges, resp, err := c.REST().Jobs.ListPipelineBridges(projectID, pipelineID, opts, goGitlab.WithContext(ctx))
 		if err != nil {
 			return resp, fmt.Errorf("listing bridges for pipeline %d/%d (page %d): %w", projectID, pipelineID, page, err)
 		}
-		if resp != nil {
-			c.rateLimiter.UpdateFromHeaders(resp.Header)
-		}
 
 		all = append(all, bridges...)
 		return resp, nil
@@ -218,14 +176,7 @@ func (c *Client) ListPipelineBridges(ctx context.Context, projectID, pipelineID
 
 // GetPipelineTestReport fetches the test report summary for a pipeline.
 func (c *Client) GetPipelineTestReport(ctx context.Context, projectID, pipelineID int) (*goGitlab.PipelineTestReport, error) {
-	if err := c.rateLimiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limiter: %w", err)
-	}
-
-	report, resp, err := c.rest.Pipelines.GetPipelineTestReport(projectID, pipelineID)
-	if resp != nil {
-		c.rateLimiter.UpdateFromHeaders(resp.Header)
-	}
+	report, _, err := c.REST().Pipelines.GetPipelineTestReport(projectID, pipelineID, goGitlab.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("getting test report for pipeline %d/%d: %w", projectID, pipelineID, err)
 	}
@@ -252,17 +203,10 @@ func (c *Client) ListMergeRequests(ctx context.Context, projectID int, opts *goG
 	err := c.fetchAllPages(ctx, func(page int) (*goGitlab.Response, error) {
 		opts.Page = page
 
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return nil, fmt.Errorf("rate limiter: %w", err)
-		}
-
-		mrs, resp, err := c.rest.MergeRequests.ListProjectMergeRequests(projectID, opts)
+		mrs, resp, err := c.REST().MergeRequests.ListProjectMergeRequests(projectID, opts, goGitlab.WithContext(ctx))
 		if err != nil {
 			return resp, fmt.Errorf("listing merge requests for project %d (page %d): %w", projectID, page, err)
 		}
-		if resp != nil {
-			c.rateLimiter.UpdateFromHeaders(resp.Header)
-		}
 
 		all = append(all, mrs...)
 		return resp, nil
@@ -273,14 +217,7 @@ func (c *Client) ListMergeRequests(ctx context.Context, projectID int, opts *goG
 
 // GetMergeRequest fetches a single merge request by IID.
 func (c *Client) GetMergeRequest(ctx context.Context, projectID, mrIID int) (*goGitlab.MergeRequest, error) {
-	if err := c.rateLimiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limiter: %w", err)
-	}
-
-	mr, resp, err := c.rest.MergeRequests.GetMergeRequest(projectID, mrIID, nil)
-	if resp != nil {
-		c.rateLimiter.UpdateFromHeaders(resp.Header)
-	}
+	mr, _, err := c.REST().MergeRequests.GetMergeRequest(projectID, mrIID, nil, goGitlab.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("getting MR %d in project %d: %w", mrIID, projectID, err)
 	}
@@ -303,17 +240,10 @@ func (c *Client) ListEnvironments(ctx context.Context, projectID int) ([]*goGitl
 	err := c.fetchAllPages(ctx, func(page int) (*goGitlab.Response, error) {
 		opts.Page = page
 
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return nil, fmt.Errorf("rate limiter: %w", err)
-		}
-
-		envs, resp, err := c.rest.Environments.ListEnvironments(projectID, opts)
+		envs, resp, err := c.REST().Environments.ListEnvironments(projectID, opts, goGitlab.WithContext(ctx))
 		if err != nil {
 			return resp, fmt.Errorf("listing environments for project %d (page %d): %w", projectID, page, err)
 		}
-		if resp != nil {
-			c.rateLimiter.UpdateFromHeaders(resp.Header)
-		}
 
 		all = append(all, envs...)
 		return resp, nil
@@ -337,17 +267,10 @@ func (c *Client) ListDeployments(ctx context.Context, projectID int, environment
 	err := c.fetchAllPages(ctx, func(page int) (*goGitlab.Response, error) {
 		opts.Page = page
 
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return nil, fmt.Errorf("rate limiter: %w", err)
-		}
-
-		deps, resp, err := c.rest.Deployments.ListProjectDeployments(projectID, opts)
+		deps, resp, err := c.REST().Deployments.ListProjectDeployments(projectID, opts, goGitlab.WithContext(ctx))
 		if err != nil {
 			return resp, fmt.Errorf("listing deployments for project %d (page %d): %w", projectID, page, err)
 		}
-		if resp != nil {
-			c.rateLimiter.UpdateFromHeaders(resp.Header)
-		}
 
 		all = append(all, deps...)
 		return resp, nil
@@ -362,14 +285,7 @@ func (c *Client) ListDeployments(ctx context.Context, projectID int, environment
 
 // GetRepositoryLanguages returns the language breakdown for a project.
 func (c *Client) GetRepositoryLanguages(ctx context.Context, projectID int) (map[string]float32, error) {
-	if err := c.rateLimiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limiter: %w", err)
-	}
-
-	languages, resp, err := c.rest.Projects.GetProjectLanguages(projectID)
-	if resp != nil {
-		c.rateLimiter.UpdateFromHeaders(resp.Header)
-	}
+	languages, _, err := c.REST().Projects.GetProjectLanguages(projectID, goGitlab.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("getting languages for project %d: %w", projectID, err)
 	}
@@ -396,17 +312,10 @@ func (c *Client) ListCommits(ctx context.Context, projectID int, ref string) ([]
 	err := c.fetchAllPages(ctx, func(page int) (*goGitlab.Response, error) {
 		opts.Page = page
 
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return nil, fmt.Errorf("rate limiter: %w", err)
-		}
-
-		commits, resp, err := c.rest.Commits.ListCommits(projectID, opts)
+		commits, resp, err := c.REST().Commits.ListCommits(projectID, opts, goGitlab.WithContext(ctx))
 		if err != nil {
 			return resp, fmt.Errorf("listing commits for project %d (page %d): %w", projectID, page, err)
 		}
-		if resp != nil {
-			c.rateLimiter.UpdateFromHeaders(resp.Header)
-		}
 
 		all = append(all, commits...)
 		return resp, nil
@@ -431,10 +340,6 @@ type DORAMetric struct {
 // metric should be one of: deployment_frequency, lead_time_for_changes,
 // time_to_restore_service, change_failure_rate.
 func (c *Client) GetDORAMetrics(ctx context.Context, projectID int, metric string, startDate, endDate time.Time) ([]DORAMetric, error) {
-	if err := c.rateLimiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limiter: %w", err)
-	}
-
 	path := fmt.Sprintf("projects/%d/dora/metrics?metric=%s&start_date=%s&end_date=%s",
 		projectID, metric,
 		startDate.Format("2006-01-02"),
@@ -442,16 +347,13 @@ func (c *Client) GetDORAMetrics(ctx context.Context, projectID int, metric strin
 	)
 
 	var metrics []DORAMetric
-	req, err := c.rest.NewRequest("GET", path, nil, nil)
+	req, err := c.REST().NewRequest("GET", path, nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("building DORA metrics request: %w", err)
 	}
 	req = req.WithContext(ctx)
 
-	resp, err := c.rest.Do(req, &metrics)
-	if resp != nil {
-		c.rateLimiter.UpdateFromHeaders(resp.Header)
-	}
+	_, err = c.REST().Do(req, &metrics)
 	if err != nil {
 		return nil, fmt.Errorf("fetching DORA metric %q for project %d: %w", metric, projectID, err)
 	}
@@ -465,18 +367,11 @@ func (c *Client) GetDORAMetrics(ctx context.Context, projectID int, metric strin
 
 // GetProjectStatistics fetches a project with statistics included (sizes, etc.).
 func (c *Client) GetProjectStatistics(ctx context.Context, projectID int) (*goGitlab.Project, error) {
-	if err := c.rateLimiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limiter: %w", err)
-	}
-
 	opts := &goGitlab.GetProjectOptions{
 		Statistics: goGitlab.Ptr(true),
 	}
 
-	project, resp, err := c.rest.Projects.GetProject(projectID, opts)
-	if resp != nil {
-		c.rateLimiter.UpdateFromHeaders(resp.Header)
-	}
+	project, _, err := c.REST().Projects.GetProject(projectID, opts, goGitlab.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("getting statistics for project %d: %w", projectID, err)
 	}
@@ -0,0 +1,126 @@
+package gitlab
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// sharedTimeout bounds every call to the shared limiter.
+	sharedTimeout = time.Second
+	// sharedRetryDelay is how long the local limiter is used alone after
+	// the shared limiter failed.
+	sharedRetryDelay = 10 * time.Second
+)
+
+// SharedLimiter is a rate-limit budget kept outside the process, shared by
+// every exporter replica and tool that uses the same token (see
+// store.RedisRateLimiter). Budgets are limit requests per window.
+type SharedLimiter interface {
+	// Take consumes one request of key's budget and returns 0, or how long
+	// to wait until a request is available.
+	Take(ctx context.Context, key string, limit int, window time.Duration) (time.Duration, error)
+	// Sync lowers key's budget to the remaining requests reported by
+	// GitLab.
+	Sync(ctx context.Context, key string, limit, remaining int, window time.Duration) error
+}
+
+// sharedKey identifies the budget of a token without revealing it.
+func sharedKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:16])
+}
+
+// SetShared makes the limiter draw from the shared budget stored under key
+// once GitLab has reported the token's RateLimit-Limit. A nil shared
+// limiter restores purely local limiting.
+func (rl *RateLimiter) SetShared(shared SharedLimiter, key string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.shared, rl.sharedKey = shared, key
+	rl.sharedRetryAt = time.Time{}
+}
+
+// sharedBudget returns the shared limiter and the limit to apply, or nil
+// when there is none, the limit is not known yet, or the shared limiter
+// recently failed.
+func (rl *RateLimiter) sharedBudget() (SharedLimiter, string, int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.shared == nil || rl.headerLimit <= 0 || time.Now().Before(rl.sharedRetryAt) {
+		return nil, "", 0
+	}
+	return rl.shared, rl.sharedKey, rl.headerLimit
+}
+
+// sharedFailed falls back to the local limiter for sharedRetryDelay.
+func (rl *RateLimiter) sharedFailed(err error) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.sharedRetryAt.IsZero() || time.Now().After(rl.sharedRetryAt) {
+		rl.logger.WithError(err).Warn("rate limiter: shared limiter unavailable, falling back to the local limiter")
+	}
+	rl.sharedRetryAt = time.Now().Add(sharedRetryDelay)
+}
+
+// waitShared blocks until the shared budget has a request left. Errors of
+// the shared limiter are logged and the request proceeds under the local
+// limiter.
+func (rl *RateLimiter) waitShared(ctx context.Context) error {
+	for {
+		shared, key, limit := rl.sharedBudget()
+		if shared == nil {
+			return nil
+		}
+
+		takeCtx, cancel := context.WithTimeout(ctx, sharedTimeout)
+		delay, err := shared.Take(takeCtx, key, limit, RateLimitWindow)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			rl.sharedFailed(err)
+			return nil
+		}
+		if delay <= 0 {
+			return nil
+		}
+
+		rl.logger.WithField("delay", delay.Round(time.Millisecond)).
+			Debug("rate limiter: waiting for the shared budget")
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// syncShared feeds the budget reported in response headers into the
+// shared limiter, so that every process sharing the token converges on
+// the remote limit.
+func (rl *RateLimiter) syncShared(headers http.Header) {
+	limit, err := strconv.Atoi(headers.Get("RateLimit-Limit"))
+	if err != nil || limit <= 0 {
+		return
+	}
+	remaining, err := strconv.Atoi(headers.Get("RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	shared, key, _ := rl.sharedBudget()
+	if shared == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sharedTimeout)
+	defer cancel()
+	if err := shared.Sync(ctx, key, limit, remaining, RateLimitWindow); err != nil {
+		rl.sharedFailed(err)
+	}
+}
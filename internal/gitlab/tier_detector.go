@@ -14,6 +14,11 @@ import (
 type DetectedFeatures struct {
 	// HasDORA is true when the DORA metrics API is available (Ultimate tier).
 	HasDORA bool
+	// HasVulnerabilities is true when the vulnerability report of a tracked
+	// project could be read (Ultimate tier). It is set by
+	// Client.ProbeVulnerabilities, not by the tier detector, and does not
+	// affect Tier.
+	HasVulnerabilities bool
 	// HasValueStream is true when Value Stream Analytics endpoints respond (Premium+).
 	HasValueStream bool
 	// HasMRAnalytics is true when the MR analytics endpoint is available (Premium+).
@@ -63,7 +68,7 @@ func (td *TierDetector) Detect(ctx context.Context) (*DetectedFeatures, error) {
 	features.GitLabVersion = version
 	td.logger.WithField("version", version).Info("connected to GitLab instance")
 
-	// --- Step 2: Probe Ultimate-tier endpoints (DORA) ---
+	// --- Step 2: Probe Ultimate-tier endpoints ---
 	features.HasDORA = td.probeDORA(ctx)
 	if features.HasDORA {
 		td.logger.Info("DORA metrics available (Ultimate tier detected)")
@@ -77,11 +82,11 @@ func (td *TierDetector) Detect(ctx context.Context) (*DetectedFeatures, error) {
 	// --- Step 4: Derive tier ---
 	features.Tier = td.deriveTier(features)
 	td.logger.WithFields(logrus.Fields{
-		"tier":           tierName(features.Tier),
-		"dora":           features.HasDORA,
-		"value_stream":   features.HasValueStream,
-		"mr_analytics":   features.HasMRAnalytics,
-		"code_review":    features.HasCodeReview,
+		"tier":         tierName(features.Tier),
+		"dora":         features.HasDORA,
+		"value_stream": features.HasValueStream,
+		"mr_analytics": features.HasMRAnalytics,
+		"code_review":  features.HasCodeReview,
 	}).Info("tier detection completed")
 
 	return features, nil
@@ -201,4 +206,62 @@ func tierName(tier int) string {
 	}
 }
 
+// vulnerabilityProbeProjects caps the tracked projects ProbeVulnerabilities
+// tries before giving up.
+const vulnerabilityProbeProjects = 5
 
+// ProbeVulnerabilities reports whether the vulnerability report of one of
+// the given projects can be read, and records the result in the detected
+// features. A non-existent project answers 404 on every tier, so a real
+// project is needed: 403 and 404 mean the report is unavailable (licence or
+// role), and the next project, up to a few, is tried. Projects the
+// permission audit denied to the vulnerabilities collector are skipped.
+// The result is published on a copy of the features, which collectors may
+// be reading concurrently.
+func (c *Client) ProbeVulnerabilities(ctx context.Context, projects []string) bool {
+	features := c.Features()
+	if features == nil {
+		return false
+	}
+
+	tried := 0
+	for _, project := range projects {
+		if tried == vulnerabilityProbeProjects || ctx.Err() != nil {
+			break
+		}
+		if !c.ProjectAllowed("vulnerabilities", project) {
+			continue
+		}
+		tried++
+
+		path := fmt.Sprintf("projects/%s/vulnerabilities", gitlab.PathEscape(project))
+		req, err := c.rest.NewRequest(http.MethodGet, path, &gitlab.ListOptions{PerPage: 1}, []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+		if err != nil {
+			c.logger.WithError(err).Debug("vulnerabilities probe: failed to build request")
+			return false
+		}
+		resp, err := c.rest.Do(req, nil)
+		if resp == nil {
+			c.logger.WithError(err).Debug("vulnerabilities probe: network error")
+			return false
+		}
+		if resp.StatusCode == http.StatusOK {
+			c.logger.WithField("project", project).Info("vulnerability report available")
+			return c.setHasVulnerabilities(features, true)
+		}
+		c.logger.WithFields(logrus.Fields{
+			"project": project,
+			"status":  resp.StatusCode,
+		}).Debug("vulnerabilities probe: report unavailable")
+	}
+	return c.setHasVulnerabilities(features, false)
+}
+
+// setHasVulnerabilities publishes a copy of features with
+// HasVulnerabilities set to ok, and returns ok.
+func (c *Client) setHasVulnerabilities(features *DetectedFeatures, ok bool) bool {
+	updated := *features
+	updated.HasVulnerabilities = ok
+	c.SetFeatures(&updated)
+	return ok
+}
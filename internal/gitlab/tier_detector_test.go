@@ -0,0 +1,45 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProbeVulnerabilitiesPublishesCopy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/projects/2/vulnerabilities" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+
+	client, err := New(srv.URL, "token", 100, 100, false, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	detected := &DetectedFeatures{Tier: TierUltimate, HasDORA: true}
+	client.SetFeatures(detected)
+
+	if !client.ProbeVulnerabilities(context.Background(), []string{"1", "2"}) {
+		t.Fatal("ProbeVulnerabilities = false, want the second project's report found")
+	}
+	if detected.HasVulnerabilities {
+		t.Error("ProbeVulnerabilities modified the published features")
+	}
+	got := client.Features()
+	if got == detected || !got.HasVulnerabilities || !got.HasDORA || got.Tier != TierUltimate {
+		t.Errorf("Features() = %+v, want a copy with HasVulnerabilities set", got)
+	}
+
+	if client.ProbeVulnerabilities(context.Background(), []string{"1"}) {
+		t.Fatal("ProbeVulnerabilities = true for a forbidden report")
+	}
+	if !got.HasVulnerabilities || client.Features().HasVulnerabilities {
+		t.Error("a failed probe must publish a new copy without HasVulnerabilities")
+	}
+}
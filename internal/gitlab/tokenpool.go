@@ -0,0 +1,460 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultTokenName is the pool name of the main access token passed to New.
+const DefaultTokenName = "default"
+
+// invalidTokenCooldown is how long a token that returned 401 is skipped
+// before it is tried again.
+const invalidTokenCooldown = 10 * time.Minute
+
+// headroomThreshold is the RateLimit-Remaining value below which a token
+// is only used when no other candidate has more budget left.
+const headroomThreshold = 10
+
+// PoolToken describes one access token in a TokenPool. Namespaces are
+// project/group path prefixes the token is dedicated to; a token without
+// namespaces serves requests that match no dedicated token.
+type PoolToken struct {
+	Name       string
+	Token      string
+	Namespaces []string
+}
+
+// pooledToken is a PoolToken together with its own rate-limit budget.
+type pooledToken struct {
+	PoolToken
+	limiter      *RateLimiter
+	invalidUntil time.Time
+}
+
+// TokenPool selects an access token per request. Requests for a project or
+// group are served by the tokens mapped to the longest matching namespace
+// prefix, falling back to the general tokens. Among the candidates, tokens
+// are used round-robin, skipping tokens without rate-limit headroom and
+// tokens that recently returned 401 Unauthorized. It is safe for concurrent
+// use.
+type TokenPool struct {
+	mu     sync.Mutex
+	main   string
+	extra  []PoolToken
+	tokens []*pooledToken
+	next   int
+	shared SharedLimiter
+	logger *logrus.Entry
+}
+
+// NewTokenPool creates a pool containing only the main token.
+func NewTokenPool(token string, logger *logrus.Entry) *TokenPool {
+	p := &TokenPool{logger: logger}
+	p.Set(token, nil)
+	return p
+}
+
+// Set replaces the pool contents with the main token followed by the
+// additional tokens. Budgets of tokens whose value is unchanged are kept.
+func (p *TokenPool) Set(token string, extra []PoolToken) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.set(token, extra)
+}
+
+// SetMain replaces the main token, keeping the additional tokens.
+func (p *TokenPool) SetMain(token string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.set(token, p.extra)
+}
+
+// SetExtra replaces the additional tokens, keeping the main token.
+func (p *TokenPool) SetExtra(extra []PoolToken) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.set(p.main, extra)
+}
+
+// set rebuilds the pool. p.mu must be held.
+func (p *TokenPool) set(token string, extra []PoolToken) {
+	p.main, p.extra = token, extra
+
+	existing := make(map[string]*pooledToken, len(p.tokens))
+	for _, t := range p.tokens {
+		existing[t.Token] = t
+	}
+
+	all := make([]PoolToken, 0, len(extra)+1)
+	if token != "" {
+		all = append(all, PoolToken{Name: DefaultTokenName, Token: token})
+	}
+	all = append(all, extra...)
+
+	tokens := make([]*pooledToken, 0, len(all))
+	for _, t := range all {
+		pt := &pooledToken{PoolToken: t}
+		if prev, ok := existing[t.Token]; ok {
+			pt.limiter = prev.limiter
+		} else {
+			// Local pacing is done by the client's limiter; the per-token
+			// limiter only tracks the remote budget reported in headers.
+			pt.limiter = NewRateLimiter(0, 0, p.logger.WithField("token", t.Name))
+			pt.limiter.SetShared(p.shared, sharedKey(t.Token))
+		}
+		tokens = append(tokens, pt)
+	}
+	p.tokens = tokens
+	p.next = 0
+}
+
+// SetShared makes every token draw from its budget in shared, keyed by a
+// hash of the token, so that processes sharing a token share its budget.
+// A nil shared limiter restores purely local budgets.
+func (p *TokenPool) SetShared(shared SharedLimiter) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.shared = shared
+	for _, t := range p.tokens {
+		t.limiter.SetShared(shared, sharedKey(t.Token))
+	}
+}
+
+// Names returns the names of the tokens in the pool.
+func (p *TokenPool) Names() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	names := make([]string, len(p.tokens))
+	for i, t := range p.tokens {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// Remaining returns the last observed RateLimit-Remaining per token name
+// (-1 when unknown).
+func (p *TokenPool) Remaining() map[string]int {
+	p.mu.Lock()
+	tokens := append([]*pooledToken(nil), p.tokens...)
+	p.mu.Unlock()
+
+	out := make(map[string]int, len(tokens))
+	for _, t := range tokens {
+		out[t.Name] = t.limiter.Remaining()
+	}
+	return out
+}
+
+// Budget sums the last observed rate-limit budgets of the tokens that are
+// not cooling down after a 401. Limit and Remaining are -1 when no token
+// has reported them yet.
+func (p *TokenPool) Budget() Budget {
+	p.mu.Lock()
+	now := time.Now()
+	var tokens []*pooledToken
+	for _, t := range p.tokens {
+		if now.After(t.invalidUntil) {
+			tokens = append(tokens, t)
+		}
+	}
+	p.mu.Unlock()
+
+	b := Budget{Limit: -1, Remaining: -1}
+	for _, t := range tokens {
+		limit, remaining := t.limiter.Limit(), t.limiter.Remaining()
+		if limit < 0 || remaining < 0 {
+			continue
+		}
+		if now.After(t.limiter.ResetAt()) {
+			// The window the last headers described is over.
+			remaining = limit
+		}
+		if b.Limit < 0 {
+			b = Budget{}
+		}
+		b.Limit += limit
+		b.Remaining += remaining
+	}
+	return b
+}
+
+// byName returns the token with the given name, or nil.
+func (p *TokenPool) byName(name string) *pooledToken {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, t := range p.tokens {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// candidates returns the names of the tokens that may serve requests for
+// namespace, ignoring rate-limit budgets and 401 cooldowns.
+func (p *TokenPool) candidates(namespace string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var names []string
+	best := -1
+	for _, t := range p.tokens {
+		for _, ns := range t.Namespaces {
+			if !namespaceMatches(namespace, ns) {
+				continue
+			}
+			switch {
+			case len(ns) > best:
+				best = len(ns)
+				names = []string{t.Name}
+			case len(ns) == best:
+				names = append(names, t.Name)
+			}
+			break
+		}
+	}
+	if len(names) == 0 {
+		for _, t := range p.tokens {
+			if len(t.Namespaces) == 0 {
+				names = append(names, t.Name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		for _, t := range p.tokens {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+// selectToken picks the token for a request against namespace (empty for
+// requests not scoped to a project or group). Tokens in skip have already
+// failed for this request. It returns nil when no usable token remains.
+func (p *TokenPool) selectToken(namespace string, skip map[*pooledToken]bool) *pooledToken {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	usable := func(t *pooledToken) bool {
+		return !skip[t] && now.After(t.invalidUntil)
+	}
+
+	// Dedicated tokens for the longest matching namespace prefix.
+	var candidates []*pooledToken
+	best := -1
+	for _, t := range p.tokens {
+		if !usable(t) {
+			continue
+		}
+		for _, ns := range t.Namespaces {
+			if !namespaceMatches(namespace, ns) {
+				continue
+			}
+			switch {
+			case len(ns) > best:
+				best = len(ns)
+				candidates = []*pooledToken{t}
+			case len(ns) == best:
+				candidates = append(candidates, t)
+			}
+			break
+		}
+	}
+
+	// General tokens, then any other usable token, then tokens that are
+	// still cooling down after a 401 (a rotated token may be valid again).
+	if len(candidates) == 0 {
+		for _, t := range p.tokens {
+			if usable(t) && len(t.Namespaces) == 0 {
+				candidates = append(candidates, t)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		for _, t := range p.tokens {
+			if usable(t) {
+				candidates = append(candidates, t)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		for _, t := range p.tokens {
+			if !skip[t] {
+				candidates = append(candidates, t)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// Round-robin, preferring tokens with rate-limit headroom.
+	start := p.next
+	p.next++
+	for i := range candidates {
+		t := candidates[(start+i)%len(candidates)]
+		if t.limiter.hasHeadroom() {
+			return t
+		}
+	}
+	return candidates[start%len(candidates)]
+}
+
+// markInvalid takes a token out of rotation after a 401 response.
+func (p *TokenPool) markInvalid(t *pooledToken) {
+	p.mu.Lock()
+	t.invalidUntil = time.Now().Add(invalidTokenCooldown)
+	p.mu.Unlock()
+	p.logger.WithField("token", t.Name).Warn("access token rejected with 401, failing over to the next token")
+}
+
+// namespaceMatches reports whether path equals prefix or lies below it.
+func namespaceMatches(path, prefix string) bool {
+	prefix = strings.Trim(prefix, "/")
+	if path == "" || prefix == "" {
+		return false
+	}
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// namespaceFromURL extracts the project or group path from a REST API URL
+// such as /api/v4/projects/group%2Fproject/pipelines. Numeric IDs and
+// other endpoints yield an empty namespace.
+func namespaceFromURL(u *url.URL) string {
+	segments := strings.Split(u.EscapedPath(), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] != "projects" && segments[i] != "groups" {
+			continue
+		}
+		id, err := url.PathUnescape(segments[i+1])
+		if err != nil || isNumeric(id) {
+			return ""
+		}
+		return id
+	}
+	return ""
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// pinnedTokenKey forces a request onto a specific pool token.
+type pinnedTokenKey struct{}
+
+// withPinnedToken returns a context that makes the token transport use the
+// named token instead of selecting one.
+func withPinnedToken(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, pinnedTokenKey{}, name)
+}
+
+// tokenTransport paces every request through the client's rate limiter,
+// authenticates it with a token from the pool, feeds the response's
+// rate-limit headers into that token's budget, and retries with another
+// token when one is rejected with 401. Response headers are never fed into
+// the client's limiter, so one exhausted token does not throttle the pool.
+type tokenTransport struct {
+	pool *TokenPool
+	base http.RoundTripper
+	// limiter, if set, paces requests before a token is selected.
+	limiter *RateLimiter
+	// lastContact, if set, records when GitLab last answered with a
+	// non-5xx status.
+	lastContact *atomic.Int64
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.limiter != nil {
+		if err := t.limiter.wait(req.Context(), req.URL.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	if name, ok := req.Context().Value(pinnedTokenKey{}).(string); ok {
+		tok := t.pool.byName(name)
+		if tok == nil {
+			return nil, fmt.Errorf("token %q is not in the pool", name)
+		}
+		return t.send(req, tok, nil)
+	}
+
+	if n, ok := req.Context().Value(requestCounterKey{}).(*atomic.Int64); ok {
+		n.Add(1)
+	}
+
+	namespace := namespaceFromURL(req.URL)
+	skip := make(map[*pooledToken]bool)
+	tok := t.pool.selectToken(namespace, skip)
+	if tok == nil {
+		return nil, fmt.Errorf("token pool is empty")
+	}
+	// body is the replayed request body of a retry; the first attempt
+	// sends the caller's.
+	var body io.ReadCloser
+	for {
+		if err := tok.limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+
+		resp, err := t.send(req, tok, body)
+		if err != nil || resp.StatusCode != http.StatusUnauthorized {
+			return resp, err
+		}
+
+		t.pool.markInvalid(tok)
+		skip[tok] = true
+
+		// Fail over only when another token is left and the request body
+		// can be replayed; otherwise the caller sees the 401.
+		next := t.pool.selectToken(namespace, skip)
+		if next == nil || (req.Body != nil && req.GetBody == nil) {
+			return resp, nil
+		}
+		_ = resp.Body.Close()
+		if req.GetBody != nil {
+			if body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		tok = next
+	}
+}
+
+// send issues a copy of req authenticated with tok, with body in place of
+// req's when set, and records the budget headers. req itself is never
+// modified, as http.RoundTripper requires.
+func (t *tokenTransport) send(req *http.Request, tok *pooledToken, body io.ReadCloser) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	if body != nil {
+		out.Body = body
+	}
+	out.Header.Del("Authorization")
+	out.Header.Set("PRIVATE-TOKEN", tok.Token)
+	resp, err := t.base.RoundTrip(out)
+	if resp != nil {
+		tok.limiter.UpdateFromHeaders(resp.Header)
+		if t.lastContact != nil && resp.StatusCode < http.StatusInternalServerError {
+			t.lastContact.Store(time.Now().UnixNano())
+		}
+	}
+	return resp, err
+}
@@ -0,0 +1,153 @@
+package gitlab
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func testLogger() *logrus.Entry {
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	return logrus.NewEntry(l)
+}
+
+func TestTokenTransportFailsOverOn401(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		tokens []string
+		bodies []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		tokens = append(tokens, r.Header.Get("PRIVATE-TOKEN"))
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		if r.Header.Get("PRIVATE-TOKEN") != "valid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	pool := NewTokenPool("revoked", testLogger())
+	pool.SetExtra([]PoolToken{{Name: "spare", Token: "valid"}})
+	client := &http.Client{Transport: &tokenTransport{pool: pool, base: http.DefaultTransport}}
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/v4/projects/1/issues", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	callerBody := req.Body
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status = %d, want the spare token's 201", resp.StatusCode)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"revoked", "valid"}; !slices.Equal(tokens, want) {
+		t.Errorf("tokens sent = %v, want %v", tokens, want)
+	}
+	if want := []string{"payload", "payload"}; !slices.Equal(bodies, want) {
+		t.Errorf("bodies sent = %q, want the body replayed: %q", bodies, want)
+	}
+	if req.Body != callerBody || req.Header.Get("PRIVATE-TOKEN") != "" {
+		t.Error("RoundTrip modified the caller's request")
+	}
+
+	// The rejected token cools down, so the next request goes straight to
+	// the spare one.
+	mu.Unlock()
+	resp, err = client.Get(srv.URL + "/api/v4/version")
+	mu.Lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := tokens[len(tokens)-1]; len(tokens) != 3 || got != "valid" {
+		t.Errorf("tokens sent = %v, want the cooled-down token skipped", tokens)
+	}
+}
+
+func TestTokenTransportReturns401WithoutSpare(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	pool := NewTokenPool("revoked", testLogger())
+	client := &http.Client{Transport: &tokenTransport{pool: pool, base: http.DefaultTransport}}
+	resp, err := client.Get(srv.URL + "/api/v4/version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestTokenPoolNamespaceMapping(t *testing.T) {
+	pool := NewTokenPool("main", testLogger())
+	pool.SetExtra([]PoolToken{
+		{Name: "platform", Token: "t1", Namespaces: []string{"platform"}},
+		{Name: "infra", Token: "t2", Namespaces: []string{"/platform/infra/"}},
+		{Name: "mobile", Token: "t3", Namespaces: []string{"apps/mobile", "apps/tablet"}},
+	})
+
+	tests := []struct {
+		namespace string
+		want      string
+	}{
+		{"platform/api", "platform"},
+		{"platform", "platform"},
+		{"platform/infra/terraform", "infra"},
+		{"platformer/x", DefaultTokenName},
+		{"apps/tablet/ios", "mobile"},
+		{"apps/web", DefaultTokenName},
+		{"", DefaultTokenName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			if got := pool.selectToken(tt.namespace, nil); got == nil || got.Name != tt.want {
+				t.Errorf("selectToken(%q) = %v, want %s", tt.namespace, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamespaceFromURL(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/v4/projects/group%2Fproject/pipelines", "group/project"},
+		{"/api/v4/groups/group%2Fsub/runners", "group/sub"},
+		{"/api/v4/projects/42/pipelines", ""},
+		{"/api/v4/version", ""},
+		{"/api/v4/projects", ""},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse("https://gitlab.example.com" + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := namespaceFromURL(u); got != tt.want {
+			t.Errorf("namespaceFromURL(%s) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -11,47 +11,119 @@ import (
 
 // Scheduler manages a set of periodic tasks, running each in its own goroutine.
 type Scheduler struct {
-	tasks  []*Task
-	logger *logrus.Entry
-	wg     sync.WaitGroup
+	mu      sync.Mutex
+	tasks   []*Task
+	running map[*Task]*runningTask
+	ctx     context.Context
+	logger  *logrus.Entry
+	wg      sync.WaitGroup
+	cancel  context.CancelFunc
+}
+
+// runningTask tracks the goroutine executing a single task so it can be
+// stopped independently of the rest of the scheduler.
+type runningTask struct {
 	cancel context.CancelFunc
+	done   chan struct{}
 }
 
 // NewScheduler creates a new scheduler.
 func NewScheduler(logger *logrus.Entry) *Scheduler {
 	return &Scheduler{
-		logger: logger.WithField("component", "scheduler"),
+		running: make(map[*Task]*runningTask),
+		logger:  logger.WithField("component", "scheduler"),
 	}
 }
 
-// AddTask registers a task to be started when Start is called.
-// It must be called before Start.
+// AddTask registers a task. Tasks added before Start are launched by Start;
+// tasks added afterwards are launched immediately.
 func (s *Scheduler) AddTask(task *Task) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.tasks = append(s.tasks, task)
+	if s.ctx != nil {
+		s.startTask(task)
+	}
+}
+
+// RemoveTask stops and unregisters the task with the given name, blocking
+// until its goroutine has returned. It reports whether a task was removed.
+func (s *Scheduler) RemoveTask(name string) bool {
+	s.mu.Lock()
+	var (
+		removed *Task
+		rt      *runningTask
+	)
+	for i, t := range s.tasks {
+		if t.Name == name {
+			removed = t
+			s.tasks = append(s.tasks[:i], s.tasks[i+1:]...)
+			break
+		}
+	}
+	if removed != nil {
+		rt = s.running[removed]
+		delete(s.running, removed)
+	}
+	s.mu.Unlock()
+
+	if removed == nil {
+		return false
+	}
+	if rt != nil {
+		rt.cancel()
+		<-rt.done
+	}
+	s.logger.WithField("task", name).Info("task removed")
+	return true
+}
+
+// Tasks returns a snapshot of the registered tasks.
+func (s *Scheduler) Tasks() []*Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*Task, len(s.tasks))
+	copy(out, s.tasks)
+	return out
 }
 
 // Start launches a goroutine for every registered task. Each goroutine runs
 // the task's loop (see Task.Run) until ctx is cancelled or Stop is called.
 func (s *Scheduler) Start(ctx context.Context) {
-	ctx, s.cancel = context.WithCancel(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ctx, s.cancel = context.WithCancel(ctx)
 
 	s.logger.WithField("task_count", len(s.tasks)).Info("starting scheduler")
 
 	for _, t := range s.tasks {
-		s.wg.Add(1)
-		go func(task *Task) {
-			defer s.wg.Done()
-			task.Run(ctx)
-		}(t)
+		s.startTask(t)
 	}
 }
 
+// startTask launches the goroutine for a single task. s.mu must be held.
+func (s *Scheduler) startTask(task *Task) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	rt := &runningTask{cancel: cancel, done: make(chan struct{})}
+	s.running[task] = rt
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer close(rt.done)
+		task.Run(ctx)
+	}()
+}
+
 // Stop cancels all running tasks and blocks until every goroutine has returned.
 func (s *Scheduler) Stop() {
 	s.logger.Info("stopping scheduler")
+	s.mu.Lock()
 	if s.cancel != nil {
 		s.cancel()
 	}
+	s.mu.Unlock()
 	s.wg.Wait()
 	s.logger.Info("scheduler stopped")
 }
@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+// staggerWindowKey carries the window a run may spread its work over.
+type staggerWindowKey struct{}
+
+// withStaggerWindow returns a context that lets NewStagger spread work over
+// window.
+func withStaggerWindow(ctx context.Context, window time.Duration) context.Context {
+	return context.WithValue(ctx, staggerWindowKey{}, window)
+}
+
+// Stagger spreads the items of one run (typically the projects a collector
+// walks) evenly over the run's stagger window, so that a cycle's requests
+// are not all sent at its start. Without a window every slot is immediate.
+type Stagger struct {
+	start time.Time
+	step  time.Duration
+}
+
+// NewStagger returns a Stagger for n items of the run ctx belongs to.
+func NewStagger(ctx context.Context, n int) *Stagger {
+	s := &Stagger{start: time.Now()}
+	if window, ok := ctx.Value(staggerWindowKey{}).(time.Duration); ok && n > 1 {
+		s.step = window / time.Duration(n)
+	}
+	return s
+}
+
+// Wait blocks until the slot of item i has come, returning early with the
+// context's error if ctx is done. Items that are already late do not wait.
+func (s *Stagger) Wait(ctx context.Context, i int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	delay := time.Until(s.start.Add(time.Duration(i) * s.step))
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
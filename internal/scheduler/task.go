@@ -2,21 +2,90 @@ package scheduler
 
 import (
 	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+// staggerFraction is the share of the time until the next run that a
+// staggered run spreads its work over, leaving headroom before the next
+// run starts.
+const staggerFraction = 0.8
+
+// OverlapPolicy decides what happens when a run is due while the previous
+// one is still executing.
+type OverlapPolicy string
+
+const (
+	// OverlapSkip drops the due run.
+	OverlapSkip OverlapPolicy = "skip"
+	// OverlapQueue starts the due run as soon as the previous one finishes;
+	// at most one run is queued.
+	OverlapQueue OverlapPolicy = "queue"
+	// OverlapCancel cancels the previous run and starts the due one.
+	OverlapCancel OverlapPolicy = "cancel_previous"
+)
+
+// Causes of a run's context cancellation.
+var (
+	errCancelledByOverlap = errors.New("cancelled by a newer run")
+	errRunTimeout         = errors.New("task run timed out")
+)
+
 // Task represents a periodically executed unit of work (typically a collector's Run method).
 type Task struct {
 	// Name is a human-readable identifier used in log messages.
 	Name string
-	// Interval is the period between successive runs.
+	// Interval is the period between successive runs. Use SetInterval to
+	// change it while the task is running.
 	Interval time.Duration
+	// Schedule, if set, replaces Interval: runs start at the times it
+	// matches, after a first run at startup.
+	Schedule *Cron
+	// InitialDelay postpones the first run.
+	InitialDelay time.Duration
+	// Jitter adds a random delay in [0, Jitter) to every run so that tasks
+	// with the same interval or schedule do not fire together.
+	Jitter time.Duration
+	// Stagger spreads the work of every run after the first over most of
+	// the time until the next run (see NewStagger).
+	Stagger bool
+	// Timeout, if set, is the deadline of every run. A run that reaches it
+	// has its context cancelled; RunFunc is expected to keep what it has
+	// collected so far.
+	Timeout time.Duration
+	// Overlap is the policy for runs that become due while the previous
+	// run is still executing (default OverlapSkip).
+	Overlap OverlapPolicy
 	// RunFunc is the function executed each tick. Errors are logged but do not
 	// stop the loop.
 	RunFunc func(ctx context.Context) error
 	logger  *logrus.Entry
+
+	mu    sync.Mutex
+	state TaskState
+}
+
+// TaskState is a snapshot of a task's execution state.
+type TaskState struct {
+	Name string `json:"name"`
+	// Running is true while a run is executing; Queued is true when
+	// another run will start as soon as it finishes.
+	Running      bool      `json:"running"`
+	Queued       bool      `json:"queued"`
+	LastStart    time.Time `json:"last_start,omitempty"`
+	LastDuration float64   `json:"last_duration_seconds"`
+	LastError    string    `json:"last_error,omitempty"`
+	// Runs counts started runs; Skipped counts due runs dropped because
+	// of an overlap; Cancelled counts runs cancelled by a newer run; and
+	// TimedOut counts runs that hit Timeout.
+	Runs      uint64 `json:"runs"`
+	Skipped   uint64 `json:"skipped"`
+	Cancelled uint64 `json:"cancelled"`
+	TimedOut  uint64 `json:"timed_out"`
 }
 
 // NewTask creates a new periodic task.
@@ -29,36 +98,220 @@ func NewTask(name string, interval time.Duration, runFunc func(ctx context.Conte
 	}
 }
 
-// Run executes the task in a loop. It fires immediately on entry, then waits
-// for Interval between subsequent invocations. The loop exits when ctx is done.
+// State returns a snapshot of the task's execution state.
+func (t *Task) State() TaskState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.state
+	s.Name = t.Name
+	return s
+}
+
+// SetInterval changes the period between runs. It takes effect when the
+// next run is scheduled.
+func (t *Task) SetInterval(d time.Duration) {
+	t.mu.Lock()
+	t.Interval = d
+	t.mu.Unlock()
+}
+
+// interval returns the current period between runs.
+func (t *Task) interval() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.Interval
+}
+
+// Run executes the task in a loop. The first run starts after InitialDelay
+// (plus jitter); later runs follow Schedule if set, otherwise Interval.
+// Runs that become due while the previous one is still executing are
+// handled according to Overlap. The loop exits when ctx is done, after the
+// current run has returned.
 func (t *Task) Run(ctx context.Context) {
-	t.logger.WithField("interval", t.Interval).Info("task started")
+	fields := logrus.Fields{"interval": t.interval()}
+	if t.Schedule != nil {
+		fields = logrus.Fields{"schedule": t.Schedule.String()}
+	}
+	t.logger.WithFields(fields).Info("task started")
 
-	// Run immediately on start.
-	t.execute(ctx)
+	base := time.Now().Add(t.InitialDelay)
+	timer := time.NewTimer(time.Until(base) + t.jitter())
+	defer timer.Stop()
 
-	ticker := time.NewTicker(t.Interval)
-	defer ticker.Stop()
+	var (
+		done      = make(chan struct{}, 1)
+		cancelRun context.CancelCauseFunc
+		running   bool
+		queued    bool
+		first     = true
+		stopped   bool
+	)
+
+	// start launches a run. deadline is the start of the run after it (zero
+	// if there is none), which bounds the stagger window.
+	start := func(deadline time.Time) {
+		runCtx, cancel := context.WithCancelCause(ctx)
+		cancelRun = cancel
+		if t.Stagger && !first && !deadline.IsZero() {
+			if window := time.Until(deadline); window > 0 {
+				runCtx = withStaggerWindow(runCtx, time.Duration(float64(window)*staggerFraction))
+			}
+		}
+		first = false
+		running = true
+		t.setRunning(true)
+
+		go func() {
+			defer func() { done <- struct{}{} }()
+			defer cancel(nil)
+			t.execute(runCtx)
+		}()
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
+			if running {
+				<-done
+				t.setRunning(false)
+			}
+			t.setQueued(false)
 			t.logger.Info("task stopping (context cancelled)")
 			return
-		case <-ticker.C:
-			t.execute(ctx)
+
+		case <-done:
+			running = false
+			t.setRunning(false)
+			if queued {
+				queued = false
+				t.setQueued(false)
+				var deadline time.Time
+				if !stopped {
+					deadline = base
+				}
+				start(deadline)
+			} else if stopped {
+				t.logger.Warn("schedule has no further runs, task stopping")
+				return
+			}
+
+		case <-timer.C:
+			// next is both the following run and the deadline of this one.
+			next := t.next(base, time.Now())
+			if next.IsZero() {
+				stopped = true
+			} else {
+				base = next
+				timer.Reset(time.Until(base) + t.jitter())
+			}
+
+			switch {
+			case !running:
+				start(next)
+			case t.Overlap == OverlapQueue && !queued:
+				queued = true
+				t.setQueued(true)
+				t.logger.Debug("previous run still executing, run queued")
+			case t.Overlap == OverlapCancel:
+				cancelRun(errCancelledByOverlap)
+				<-done
+				t.setRunning(false)
+				t.logger.Warn("previous run still executing, cancelled it")
+				start(next)
+			default:
+				t.addSkipped()
+				t.logger.Warn("previous run still executing, run skipped")
+			}
 		}
 	}
 }
 
-// execute performs a single invocation and logs the outcome.
+// next returns the start of the first run after now. Interval runs keep
+// their phase relative to prev, skipping slots that have already passed.
+func (t *Task) next(prev, now time.Time) time.Time {
+	if t.Schedule != nil {
+		return t.Schedule.Next(now)
+	}
+	interval := t.interval()
+	next := prev.Add(interval)
+	if !next.After(now) {
+		missed := now.Sub(next)/interval + 1
+		next = next.Add(missed * interval)
+	}
+	return next
+}
+
+// jitter returns a random delay in [0, Jitter).
+func (t *Task) jitter() time.Duration {
+	if t.Jitter <= 0 {
+		return 0
+	}
+	return rand.N(t.Jitter)
+}
+
+// execute performs a single invocation, bounded by Timeout, and records and
+// logs the outcome.
 func (t *Task) execute(ctx context.Context) {
+	if t.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, t.Timeout, errRunTimeout)
+		defer cancel()
+	}
+
 	start := time.Now()
-	if err := t.RunFunc(ctx); err != nil {
-		t.logger.WithError(err).WithField("duration", time.Since(start).Round(time.Millisecond)).
-			Error("task execution failed")
-	} else {
-		t.logger.WithField("duration", time.Since(start).Round(time.Millisecond)).
-			Debug("task execution completed")
+	t.mu.Lock()
+	t.state.Runs++
+	t.state.LastStart = start
+	t.mu.Unlock()
+
+	err := t.RunFunc(ctx)
+	duration := time.Since(start)
+
+	t.mu.Lock()
+	t.state.LastDuration = duration.Seconds()
+	t.state.LastError = ""
+	if err != nil {
+		t.state.LastError = err.Error()
+	}
+	timedOut := errors.Is(context.Cause(ctx), errRunTimeout)
+	cancelled := errors.Is(context.Cause(ctx), errCancelledByOverlap)
+	if timedOut {
+		t.state.TimedOut++
+	}
+	if cancelled {
+		t.state.Cancelled++
 	}
+	t.mu.Unlock()
+
+	log := t.logger.WithField("duration", duration.Round(time.Millisecond))
+	switch {
+	case timedOut:
+		log.WithField("timeout", t.Timeout).Warn("task run timed out, partial results kept")
+	case cancelled:
+		log.Debug("task run cancelled by a newer run")
+	case ctx.Err() != nil:
+		log.Debug("task run cancelled")
+	case err != nil:
+		log.WithError(err).Error("task execution failed")
+	default:
+		log.Debug("task execution completed")
+	}
+}
+
+func (t *Task) setRunning(running bool) {
+	t.mu.Lock()
+	t.state.Running = running
+	t.mu.Unlock()
+}
+
+func (t *Task) setQueued(queued bool) {
+	t.mu.Lock()
+	t.state.Queued = queued
+	t.mu.Unlock()
+}
+
+func (t *Task) addSkipped() {
+	t.mu.Lock()
+	t.state.Skipped++
+	t.mu.Unlock()
 }
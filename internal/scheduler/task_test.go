@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func testLogger() *logrus.Entry {
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	return logrus.NewEntry(l)
+}
+
+// TestTaskStaggerDoesNotOverlap runs a staggered task whose items take up
+// the whole stagger window and checks that every run finishes before the
+// next one is due.
+func TestTaskStaggerDoesNotOverlap(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		const (
+			interval = 10 * time.Second
+			items    = 5
+			cycles   = 6
+		)
+
+		task := NewTask("staggered", interval, func(ctx context.Context) error {
+			stagger := NewStagger(ctx, items)
+			for i := range items {
+				if err := stagger.Wait(ctx, i); err != nil {
+					return err
+				}
+			}
+			// The last slot is one step before the end of the window.
+			time.Sleep(interval / 10)
+			return nil
+		}, testLogger())
+		task.Stagger = true
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			task.Run(ctx)
+			close(done)
+		}()
+
+		time.Sleep(cycles*interval + interval/2)
+		cancel()
+		<-done
+
+		state := task.State()
+		if state.Skipped != 0 {
+			t.Errorf("Skipped = %d, want 0", state.Skipped)
+		}
+		if state.Runs != cycles+1 {
+			t.Errorf("Runs = %d, want %d", state.Runs, cycles+1)
+		}
+	})
+}
+
+func TestTaskNext(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	task := NewTask("next", time.Minute, nil, testLogger())
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"before next slot", base.Add(10 * time.Second), base.Add(time.Minute)},
+		{"exactly on next slot", base.Add(time.Minute), base.Add(2 * time.Minute)},
+		{"missed slots keep phase", base.Add(3*time.Minute + time.Second), base.Add(4 * time.Minute)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := task.next(base, tt.now); !got.Equal(tt.want) {
+				t.Errorf("next = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,4 +1,4 @@
-// Package server provides the HTTP server exposing /metrics, /health, /ready, and /config endpoints.
+// Package server provides the HTTP server exposing /metrics, /health, /ready, /startup, /config and /diagnostics endpoints.
 package server
 
 import (
@@ -7,12 +7,15 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/pprof"
+	"sort"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/sirupsen/logrus"
 
 	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/collector"
@@ -22,41 +25,56 @@ import (
 // Server is the HTTP server that exposes Prometheus metrics and operational endpoints.
 type Server struct {
 	httpServer *http.Server
-	registry   *collector.Registry
-	config     *config.Config
-	ready      atomic.Bool
-	logger     *logrus.Entry
+	config     atomic.Pointer[config.Config]
+
+	// instances maps a GitLab instance name to a Prometheus registry that
+	// labels every series of that instance's collectors.
+	instMu    sync.RWMutex
+	instances map[string]*prometheus.Registry
+
+	// diagnostics maps a name served under /diagnostics/ to its provider.
+	diagMu      sync.RWMutex
+	diagnostics map[string]func() interface{}
+
+	ready atomic.Bool
+
+	// readinessCheck supplies the conditions /ready evaluates. It is set
+	// before Start.
+	readinessCheck ConditionFunc
+
+	logger *logrus.Entry
 }
 
-// NewServer creates a new HTTP server configured from cfg. The provided registry
-// is used as the Prometheus collector source for the /metrics endpoint.
-func NewServer(cfg *config.Config, registry *collector.Registry, logger *logrus.Entry) *Server {
+// NewServer creates a new HTTP server configured from cfg. The /metrics
+// endpoint serves the collector registries added with AddRegistry together
+// with the process-wide default registry (operational, Go and process
+// metrics).
+func NewServer(cfg *config.Config, logger *logrus.Entry) *Server {
 	s := &Server{
-		registry: registry,
-		config:   cfg,
-		logger:   logger.WithField("component", "server"),
+		instances:   make(map[string]*prometheus.Registry),
+		diagnostics: make(map[string]func() interface{}),
+		logger:      logger.WithField("component", "server"),
 	}
+	s.config.Store(cfg)
 
 	mux := http.NewServeMux()
 
 	// --- Prometheus metrics ---
-	promRegistry := prometheus.NewRegistry()
-	promRegistry.MustRegister(registry)
-	// Also register default Go and process collectors.
-	promRegistry.MustRegister(collectors.NewGoCollector())
-	promRegistry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
-
-	mux.Handle("/metrics", promhttp.HandlerFor(promRegistry, promhttp.HandlerOpts{
+	mux.Handle("/metrics", promhttp.HandlerFor(prometheus.GathererFunc(s.gather), promhttp.HandlerOpts{
 		EnableOpenMetrics: true,
 	}))
 
 	// --- Health / readiness ---
 	mux.HandleFunc("/health", s.handleHealth)
 	mux.HandleFunc("/ready", s.handleReady)
+	mux.HandleFunc("/startup", s.handleStartup)
 
 	// --- Config (redacted) ---
 	mux.HandleFunc("/config", s.handleConfig)
 
+	// --- Diagnostics ---
+	mux.HandleFunc("/diagnostics/", s.handleDiagnostics)
+
 	// --- pprof ---
 	if cfg.Server.EnablePprof {
 		mux.HandleFunc("/debug/pprof/", pprof.Index)
@@ -111,11 +129,79 @@ func (s *Server) Stop(ctx context.Context) error {
 	return s.httpServer.Shutdown(ctx)
 }
 
-// SetReady updates the readiness state exposed by the /ready endpoint.
+// SetReady sets the base readiness state. /ready reports ready only while
+// this is true and every condition from the readiness check holds; it is
+// cleared on shutdown.
 func (s *Server) SetReady(ready bool) {
 	s.ready.Store(ready)
 }
 
+// Condition is a single named readiness or startup check.
+type Condition struct {
+	Name    string `json:"name"`
+	OK      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+}
+
+// ConditionFunc reports the current state of a set of conditions.
+type ConditionFunc func() []Condition
+
+// SetReadinessCheck sets the conditions /ready requires in addition to the
+// base readiness state. It must be called before Start.
+func (s *Server) SetReadinessCheck(fn ConditionFunc) {
+	s.readinessCheck = fn
+}
+
+// SetConfig replaces the configuration served by the /config endpoint after
+// a live reload. Listener settings are not affected.
+func (s *Server) SetConfig(cfg *config.Config) {
+	s.config.Store(cfg)
+}
+
+// AddRegistry exposes the collectors of a GitLab instance on /metrics. Every
+// series they produce is given an "instance" label with the instance name.
+// Adding a registry under an existing name replaces it.
+func (s *Server) AddRegistry(instance string, registry *collector.Registry) {
+	reg := prometheus.NewRegistry()
+	prometheus.WrapRegistererWith(prometheus.Labels{"instance": instance}, reg).MustRegister(registry)
+
+	s.instMu.Lock()
+	s.instances[instance] = reg
+	s.instMu.Unlock()
+}
+
+// RemoveRegistry stops exposing the collectors of a GitLab instance.
+func (s *Server) RemoveRegistry(instance string) {
+	s.instMu.Lock()
+	delete(s.instances, instance)
+	s.instMu.Unlock()
+}
+
+// HandleDiagnostic serves the JSON encoding of fn's result on
+// /diagnostics/<name>.
+func (s *Server) HandleDiagnostic(name string, fn func() interface{}) {
+	s.diagMu.Lock()
+	s.diagnostics[name] = fn
+	s.diagMu.Unlock()
+}
+
+// gather merges the per-instance registries with the default registry.
+func (s *Server) gather() ([]*dto.MetricFamily, error) {
+	s.instMu.RLock()
+	names := make([]string, 0, len(s.instances))
+	for name := range s.instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	gatherers := prometheus.Gatherers{prometheus.DefaultGatherer}
+	for _, name := range names {
+		gatherers = append(gatherers, s.instances[name])
+	}
+	s.instMu.RUnlock()
+
+	return gatherers.Gather()
+}
+
 // --- HTTP handlers ---
 
 func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
@@ -124,28 +210,54 @@ func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
 	_, _ = w.Write([]byte(`{"status":"ok"}`))
 }
 
-func (s *Server) handleReady(w http.ResponseWriter, _ *http.Request) {
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	conds := []Condition{{Name: "serving", OK: s.ready.Load()}}
+	if s.readinessCheck != nil {
+		conds = append(conds, s.readinessCheck()...)
+	}
+	writeConditions(w, r, conds, "ready", "not_ready")
+}
+
+// handleStartup reports started once the process is serving: the
+// configuration is loaded and the listener is up. GitLab availability is
+// left to /ready, so a GitLab outage never gets the process restarted.
+func (s *Server) handleStartup(w http.ResponseWriter, r *http.Request) {
+	conds := []Condition{{Name: "serving", OK: s.ready.Load()}}
+	writeConditions(w, r, conds, "started", "starting")
+}
+
+// writeConditions answers 200 when every condition holds and 503 otherwise.
+// With a "verbose" query parameter the body also lists each condition.
+func writeConditions(w http.ResponseWriter, r *http.Request, conds []Condition, okStatus, failStatus string) {
+	ok := true
+	for _, c := range conds {
+		ok = ok && c.OK
+	}
+
+	body := struct {
+		Status     string      `json:"status"`
+		Conditions []Condition `json:"conditions,omitempty"`
+	}{Status: okStatus}
+	if !ok {
+		body.Status = failStatus
+	}
+	if r.URL.Query().Has("verbose") {
+		body.Conditions = conds
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	if s.ready.Load() {
+	if ok {
 		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"status":"ready"}`))
 	} else {
 		w.WriteHeader(http.StatusServiceUnavailable)
-		_, _ = w.Write([]byte(`{"status":"not_ready"}`))
 	}
+	_ = json.NewEncoder(w).Encode(body)
 }
 
 func (s *Server) handleConfig(w http.ResponseWriter, _ *http.Request) {
 	// Return a redacted copy of the configuration so that secrets are not
 	// exposed through the /config endpoint.
-	redacted := *s.config
-	redacted.GitLab.Token = "***REDACTED***"
-	if redacted.Redis.URL != "" {
-		redacted.Redis.URL = "***REDACTED***"
-	}
-	if redacted.Server.Webhook.SecretToken != "" {
-		redacted.Server.Webhook.SecretToken = "***REDACTED***"
-	}
+	redacted := s.config.Load().Redacted()
 
 	w.Header().Set("Content-Type", "application/json")
 	enc := json.NewEncoder(w)
@@ -155,3 +267,35 @@ func (s *Server) handleConfig(w http.ResponseWriter, _ *http.Request) {
 		http.Error(w, "internal error", http.StatusInternalServerError)
 	}
 }
+
+func (s *Server) handleDiagnostics(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/diagnostics/")
+
+	s.diagMu.RLock()
+	fn, ok := s.diagnostics[name]
+	names := make([]string, 0, len(s.diagnostics))
+	for n := range s.diagnostics {
+		names = append(names, n)
+	}
+	s.diagMu.RUnlock()
+
+	var body interface{}
+	switch {
+	case name == "":
+		sort.Strings(names)
+		body = map[string][]string{"diagnostics": names}
+	case ok:
+		body = fn()
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(body); err != nil {
+		s.logger.WithError(err).Error("failed to encode diagnostics")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const rateLimitKeyspace = "ratelimit:"
+
+// gcraScript implements the generic cell rate algorithm over a budget of
+// ARGV[3] requests per window, one request every ARGV[2] milliseconds. The
+// key holds the theoretical arrival time (TAT) in milliseconds of the Redis
+// clock, so replicas with skewed clocks agree.
+//
+// ARGV[1] "take" consumes one request and returns 0, or returns the
+// milliseconds until one is available without consuming anything.
+// ARGV[1] "sync" raises the TAT so that at most ARGV[4] requests are left,
+// as reported by GitLab's RateLimit-Remaining header, and returns 0.
+var gcraScript = redis.NewScript(`
+local now = redis.call('TIME')
+now = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+local interval = tonumber(ARGV[2])
+local burst = tonumber(ARGV[3])
+local tau = interval * burst
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+  tat = now
+end
+
+if ARGV[1] == 'sync' then
+  local floor = now + (burst - tonumber(ARGV[4])) * interval
+  if floor > tat then
+    tat = floor
+    redis.call('SET', KEYS[1], tat, 'PX', math.ceil(tat - now + interval))
+  end
+  return 0
+end
+
+local new = tat + interval
+local allow = new - tau
+if allow > now then
+  return math.ceil(allow - now)
+end
+redis.call('SET', KEYS[1], new, 'PX', math.ceil(new - now + interval))
+return 0
+`)
+
+// RedisRateLimiter keeps GitLab rate-limit budgets in Redis so that every
+// exporter replica (or other tool) using the same token draws from one
+// budget. It implements gitlab.SharedLimiter.
+type RedisRateLimiter struct {
+	client    redis.UniversalClient
+	keyPrefix string
+}
+
+// NewRedisRateLimiter creates a shared limiter on the store's Redis client.
+func NewRedisRateLimiter(r *RedisStore) *RedisRateLimiter {
+	return &RedisRateLimiter{client: r.client, keyPrefix: r.keyPrefix}
+}
+
+// Take consumes one request of key's budget of limit requests per window.
+// It returns 0, or how long to wait until a request is available.
+func (r *RedisRateLimiter) Take(ctx context.Context, key string, limit int, window time.Duration) (time.Duration, error) {
+	ms, err := gcraScript.Run(ctx, r.client, []string{r.key(key)},
+		"take", interval(limit, window), limit).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("redis rate limit take %s: %w", key, err)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// Sync lowers key's budget to the remaining requests GitLab reported.
+func (r *RedisRateLimiter) Sync(ctx context.Context, key string, limit, remaining int, window time.Duration) error {
+	err := gcraScript.Run(ctx, r.client, []string{r.key(key)},
+		"sync", interval(limit, window), limit, remaining).Err()
+	if err != nil {
+		return fmt.Errorf("redis rate limit sync %s: %w", key, err)
+	}
+	return nil
+}
+
+// key returns the namespaced Redis key for a rate-limit budget.
+func (r *RedisRateLimiter) key(key string) string {
+	return r.keyPrefix + rateLimitKeyspace + key
+}
+
+// interval returns the emission interval in milliseconds of a budget of
+// limit requests per window.
+func interval(limit int, window time.Duration) float64 {
+	return float64(window.Milliseconds()) / float64(limit)
+}
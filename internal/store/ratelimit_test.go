@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+)
+
+func newTestRateLimiter(t *testing.T) (*RedisRateLimiter, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	mr.SetTime(time.Unix(1700000000, 0))
+
+	st, err := NewRedisStore(config.RedisConfig{Addrs: []string{mr.Addr()}, KeyPrefix: "test:"})
+	if err != nil {
+		t.Fatalf("NewRedisStore: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return NewRedisRateLimiter(st), mr
+}
+
+func TestRedisRateLimiterTake(t *testing.T) {
+	rl, mr := newTestRateLimiter(t)
+	ctx := context.Background()
+
+	// 3 requests per 3s: a burst of 3, then one request per second.
+	for i := range 3 {
+		wait, err := rl.Take(ctx, "token", 3, 3*time.Second)
+		if err != nil || wait != 0 {
+			t.Fatalf("Take #%d = %v, %v; want 0, nil", i+1, wait, err)
+		}
+	}
+	wait, err := rl.Take(ctx, "token", 3, 3*time.Second)
+	if err != nil || wait != time.Second {
+		t.Fatalf("Take over the burst = %v, %v; want 1s, nil", wait, err)
+	}
+	if !mr.Exists("test:ratelimit:token") {
+		t.Error("budget not stored under the key prefix")
+	}
+
+	// A denied request consumes nothing, so the wait only shrinks.
+	mr.SetTime(time.Unix(1700000000, 0).Add(600 * time.Millisecond))
+	wait, err = rl.Take(ctx, "token", 3, 3*time.Second)
+	if err != nil || wait != 400*time.Millisecond {
+		t.Fatalf("Take after 600ms = %v, %v; want 400ms, nil", wait, err)
+	}
+
+	mr.SetTime(time.Unix(1700000001, 0))
+	if wait, err := rl.Take(ctx, "token", 3, 3*time.Second); err != nil || wait != 0 {
+		t.Fatalf("Take after 1s = %v, %v; want 0, nil", wait, err)
+	}
+
+	// Budgets are independent per key.
+	if wait, err := rl.Take(ctx, "other", 3, 3*time.Second); err != nil || wait != 0 {
+		t.Fatalf("Take on another key = %v, %v; want 0, nil", wait, err)
+	}
+}
+
+func TestRedisRateLimiterSync(t *testing.T) {
+	rl, _ := newTestRateLimiter(t)
+	ctx := context.Background()
+
+	// GitLab reports 1 of 3 requests left: one more is allowed.
+	if err := rl.Sync(ctx, "token", 3, 1, 3*time.Second); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if wait, err := rl.Take(ctx, "token", 3, 3*time.Second); err != nil || wait != 0 {
+		t.Fatalf("Take after Sync = %v, %v; want 0, nil", wait, err)
+	}
+	if wait, err := rl.Take(ctx, "token", 3, 3*time.Second); err != nil || wait != time.Second {
+		t.Fatalf("second Take after Sync = %v, %v; want 1s, nil", wait, err)
+	}
+
+	// A report of more remaining requests than the local budget never
+	// raises it.
+	if err := rl.Sync(ctx, "token", 3, 3, 3*time.Second); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if wait, err := rl.Take(ctx, "token", 3, 3*time.Second); err != nil || wait != time.Second {
+		t.Fatalf("Take after a higher Sync = %v, %v; want 1s, nil", wait, err)
+	}
+}
@@ -2,30 +2,38 @@ package store
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"os"
 	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
 )
 
-const redisKeyPrefix = "age:last_updated:"
+const (
+	defaultRedisKeyPrefix = "age:"
+	lastUpdatedKeyspace   = "last_updated:"
+)
 
-// RedisStore implements the Store interface using Redis.
+// RedisStore implements the Store interface using Redis. The underlying
+// client may be a standalone, Sentinel (failover) or Cluster client.
 type RedisStore struct {
-	client *redis.Client
+	client    redis.UniversalClient
+	keyPrefix string
 }
 
-// NewRedisStore creates a new RedisStore connected to the given Redis URL.
-// The URL is parsed with redis.ParseURL so it supports redis:// and rediss:// schemes.
-func NewRedisStore(url string) (*RedisStore, error) {
-	opts, err := redis.ParseURL(url)
+// NewRedisStore creates a new RedisStore from the given configuration and
+// verifies connectivity with a PING.
+func NewRedisStore(cfg config.RedisConfig) (*RedisStore, error) {
+	client, err := NewRedisClient(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("parsing redis URL: %w", err)
+		return nil, err
 	}
 
-	client := redis.NewClient(opts)
-
 	// Verify connectivity.
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -35,13 +43,224 @@ func NewRedisStore(url string) (*RedisStore, error) {
 		return nil, fmt.Errorf("connecting to redis: %w", err)
 	}
 
-	return &RedisStore{client: client}, nil
+	return NewRedisStoreWithClient(client, cfg.KeyPrefix), nil
+}
+
+// NewRedisStoreWithClient wraps an existing Redis client. It is used by
+// NewRedisStore and lets callers plug in an in-process Redis stand-in.
+// An empty keyPrefix falls back to "age:".
+func NewRedisStoreWithClient(client redis.UniversalClient, keyPrefix string) *RedisStore {
+	if keyPrefix == "" {
+		keyPrefix = defaultRedisKeyPrefix
+	}
+	return &RedisStore{
+		client:    client,
+		keyPrefix: keyPrefix,
+	}
+}
+
+// NewRedisClient builds a Redis client for the configured mode without
+// connecting. Credentials read from files are re-read whenever the client
+// opens a new connection, so rotated Kubernetes secrets are picked up
+// without a restart.
+func NewRedisClient(cfg config.RedisConfig) (redis.UniversalClient, error) {
+	tlsConfig, err := redisTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve credentials up-front so a missing secret file fails fast.
+	creds := redisCredentials(cfg)
+	if _, _, err := creds.load(); err != nil {
+		return nil, err
+	}
+
+	dialTimeout := time.Duration(cfg.DialTimeoutSeconds) * time.Second
+
+	switch cfg.Mode {
+	case "sentinel":
+		if cfg.MasterName == "" || len(cfg.Addrs) == 0 {
+			return nil, fmt.Errorf("redis sentinel mode requires master_name and addrs")
+		}
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:          cfg.MasterName,
+			SentinelAddrs:       cfg.Addrs,
+			SentinelPassword:    cfg.SentinelPassword,
+			CredentialsProvider: creds.provider(),
+			DB:                  cfg.DB,
+			PoolSize:            cfg.PoolSize,
+			MinIdleConns:        cfg.MinIdleConns,
+			MaxIdleConns:        cfg.MaxIdleConns,
+			DialTimeout:         dialTimeout,
+			TLSConfig:           tlsConfig,
+		}), nil
+
+	case "cluster":
+		if len(cfg.Addrs) == 0 {
+			return nil, fmt.Errorf("redis cluster mode requires addrs")
+		}
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:               cfg.Addrs,
+			CredentialsProvider: creds.provider(),
+			PoolSize:            cfg.PoolSize,
+			MinIdleConns:        cfg.MinIdleConns,
+			MaxIdleConns:        cfg.MaxIdleConns,
+			DialTimeout:         dialTimeout,
+			TLSConfig:           tlsConfig,
+		}), nil
+
+	case "", "standalone":
+		opts := &redis.Options{DB: cfg.DB}
+		switch {
+		case cfg.URL != "":
+			// The URL is parsed with redis.ParseURL so it supports redis://
+			// and rediss:// schemes.
+			opts, err = redis.ParseURL(cfg.URL)
+			if err != nil {
+				return nil, fmt.Errorf("parsing redis URL: %w", err)
+			}
+		case len(cfg.Addrs) > 0:
+			opts.Addr = cfg.Addrs[0]
+		default:
+			return nil, fmt.Errorf("redis standalone mode requires url or addrs")
+		}
+
+		if creds.configured() {
+			opts.CredentialsProvider = creds.provider()
+		}
+		if cfg.PoolSize > 0 {
+			opts.PoolSize = cfg.PoolSize
+		}
+		if cfg.MinIdleConns > 0 {
+			opts.MinIdleConns = cfg.MinIdleConns
+		}
+		if cfg.MaxIdleConns > 0 {
+			opts.MaxIdleConns = cfg.MaxIdleConns
+		}
+		if dialTimeout > 0 {
+			opts.DialTimeout = dialTimeout
+		}
+		if tlsConfig != nil {
+			opts.TLSConfig = tlsConfig
+		}
+		return redis.NewClient(opts), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported redis mode %q", cfg.Mode)
+	}
+}
+
+// redisCredentialSource resolves the Redis username and password, preferring
+// file-based secrets over literal values.
+type redisCredentialSource struct {
+	username     string
+	usernameFile string
+	password     string
+	passwordFile string
+}
+
+func redisCredentials(cfg config.RedisConfig) redisCredentialSource {
+	return redisCredentialSource{
+		username:     cfg.Username,
+		usernameFile: cfg.UsernameFile,
+		password:     cfg.Password,
+		passwordFile: cfg.PasswordFile,
+	}
+}
+
+// configured reports whether any explicit credential has been set.
+func (s redisCredentialSource) configured() bool {
+	return s.username != "" || s.usernameFile != "" || s.password != "" || s.passwordFile != ""
+}
+
+// load returns the current username and password.
+func (s redisCredentialSource) load() (string, string, error) {
+	username, password := s.username, s.password
+	if s.usernameFile != "" {
+		v, err := config.ReadSecretFile(s.usernameFile)
+		if err != nil {
+			return "", "", fmt.Errorf("reading redis username file: %w", err)
+		}
+		username = v
+	}
+	if s.passwordFile != "" {
+		v, err := config.ReadSecretFile(s.passwordFile)
+		if err != nil {
+			return "", "", fmt.Errorf("reading redis password file: %w", err)
+		}
+		password = v
+	}
+	return username, password, nil
+}
+
+// provider adapts load to go-redis' CredentialsProvider. A failed re-read
+// falls back to the literal values so a transient file error does not
+// break an otherwise healthy connection pool.
+func (s redisCredentialSource) provider() func() (string, string) {
+	return func() (string, string) {
+		username, password, err := s.load()
+		if err != nil {
+			return s.username, s.password
+		}
+		return username, password
+	}
+}
+
+// redisTLSConfig builds a *tls.Config from the Redis TLS settings, or
+// returns nil when TLS is not enabled.
+func redisTLSConfig(cfg config.RedisTLSConfig) (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint:gosec // explicitly opted into by configuration
+	}
+
+	if cfg.CACertPath != "" {
+		pem, err := os.ReadFile(cfg.CACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading redis CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in %s", cfg.CACertPath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertPath != "" || cfg.KeyPath != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertPath, cfg.KeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading redis client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// key returns the namespaced Redis key for a last-updated entry.
+func (r *RedisStore) key(key string) string {
+	return r.keyPrefix + lastUpdatedKeyspace + key
+}
+
+// Client returns the underlying Redis client.
+func (r *RedisStore) Client() redis.UniversalClient {
+	return r.client
+}
+
+// KeyPrefix returns the configured key prefix.
+func (r *RedisStore) KeyPrefix() string {
+	return r.keyPrefix
 }
 
 // GetLastUpdated returns the last-updated timestamp for the given key.
 // If the key does not exist, a zero time.Time is returned.
 func (r *RedisStore) GetLastUpdated(ctx context.Context, key string) (time.Time, error) {
-	val, err := r.client.Get(ctx, redisKeyPrefix+key).Result()
+	val, err := r.client.Get(ctx, r.key(key)).Result()
 	if err == redis.Nil {
 		return time.Time{}, nil
 	}
@@ -60,7 +279,7 @@ func (r *RedisStore) GetLastUpdated(ctx context.Context, key string) (time.Time,
 // SetLastUpdated stores the given timestamp as a Unix epoch string in Redis.
 func (r *RedisStore) SetLastUpdated(ctx context.Context, key string, t time.Time) error {
 	val := strconv.FormatInt(t.Unix(), 10)
-	if err := r.client.Set(ctx, redisKeyPrefix+key, val, 0).Err(); err != nil {
+	if err := r.client.Set(ctx, r.key(key), val, 0).Err(); err != nil {
 		return fmt.Errorf("redis SET %s: %w", key, err)
 	}
 	return nil
@@ -0,0 +1,177 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/amazing-gitlab-exporter/amazing-gitlab-exporter/internal/config"
+)
+
+func TestRedisStoreStandalone(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	st, err := NewRedisStore(config.RedisConfig{Addrs: []string{mr.Addr()}, KeyPrefix: "test:"})
+	if err != nil {
+		t.Fatalf("NewRedisStore: %v", err)
+	}
+	defer st.Close()
+
+	ctx := context.Background()
+	got, err := st.GetLastUpdated(ctx, "missing")
+	if err != nil || !got.IsZero() {
+		t.Fatalf("GetLastUpdated(missing) = %v, %v; want zero time, nil", got, err)
+	}
+
+	want := time.Unix(1700000000, 0)
+	if err := st.SetLastUpdated(ctx, "project/1", want); err != nil {
+		t.Fatalf("SetLastUpdated: %v", err)
+	}
+	got, err = st.GetLastUpdated(ctx, "project/1")
+	if err != nil || !got.Equal(want) {
+		t.Fatalf("GetLastUpdated = %v, %v; want %v", got, err, want)
+	}
+	if v, err := mr.Get("test:last_updated:project/1"); err != nil || v != "1700000000" {
+		t.Errorf("stored value = %q, %v; want %q under the key prefix", v, err, "1700000000")
+	}
+}
+
+func TestRedisStoreURL(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	st, err := NewRedisStore(config.RedisConfig{URL: "redis://" + mr.Addr() + "/0"})
+	if err != nil {
+		t.Fatalf("NewRedisStore: %v", err)
+	}
+	defer st.Close()
+	if st.KeyPrefix() != defaultRedisKeyPrefix {
+		t.Errorf("KeyPrefix = %q, want %q", st.KeyPrefix(), defaultRedisKeyPrefix)
+	}
+}
+
+func TestNewRedisClientConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	badPEM := writeFile(t, dir, "bad.pem", "not a certificate")
+
+	tests := []struct {
+		name string
+		cfg  config.RedisConfig
+		want string
+	}{
+		{
+			name: "missing CA certificate",
+			cfg: config.RedisConfig{Addrs: []string{"localhost:6379"}, TLS: config.RedisTLSConfig{
+				Enabled: true, CACertPath: filepath.Join(dir, "missing.pem"),
+			}},
+			want: "reading redis CA certificate",
+		},
+		{
+			name: "invalid CA certificate",
+			cfg: config.RedisConfig{Addrs: []string{"localhost:6379"}, TLS: config.RedisTLSConfig{
+				Enabled: true, CACertPath: badPEM,
+			}},
+			want: "no valid certificates",
+		},
+		{
+			name: "invalid client certificate",
+			cfg: config.RedisConfig{Addrs: []string{"localhost:6379"}, TLS: config.RedisTLSConfig{
+				Enabled: true, CertPath: badPEM, KeyPath: badPEM,
+			}},
+			want: "loading redis client certificate",
+		},
+		{
+			name: "missing password file",
+			cfg:  config.RedisConfig{Addrs: []string{"localhost:6379"}, PasswordFile: filepath.Join(dir, "missing")},
+			want: "reading redis password file",
+		},
+		{
+			name: "sentinel without master name",
+			cfg:  config.RedisConfig{Mode: "sentinel", Addrs: []string{"localhost:26379"}},
+			want: "requires master_name",
+		},
+		{
+			name: "standalone without address",
+			cfg:  config.RedisConfig{Mode: "standalone"},
+			want: "requires url or addrs",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRedisClient(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("NewRedisClient error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestRedisCredentialsReread rotates the password file and checks that new
+// connections authenticate with the new password.
+func TestRedisCredentialsReread(t *testing.T) {
+	mr := miniredis.RunT(t)
+	mr.RequireAuth("first")
+	passwordFile := writeFile(t, t.TempDir(), "password", "first\n")
+
+	st, err := NewRedisStore(config.RedisConfig{Addrs: []string{mr.Addr()}, PasswordFile: passwordFile})
+	if err != nil {
+		t.Fatalf("NewRedisStore: %v", err)
+	}
+	defer st.Close()
+
+	mr.RequireAuth("second")
+	writeFile(t, filepath.Dir(passwordFile), "password", "second\n")
+
+	// Hold the authenticated pooled connection so the next command has to
+	// open, and authenticate, a new one.
+	ctx := context.Background()
+	held := st.Client().(*redis.Client).Conn()
+	defer held.Close()
+	if err := held.Ping(ctx).Err(); err != nil {
+		t.Fatalf("ping on the existing connection: %v", err)
+	}
+
+	if err := st.SetLastUpdated(ctx, "key", time.Unix(1, 0)); err != nil {
+		t.Fatalf("SetLastUpdated after rotation: %v", err)
+	}
+}
+
+func TestRedisCredentialsProvider(t *testing.T) {
+	dir := t.TempDir()
+	usernameFile := writeFile(t, dir, "username", "bot")
+	passwordFile := writeFile(t, dir, "password", "one")
+	creds := redisCredentials(config.RedisConfig{
+		Password:     "literal",
+		UsernameFile: usernameFile,
+		PasswordFile: passwordFile,
+	})
+	provider := creds.provider()
+
+	if u, p := provider(); u != "bot" || p != "one" {
+		t.Fatalf("provider() = %q, %q; want bot, one", u, p)
+	}
+	writeFile(t, dir, "password", "two")
+	if _, p := provider(); p != "two" {
+		t.Fatalf("provider() password = %q after rotation, want two", p)
+	}
+	if err := os.Remove(passwordFile); err != nil {
+		t.Fatal(err)
+	}
+	if _, p := provider(); p != "literal" {
+		t.Fatalf("provider() password = %q with the file gone, want the literal fallback", p)
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}